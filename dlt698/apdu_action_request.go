@@ -46,6 +46,9 @@ func (a *ActionRequestNormal) decoder(buf *bytes.Reader) error {
 		return errors.New("ActionRequestNormal decode err: " + err.Error())
 	}
 	a.Data = dataTranslate(dataType)
+	if a.Data == nil {
+		return errors.New("ActionRequestNormal decode err: data type not found")
+	}
 	return a.Data.decoder(buf)
 }
 
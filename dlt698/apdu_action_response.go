@@ -76,10 +76,10 @@ func (a *ActionResponseNormal) encoder() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	encodeArray = append(encodeArray, darArray...)
 	if a.Data == nil {
 		return append(encodeArray, 0x00), nil
 	}
-	encodeArray = append(encodeArray, darArray...)
 	encodeArray = append(encodeArray, 0x01, a.Data.DataType())
 	dataArray, err := a.Data.encoder()
 	if err != nil {
@@ -104,6 +104,11 @@ func (a *ActionResponseNormal) hasTimeTag() bool {
 	return true
 }
 
+// Err 操作结果非成功时返回附带OMD的错误
+func (a *ActionResponseNormal) Err() error {
+	return omdError(a.Omd, a.DAR)
+}
+
 /*---------------------------------*/
 
 type ActionResponseNormalList struct {
@@ -158,6 +163,15 @@ func (a *ActionResponseNormalList) hasTimeTag() bool {
 	return true
 }
 
+// Err 合并所有操作结果中的错误
+func (a *ActionResponseNormalList) Err() error {
+	errs := make([]error, 0, len(a.Data))
+	for _, d := range a.Data {
+		errs = append(errs, d.Err())
+	}
+	return joinErrors(errs)
+}
+
 /*----------------------------------------*/
 
 type ActionThenGetResponseNormalList struct {
@@ -214,6 +228,15 @@ func (a *ActionThenGetResponseNormalList) hasTimeTag() bool {
 	return true
 }
 
+// Err 合并所有操作与读取结果中的错误
+func (a *ActionThenGetResponseNormalList) Err() error {
+	errs := make([]error, 0, len(a.Data))
+	for _, d := range a.Data {
+		errs = append(errs, d.Err())
+	}
+	return joinErrors(errs)
+}
+
 type ActionThenGetResponseNormalListItem struct {
 	Omd          *OMD          `json:"omd"`           //一个设置的对象方法描述符
 	Dar          *DAR          `json:"dar"`           //操作执行结果
@@ -281,3 +304,12 @@ func (a *ActionThenGetResponseNormalListItem) encoder() ([]byte, error) {
 	encodeArray = append(encodeArray, resultNormalArray...)
 	return encodeArray, nil
 }
+
+// Err 合并操作结果与读取结果中的错误
+func (a *ActionThenGetResponseNormalListItem) Err() error {
+	errs := []error{omdError(a.Omd, a.Dar)}
+	if a.ResultNormal != nil {
+		errs = append(errs, a.ResultNormal.Err())
+	}
+	return joinErrors(errs)
+}
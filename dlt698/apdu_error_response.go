@@ -0,0 +1,79 @@
+package dlt698
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+)
+
+var _ APDURegion = (*ErrorResponse)(nil)
+
+const (
+	ErrorResponseIdent string = "ee"
+)
+
+const (
+	ErrorResponseUnparsable  byte = 1   //APDU无法解析
+	ErrorResponseUnsupported byte = 2   //服务不支持
+	ErrorResponseOther       byte = 255 //其他
+)
+
+var (
+	ErrAPDUUnparsable     = errors.New("error response: apdu unparsable")
+	ErrServiceUnsupported = errors.New("error response: service unsupported")
+	ErrErrorResponseOther = errors.New("error response: other")
+)
+
+func init() {
+	apduMap[ErrorResponseIdent] = func() APDURegion {
+		return new(ErrorResponse)
+	}
+}
+
+// ErrorResponse 异常响应，服务器无法解析或不支持请求时应答
+type ErrorResponse struct {
+	ErrorType byte `json:"error_type"` //异常类型 1-APDU无法解析 2-服务不支持 255-其他
+}
+
+func (e *ErrorResponse) decoder(buf *bytes.Reader) error {
+	var err error
+	e.ErrorType, err = buf.ReadByte()
+	if err != nil {
+		return errors.New("ErrorResponse decode err: " + err.Error())
+	}
+	return nil
+}
+
+func (e *ErrorResponse) encoder() ([]byte, error) {
+	return []byte{e.ErrorType}, nil
+}
+
+func (e *ErrorResponse) APDUType() string {
+	return ErrorResponseIdent
+}
+
+func (e *ErrorResponse) APDUMark() string {
+	return "error_response"
+}
+
+func (e *ErrorResponse) hasFollowReport() bool {
+	return false
+}
+
+func (e *ErrorResponse) hasTimeTag() bool {
+	return false
+}
+
+// Err 返回异常类型对应的错误，可以使用errors.Is比较
+func (e *ErrorResponse) Err() error {
+	switch e.ErrorType {
+	case ErrorResponseUnparsable:
+		return ErrAPDUUnparsable
+	case ErrorResponseUnsupported:
+		return ErrServiceUnsupported
+	case ErrorResponseOther:
+		return ErrErrorResponseOther
+	default:
+		return errors.New("error response: unknown error type " + strconv.Itoa(int(e.ErrorType)))
+	}
+}
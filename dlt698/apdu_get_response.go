@@ -4,13 +4,13 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
+	"strconv"
 )
 
 var _ FrameRegion = (*ResultNormal)(nil)
 var _ FrameRegion = (*GetResult)(nil)
 var _ FrameRegion = (*ResultRecord)(nil)
 
-var _ DataInter = (*DAR)(nil)
 var _ DataInter = (*RecordRow)(nil)
 var _ APDURegion = (*GetResponseNormal)(nil)
 var _ APDURegion = (*GetResponseNormalList)(nil)
@@ -78,6 +78,14 @@ func (g *GetResponseNormal) hasTimeTag() bool {
 	return true
 }
 
+// Err 读取结果为DAR时返回对应的错误
+func (g *GetResponseNormal) Err() error {
+	if g.ResultNormal == nil {
+		return nil
+	}
+	return g.ResultNormal.Err()
+}
+
 // ResultNormal 对象属性及结果
 type ResultNormal struct {
 	OAD       []byte     `json:"oad"`
@@ -103,6 +111,18 @@ func (r *ResultNormal) decoder(buf *bytes.Reader) error {
 	return err
 }
 
+// Err 读取结果为DAR时返回附带OAD的错误
+func (r *ResultNormal) Err() error {
+	if r.GetResult == nil {
+		return nil
+	}
+	dar, ok := r.GetResult.Data.(*DAR)
+	if !ok {
+		return nil
+	}
+	return oadError(r.OAD, dar)
+}
+
 func (r *ResultNormal) encoder() ([]byte, error) {
 	getResultArray, err := r.GetResult.encoder()
 	if err != nil {
@@ -151,24 +171,13 @@ func (g *GetResult) encoder() ([]byte, error) {
 	}
 }
 
-type DAR struct {
-	Data byte `json:"value"`
-}
-
-func (D *DAR) DataType() byte {
-	return 0
-}
-
-func (D *DAR) Value() interface{} {
-	return D.Data
-}
-
-func (D *DAR) decoder(buf *bytes.Reader) error {
-	return binary.Read(buf, binary.LittleEndian, &D.Data)
-}
-
-func (D *DAR) encoder() ([]byte, error) {
-	return []byte{D.Data}, nil
+// Err 读取结果为DAR时返回对应的DARCode
+func (g *GetResult) Err() error {
+	dar, ok := g.Data.(*DAR)
+	if !ok {
+		return nil
+	}
+	return dar.Err()
 }
 
 /*-----------------------*/
@@ -227,6 +236,15 @@ func (g *GetResponseNormalList) hasTimeTag() bool {
 	return true
 }
 
+// Err 合并所有读取结果中的错误
+func (g *GetResponseNormalList) Err() error {
+	errs := make([]error, 0, len(g.ResultNormals))
+	for _, rn := range g.ResultNormals {
+		errs = append(errs, rn.Err())
+	}
+	return joinErrors(errs)
+}
+
 /*-----------------------------------*/
 
 type GetResponseRecord struct {
@@ -258,6 +276,14 @@ func (g *GetResponseRecord) hasTimeTag() bool {
 	return true
 }
 
+// Err 读取结果为DAR时返回对应的错误
+func (g *GetResponseRecord) Err() error {
+	if g.ResultRecord == nil {
+		return nil
+	}
+	return g.ResultRecord.Err()
+}
+
 type ResultRecord struct {
 	Oad  []byte    `json:"oad"`        //记录型对象属性描述符
 	Rcsd *RCSD     `json:"rcsd"`       //记录的N列属性描述符
@@ -294,12 +320,33 @@ func (r *ResultRecord) decoder(buf *bytes.Reader) error {
 	return r.Data.decoder(buf)
 }
 
+// Err 读取结果为DAR时返回附带OAD的错误
+func (r *ResultRecord) Err() error {
+	dar, ok := r.Data.(*DAR)
+	if !ok {
+		return nil
+	}
+	return oadError(r.Oad, dar)
+}
+
+// Rows 返回按列拆分后的记录，读取结果为DAR时返回nil
+func (r *ResultRecord) Rows() [][]DataInter {
+	row, ok := r.Data.(*RecordRow)
+	if !ok || r.Rcsd == nil {
+		return nil
+	}
+	return row.Rows(len(r.Rcsd.CSDs))
+}
+
 func (r *ResultRecord) encoder() ([]byte, error) {
 	rcsdArray, err := r.Rcsd.encoder()
 	if err != nil {
 		return nil, err
 	}
 	encodeArray := append(r.Oad, rcsdArray...)
+	if row, ok := r.Data.(*RecordRow); ok {
+		row.length = len(r.Rcsd.CSDs)
+	}
 	dataArray, err := r.Data.encoder()
 	if err != nil {
 		return nil, err
@@ -314,33 +361,49 @@ func (r *ResultRecord) encoder() ([]byte, error) {
 	}
 }
 
+// RecordRow M条记录，每条记录N列，按行依次保存在RecordRow中
 type RecordRow struct {
 	length    int
 	RecordRow []DataInter `json:"record_row"` //M 条记录
 }
 
 func (r *RecordRow) decoder(buf *bytes.Reader) error {
-	r.RecordRow = make([]DataInter, r.length)
-	_, err := buf.ReadByte()
+	rows, err := decodeVarLength(buf)
 	if err != nil {
 		return err
 	}
-	for i := 0; i < r.length; i++ {
+	//每个Data至少1个字节，数量不能超过剩余的数据长度
+	count := rows * r.length
+	if count > buf.Len() {
+		return errors.New("RecordRow decode err: record count exceeds remaining data")
+	}
+	r.RecordRow = nil
+	for i := 0; i < count; i++ {
 		dataType, err := buf.ReadByte()
 		if err != nil {
 			return err
 		}
-		r.RecordRow[i] = dataTranslate(dataType)
-		err = r.RecordRow[i].decoder(buf)
-		if err != nil {
+		data := dataTranslate(dataType)
+		if data == nil {
+			return errors.New("RecordRow decode err: unknown data type " + strconv.Itoa(int(dataType)))
+		}
+		if err = data.decoder(buf); err != nil {
 			return err
 		}
+		r.RecordRow = append(r.RecordRow, data)
 	}
 	return nil
 }
 
 func (r *RecordRow) encoder() ([]byte, error) {
-	encodeArray := []byte{1}
+	rows := 1
+	if r.length > 0 {
+		if len(r.RecordRow)%r.length != 0 {
+			return nil, errors.New("RecordRow encode err: data size is not a multiple of column size")
+		}
+		rows = len(r.RecordRow) / r.length
+	}
+	encodeArray := encodeVarLength(rows)
 	for _, row := range r.RecordRow {
 		encodeArray = append(encodeArray, row.DataType())
 		rowArray, err := row.encoder()
@@ -352,6 +415,18 @@ func (r *RecordRow) encoder() ([]byte, error) {
 	return encodeArray, nil
 }
 
+// Rows 按列数拆分为M条记录
+func (r *RecordRow) Rows(columns int) [][]DataInter {
+	if columns <= 0 {
+		return [][]DataInter{r.RecordRow}
+	}
+	rows := make([][]DataInter, 0, len(r.RecordRow)/columns)
+	for i := 0; i+columns <= len(r.RecordRow); i += columns {
+		rows = append(rows, r.RecordRow[i:i+columns])
+	}
+	return rows
+}
+
 func (r *RecordRow) DataType() byte {
 	return 0
 }
@@ -416,6 +491,15 @@ func (g *GetResponseRecordList) hasTimeTag() bool {
 	return false
 }
 
+// Err 合并所有读取结果中的错误
+func (g *GetResponseRecordList) Err() error {
+	errs := make([]error, 0, len(g.ResultRecords))
+	for _, rr := range g.ResultRecords {
+		errs = append(errs, rr.Err())
+	}
+	return joinErrors(errs)
+}
+
 /*--------------------*/
 
 type GetResponseNext struct {
@@ -482,7 +566,7 @@ func (g *GetResponseNext) encoder() ([]byte, error) {
 		return nil, errors.New("GetResponseNext encode err:" + err.Error())
 	}
 	if g.Dar != nil {
-		err = binary.Write(buf, binary.BigEndian, []byte{0x00, g.Dar.Data})
+		err = binary.Write(buf, binary.BigEndian, []byte{0x00, byte(g.Dar.Data)})
 		return buf.Bytes(), err
 	}
 	switch g.Data[0].(type) {
@@ -525,6 +609,23 @@ func (g *GetResponseNext) hasTimeTag() bool {
 	return true
 }
 
+// Err 合并分帧响应中的错误
+func (g *GetResponseNext) Err() error {
+	if g.Dar != nil {
+		return g.Dar.Err()
+	}
+	errs := make([]error, 0, len(g.Data))
+	for _, d := range g.Data {
+		switch data := d.(type) {
+		case *ResultNormal:
+			errs = append(errs, data.Err())
+		case *ResultRecord:
+			errs = append(errs, data.Err())
+		}
+	}
+	return joinErrors(errs)
+}
+
 /*----------------------------------------*/
 
 type GetResponseMD5 struct {
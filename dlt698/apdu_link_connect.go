@@ -128,7 +128,7 @@ func (l *LinkResponse) decoder(buf *bytes.Reader) error {
 		return errors.New("decode linkResponse's Timing err:" + err.Error())
 	}
 	//获取bit7
-	l.Timing = resultValue & (1 << 7)
+	l.Timing = resultValue >> 7 & 1
 	//获取bit0~bit2
 	l.Result = resultValue & 0x07
 	l.RequestTime = &DateTime{}
@@ -231,16 +231,7 @@ func (c *ConnectRequest) decoder(buf *bytes.Reader) error {
 	if err := binary.Read(buf, binary.BigEndian, &connectMechanismInfoType); err != nil {
 		return errors.New("decode ConnectRequest's connectMechanismInfoType err:" + err.Error())
 	}
-	switch connectMechanismInfoType {
-	case 0:
-		c.ConnectMechanismInfo = &NullSecurity{}
-	case 1:
-		c.ConnectMechanismInfo = &PasswordSecurity{}
-	case 2:
-		c.ConnectMechanismInfo = &SignatureSecurity{}
-	case 3:
-		c.ConnectMechanismInfo = &SymmetrySecurity{}
-	}
+	c.ConnectMechanismInfo = newConnectMechanismInfo(connectMechanismInfoType)
 	if c.ConnectMechanismInfo == nil {
 		return errors.New("ConnectMechanismInfo no such type")
 	}
@@ -302,6 +293,22 @@ type ConnectMechanismInfo interface {
 	Sign() byte
 }
 
+// newConnectMechanismInfo 根据认证机制序号创建认证请求对象
+// 0-公共连接 1-一般密码 2-对称加密 3-数字签名
+func newConnectMechanismInfo(sign byte) ConnectMechanismInfo {
+	switch sign {
+	case 0:
+		return &NullSecurity{}
+	case 1:
+		return &PasswordSecurity{}
+	case 2:
+		return &SymmetrySecurity{}
+	case 3:
+		return &SignatureSecurity{}
+	}
+	return nil
+}
+
 type NullSecurity struct {
 }
 
@@ -339,7 +346,7 @@ type SymmetrySecurity struct {
 	Signature *OctetString `json:"signature1"` //客户机签名1
 }
 
-func (s SymmetrySecurity) decoder(buf *bytes.Reader) error {
+func (s *SymmetrySecurity) decoder(buf *bytes.Reader) error {
 	s.Secret = &OctetString{}
 	if err := s.Secret.decoder(buf); err != nil {
 		return err
@@ -351,7 +358,7 @@ func (s SymmetrySecurity) decoder(buf *bytes.Reader) error {
 	return nil
 }
 
-func (s SymmetrySecurity) encoder() ([]byte, error) {
+func (s *SymmetrySecurity) encoder() ([]byte, error) {
 	secret1Array, err := s.Secret.encoder()
 	if err != nil {
 		return nil, err
@@ -363,7 +370,7 @@ func (s SymmetrySecurity) encoder() ([]byte, error) {
 	return append(secret1Array, signature1Array...), nil
 }
 
-func (s SymmetrySecurity) Sign() byte {
+func (s *SymmetrySecurity) Sign() byte {
 	return 2
 }
 
@@ -372,7 +379,7 @@ type SignatureSecurity struct {
 	Signature *OctetString `json:"signature2"` //客户机签名2
 }
 
-func (s SignatureSecurity) decoder(buf *bytes.Reader) error {
+func (s *SignatureSecurity) decoder(buf *bytes.Reader) error {
 	s.Secret = &OctetString{}
 	if err := s.Secret.decoder(buf); err != nil {
 		return err
@@ -384,7 +391,7 @@ func (s SignatureSecurity) decoder(buf *bytes.Reader) error {
 	return nil
 }
 
-func (s SignatureSecurity) encoder() ([]byte, error) {
+func (s *SignatureSecurity) encoder() ([]byte, error) {
 	secret1Array, err := s.Secret.encoder()
 	if err != nil {
 		return nil, err
@@ -396,7 +403,7 @@ func (s SignatureSecurity) encoder() ([]byte, error) {
 	return append(secret1Array, signature1Array...), nil
 }
 
-func (s SignatureSecurity) Sign() byte {
+func (s *SignatureSecurity) Sign() byte {
 	return 3
 }
 
@@ -598,7 +605,7 @@ func (c *ConnectResponseInfo) decoder(buf *bytes.Reader) error {
 }
 
 func (c *ConnectResponseInfo) encoder() ([]byte, error) {
-	if &c.SecurityData == nil {
+	if c.SecurityData == nil {
 		return []byte{c.ConnectResult, 0x00}, nil
 	}
 	encodeSignArray := append([]byte{c.ConnectResult}, 0x01)
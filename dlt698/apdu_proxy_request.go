@@ -3,18 +3,31 @@ package dlt698
 import (
 	"bytes"
 	"encoding/binary"
-	"encoding/hex"
 	"errors"
 )
 
 var _ APDURegion = (*ProxyGetRequestList)(nil)
 var _ APDURegion = (*ProxyGetRequestRecord)(nil)
+var _ APDURegion = (*ProxySetRequestList)(nil)
+var _ APDURegion = (*ProxySetThenGetRequestList)(nil)
+var _ APDURegion = (*ProxyActionRequestList)(nil)
+var _ APDURegion = (*ProxyActionThenGetRequestList)(nil)
+var _ APDURegion = (*ProxyTransCommandRequest)(nil)
 
 var _ FrameRegion = (*ProxyGetRequestListItem)(nil)
+var _ FrameRegion = (*ProxySetRequestListItem)(nil)
+var _ FrameRegion = (*ProxySetThenGetRequestListItem)(nil)
+var _ FrameRegion = (*ProxyActionRequestListItem)(nil)
+var _ FrameRegion = (*ProxyActionThenGetRequestListItem)(nil)
 
 const (
-	ProxyGetRequestListIdent   string = "0901"
-	ProxyGetRequestRecordIdent string = "0902"
+	ProxyGetRequestListIdent           string = "0901"
+	ProxyGetRequestRecordIdent         string = "0902"
+	ProxySetRequestListIdent           string = "0903"
+	ProxySetThenGetRequestListIdent    string = "0904"
+	ProxyActionRequestListIdent        string = "0905"
+	ProxyActionThenGetRequestListIdent string = "0906"
+	ProxyTransCommandRequestIdent      string = "0907"
 )
 
 func init() {
@@ -24,6 +37,21 @@ func init() {
 	apduMap[ProxyGetRequestRecordIdent] = func() APDURegion {
 		return new(ProxyGetRequestRecord)
 	}
+	apduMap[ProxySetRequestListIdent] = func() APDURegion {
+		return new(ProxySetRequestList)
+	}
+	apduMap[ProxySetThenGetRequestListIdent] = func() APDURegion {
+		return new(ProxySetThenGetRequestList)
+	}
+	apduMap[ProxyActionRequestListIdent] = func() APDURegion {
+		return new(ProxyActionRequestList)
+	}
+	apduMap[ProxyActionThenGetRequestListIdent] = func() APDURegion {
+		return new(ProxyActionThenGetRequestList)
+	}
+	apduMap[ProxyTransCommandRequestIdent] = func() APDURegion {
+		return new(ProxyTransCommandRequest)
+	}
 }
 
 type ProxyGetRequestList struct {
@@ -87,31 +115,16 @@ func (p *ProxyGetRequestList) hasTimeTag() bool {
 }
 
 type ProxyGetRequestListItem struct {
-	Tsa     string        //一个目标服务器地址
+	Tsa     *TSA          //一个目标服务器地址
 	TimeOut *LongUnsigned // 代理一个目标服气器的超时时间
 	Oads    [][]byte      // 若干个对象属性描述符
 }
 
 func (p *ProxyGetRequestListItem) decoder(buf *bytes.Reader) error {
-	var tsaAllLength byte
-	if err := binary.Read(buf, binary.BigEndian, &tsaAllLength); err != nil {
-		return errors.New("ProxyGetRequestListItem decode err: " + err.Error())
-	}
-	var tsaLen byte
-	if err := binary.Read(buf, binary.BigEndian, &tsaLen); err != nil {
+	var err error
+	if p.Tsa, err = decodeProxyTsa(buf); err != nil {
 		return errors.New("ProxyGetRequestListItem decode err: " + err.Error())
 	}
-	if tsaAllLength != tsaLen+2 {
-		return errors.New("tsaAllLength != tsaLen + 2")
-	}
-	if tsaLen > 0 {
-		tsaArray := make([]byte, tsaLen+1)
-		err := binary.Read(buf, binary.LittleEndian, &tsaArray)
-		if err != nil {
-			return err
-		}
-		p.Tsa = hex.EncodeToString(tsaArray)
-	}
 	p.TimeOut = &LongUnsigned{}
 	if err := p.TimeOut.decoder(buf); err != nil {
 		return err
@@ -131,11 +144,10 @@ func (p *ProxyGetRequestListItem) decoder(buf *bytes.Reader) error {
 }
 
 func (p *ProxyGetRequestListItem) encoder() ([]byte, error) {
-	encodeArray, err := hex.DecodeString(p.Tsa)
+	encodeArray, err := encodeProxyTsa(p.Tsa)
 	if err != nil {
 		return nil, err
 	}
-	encodeArray = append([]byte{byte(len(encodeArray) + 1), byte(len(encodeArray) - 1)}, encodeArray...)
 	toArray, err := p.TimeOut.encoder()
 	if err != nil {
 		return nil, err
@@ -156,7 +168,7 @@ func (p *ProxyGetRequestListItem) encoder() ([]byte, error) {
 
 type ProxyGetRequestRecord struct {
 	TimeOut uint16 // 代理请求的超时时间
-	Tsa     string //目标服务器地址
+	Tsa     *TSA   //目标服务器地址
 	Oad     []byte //对象属性描述符
 	Rsd     *RSD   // 记录行选择描述符
 	Rcsd    *RCSD  //记录列选择描述符
@@ -167,25 +179,9 @@ func (p *ProxyGetRequestRecord) decoder(buf *bytes.Reader) error {
 	if err != nil {
 		return errors.New("ProxyGetRequestRecord decode err: " + err.Error())
 	}
-	tsaAllLength, err := buf.ReadByte()
-	if err != nil {
+	if p.Tsa, err = decodeProxyTsa(buf); err != nil {
 		return errors.New("ProxyGetRequestRecord decode err: " + err.Error())
 	}
-	tsaLen, err := buf.ReadByte()
-	if err != nil {
-		return err
-	}
-	if tsaAllLength != tsaLen+2 {
-		return errors.New("tsaAllLength != tsaLen + 2")
-	}
-	if tsaLen > 0 {
-		tsaArray := make([]byte, tsaLen+1)
-		err := binary.Read(buf, binary.LittleEndian, &tsaArray)
-		if err != nil {
-			return err
-		}
-		p.Tsa = hex.EncodeToString(tsaArray)
-	}
 	p.Oad = make([]byte, 4)
 	if err = binary.Read(buf, binary.LittleEndian, &p.Oad); err != nil {
 		return err
@@ -205,11 +201,10 @@ func (p *ProxyGetRequestRecord) encoder() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	encodeArray, err := hex.DecodeString(p.Tsa)
+	encodeArray, err := encodeProxyTsa(p.Tsa)
 	if err != nil {
 		return nil, err
 	}
-	encodeArray = append([]byte{byte(len(encodeArray) + 1), byte(len(encodeArray) - 1)}, encodeArray...)
 	encodeArray = append(buf.Bytes(), encodeArray...)
 	encodeArray = append(encodeArray, p.Oad...)
 	rsdArray, err := p.Rsd.encoder()
@@ -240,3 +235,508 @@ func (p *ProxyGetRequestRecord) hasFollowReport() bool {
 func (p *ProxyGetRequestRecord) hasTimeTag() bool {
 	return true
 }
+
+/*-------------------------------------------*/
+
+type ProxySetRequestList struct {
+	TimeOut *LongUnsigned              `json:"time_out"` //代理整个请求的超时时间，单位秒
+	Data    []*ProxySetRequestListItem `json:"data"`     //若干个服务器的对象属性设置
+}
+
+func (p *ProxySetRequestList) decoder(buf *bytes.Reader) error {
+	p.TimeOut = &LongUnsigned{}
+	if err := p.TimeOut.decoder(buf); err != nil {
+		return err
+	}
+	length, err := buf.ReadByte()
+	if err != nil {
+		return errors.New("ProxySetRequestList decode err: " + err.Error())
+	}
+	p.Data = make([]*ProxySetRequestListItem, length)
+	for i := 0; i < int(length); i++ {
+		p.Data[i] = &ProxySetRequestListItem{}
+		if err := p.Data[i].decoder(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProxySetRequestList) encoder() ([]byte, error) {
+	encodeArray, err := p.TimeOut.encoder()
+	if err != nil {
+		return nil, err
+	}
+	encodeArray = append(encodeArray, byte(len(p.Data)))
+	for _, item := range p.Data {
+		itemArray, err := item.encoder()
+		if err != nil {
+			return nil, err
+		}
+		encodeArray = append(encodeArray, itemArray...)
+	}
+	return encodeArray, nil
+}
+
+func (p *ProxySetRequestList) APDUType() string {
+	return ProxySetRequestListIdent
+}
+
+func (p *ProxySetRequestList) APDUMark() string {
+	return "proxy_set_request_list"
+}
+
+func (p *ProxySetRequestList) hasFollowReport() bool {
+	return false
+}
+
+func (p *ProxySetRequestList) hasTimeTag() bool {
+	return true
+}
+
+type ProxySetRequestListItem struct {
+	Tsa     *TSA                `json:"tsa"`      //一个目标服务器地址
+	TimeOut *LongUnsigned       `json:"time_out"` //代理一个服务器的超时时间
+	Data    []*SetRequestNormal `json:"data"`     //若干个对象属性描述符及其数据
+}
+
+func (p *ProxySetRequestListItem) decoder(buf *bytes.Reader) error {
+	var length byte
+	var err error
+	p.Tsa, p.TimeOut, length, err = decodeProxyHead(buf)
+	if err != nil {
+		return errors.New("ProxySetRequestListItem decode err: " + err.Error())
+	}
+	p.Data = make([]*SetRequestNormal, length)
+	for i := 0; i < int(length); i++ {
+		p.Data[i] = &SetRequestNormal{}
+		if err = p.Data[i].decoder(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProxySetRequestListItem) encoder() ([]byte, error) {
+	encodeArray, err := encodeProxyHead(p.Tsa, p.TimeOut, len(p.Data))
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range p.Data {
+		itemArray, err := item.encoder()
+		if err != nil {
+			return nil, err
+		}
+		encodeArray = append(encodeArray, itemArray...)
+	}
+	return encodeArray, nil
+}
+
+/*-------------------------------------------*/
+
+type ProxySetThenGetRequestList struct {
+	TimeOut *LongUnsigned                     `json:"time_out"` //代理整个请求的超时时间，单位秒
+	Data    []*ProxySetThenGetRequestListItem `json:"data"`     //若干个服务器的对象属性设置后读取
+}
+
+func (p *ProxySetThenGetRequestList) decoder(buf *bytes.Reader) error {
+	p.TimeOut = &LongUnsigned{}
+	if err := p.TimeOut.decoder(buf); err != nil {
+		return err
+	}
+	length, err := buf.ReadByte()
+	if err != nil {
+		return errors.New("ProxySetThenGetRequestList decode err: " + err.Error())
+	}
+	p.Data = make([]*ProxySetThenGetRequestListItem, length)
+	for i := 0; i < int(length); i++ {
+		p.Data[i] = &ProxySetThenGetRequestListItem{}
+		if err := p.Data[i].decoder(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProxySetThenGetRequestList) encoder() ([]byte, error) {
+	encodeArray, err := p.TimeOut.encoder()
+	if err != nil {
+		return nil, err
+	}
+	encodeArray = append(encodeArray, byte(len(p.Data)))
+	for _, item := range p.Data {
+		itemArray, err := item.encoder()
+		if err != nil {
+			return nil, err
+		}
+		encodeArray = append(encodeArray, itemArray...)
+	}
+	return encodeArray, nil
+}
+
+func (p *ProxySetThenGetRequestList) APDUType() string {
+	return ProxySetThenGetRequestListIdent
+}
+
+func (p *ProxySetThenGetRequestList) APDUMark() string {
+	return "proxy_set_then_get_request_list"
+}
+
+func (p *ProxySetThenGetRequestList) hasFollowReport() bool {
+	return false
+}
+
+func (p *ProxySetThenGetRequestList) hasTimeTag() bool {
+	return true
+}
+
+type ProxySetThenGetRequestListItem struct {
+	Tsa     *TSA                     `json:"tsa"`      //一个目标服务器地址
+	TimeOut *LongUnsigned            `json:"time_out"` //代理一个服务器的超时时间
+	Data    []*SetThenGetRequestItem `json:"data"`     //若干个对象属性的设置后读取
+}
+
+func (p *ProxySetThenGetRequestListItem) decoder(buf *bytes.Reader) error {
+	var length byte
+	var err error
+	p.Tsa, p.TimeOut, length, err = decodeProxyHead(buf)
+	if err != nil {
+		return errors.New("ProxySetThenGetRequestListItem decode err: " + err.Error())
+	}
+	p.Data = make([]*SetThenGetRequestItem, length)
+	for i := 0; i < int(length); i++ {
+		p.Data[i] = &SetThenGetRequestItem{}
+		if err = p.Data[i].decoder(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProxySetThenGetRequestListItem) encoder() ([]byte, error) {
+	encodeArray, err := encodeProxyHead(p.Tsa, p.TimeOut, len(p.Data))
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range p.Data {
+		itemArray, err := item.encoder()
+		if err != nil {
+			return nil, err
+		}
+		encodeArray = append(encodeArray, itemArray...)
+	}
+	return encodeArray, nil
+}
+
+/*-------------------------------------------*/
+
+type ProxyActionRequestList struct {
+	TimeOut *LongUnsigned                 `json:"time_out"` //代理整个请求的超时时间，单位秒
+	Data    []*ProxyActionRequestListItem `json:"data"`     //若干个服务器的对象方法操作
+}
+
+func (p *ProxyActionRequestList) decoder(buf *bytes.Reader) error {
+	p.TimeOut = &LongUnsigned{}
+	if err := p.TimeOut.decoder(buf); err != nil {
+		return err
+	}
+	length, err := buf.ReadByte()
+	if err != nil {
+		return errors.New("ProxyActionRequestList decode err: " + err.Error())
+	}
+	p.Data = make([]*ProxyActionRequestListItem, length)
+	for i := 0; i < int(length); i++ {
+		p.Data[i] = &ProxyActionRequestListItem{}
+		if err := p.Data[i].decoder(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProxyActionRequestList) encoder() ([]byte, error) {
+	encodeArray, err := p.TimeOut.encoder()
+	if err != nil {
+		return nil, err
+	}
+	encodeArray = append(encodeArray, byte(len(p.Data)))
+	for _, item := range p.Data {
+		itemArray, err := item.encoder()
+		if err != nil {
+			return nil, err
+		}
+		encodeArray = append(encodeArray, itemArray...)
+	}
+	return encodeArray, nil
+}
+
+func (p *ProxyActionRequestList) APDUType() string {
+	return ProxyActionRequestListIdent
+}
+
+func (p *ProxyActionRequestList) APDUMark() string {
+	return "proxy_action_request_list"
+}
+
+func (p *ProxyActionRequestList) hasFollowReport() bool {
+	return false
+}
+
+func (p *ProxyActionRequestList) hasTimeTag() bool {
+	return true
+}
+
+type ProxyActionRequestListItem struct {
+	Tsa     *TSA                   `json:"tsa"`      //一个目标服务器地址
+	TimeOut *LongUnsigned          `json:"time_out"` //代理一个服务器的超时时间
+	Data    []*ActionRequestNormal `json:"data"`     //若干个对象方法描述符及其参数
+}
+
+func (p *ProxyActionRequestListItem) decoder(buf *bytes.Reader) error {
+	var length byte
+	var err error
+	p.Tsa, p.TimeOut, length, err = decodeProxyHead(buf)
+	if err != nil {
+		return errors.New("ProxyActionRequestListItem decode err: " + err.Error())
+	}
+	p.Data = make([]*ActionRequestNormal, length)
+	for i := 0; i < int(length); i++ {
+		p.Data[i] = &ActionRequestNormal{}
+		if err = p.Data[i].decoder(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProxyActionRequestListItem) encoder() ([]byte, error) {
+	encodeArray, err := encodeProxyHead(p.Tsa, p.TimeOut, len(p.Data))
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range p.Data {
+		itemArray, err := item.encoder()
+		if err != nil {
+			return nil, err
+		}
+		encodeArray = append(encodeArray, itemArray...)
+	}
+	return encodeArray, nil
+}
+
+/*-------------------------------------------*/
+
+type ProxyActionThenGetRequestList struct {
+	TimeOut *LongUnsigned                        `json:"time_out"` //代理整个请求的超时时间，单位秒
+	Data    []*ProxyActionThenGetRequestListItem `json:"data"`     //若干个服务器的对象方法操作后读取
+}
+
+func (p *ProxyActionThenGetRequestList) decoder(buf *bytes.Reader) error {
+	p.TimeOut = &LongUnsigned{}
+	if err := p.TimeOut.decoder(buf); err != nil {
+		return err
+	}
+	length, err := buf.ReadByte()
+	if err != nil {
+		return errors.New("ProxyActionThenGetRequestList decode err: " + err.Error())
+	}
+	p.Data = make([]*ProxyActionThenGetRequestListItem, length)
+	for i := 0; i < int(length); i++ {
+		p.Data[i] = &ProxyActionThenGetRequestListItem{}
+		if err := p.Data[i].decoder(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProxyActionThenGetRequestList) encoder() ([]byte, error) {
+	encodeArray, err := p.TimeOut.encoder()
+	if err != nil {
+		return nil, err
+	}
+	encodeArray = append(encodeArray, byte(len(p.Data)))
+	for _, item := range p.Data {
+		itemArray, err := item.encoder()
+		if err != nil {
+			return nil, err
+		}
+		encodeArray = append(encodeArray, itemArray...)
+	}
+	return encodeArray, nil
+}
+
+func (p *ProxyActionThenGetRequestList) APDUType() string {
+	return ProxyActionThenGetRequestListIdent
+}
+
+func (p *ProxyActionThenGetRequestList) APDUMark() string {
+	return "proxy_action_then_get_request_list"
+}
+
+func (p *ProxyActionThenGetRequestList) hasFollowReport() bool {
+	return false
+}
+
+func (p *ProxyActionThenGetRequestList) hasTimeTag() bool {
+	return true
+}
+
+type ProxyActionThenGetRequestListItem struct {
+	Tsa     *TSA                                  `json:"tsa"`      //一个目标服务器地址
+	TimeOut *LongUnsigned                         `json:"time_out"` //代理一个服务器的超时时间
+	Data    []*ActionThenGetRequestNormalListItem `json:"data"`     //若干个对象方法操作后读取
+}
+
+func (p *ProxyActionThenGetRequestListItem) decoder(buf *bytes.Reader) error {
+	var length byte
+	var err error
+	p.Tsa, p.TimeOut, length, err = decodeProxyHead(buf)
+	if err != nil {
+		return errors.New("ProxyActionThenGetRequestListItem decode err: " + err.Error())
+	}
+	p.Data = make([]*ActionThenGetRequestNormalListItem, length)
+	for i := 0; i < int(length); i++ {
+		p.Data[i] = &ActionThenGetRequestNormalListItem{}
+		if err = p.Data[i].decoder(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProxyActionThenGetRequestListItem) encoder() ([]byte, error) {
+	encodeArray, err := encodeProxyHead(p.Tsa, p.TimeOut, len(p.Data))
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range p.Data {
+		itemArray, err := item.encoder()
+		if err != nil {
+			return nil, err
+		}
+		encodeArray = append(encodeArray, itemArray...)
+	}
+	return encodeArray, nil
+}
+
+/*-------------------------------------------*/
+
+type ProxyTransCommandRequest struct {
+	Oad         []byte       `json:"oad"`           //数据转发端口
+	Comdcb      *COMDCB      `json:"comdcb"`        //端口通信控制块
+	RecvTimeOut uint16       `json:"recv_time_out"` //接收等待报文超时时间，单位秒
+	ByteTimeOut uint16       `json:"byte_time_out"` //接收等待字节超时时间，单位毫秒
+	Command     *OctetString `json:"command"`       //透明转发命令
+}
+
+func (p *ProxyTransCommandRequest) decoder(buf *bytes.Reader) error {
+	p.Oad = make([]byte, 4)
+	if err := binary.Read(buf, binary.LittleEndian, &p.Oad); err != nil {
+		return errors.New("ProxyTransCommandRequest decode err: " + err.Error())
+	}
+	p.Comdcb = &COMDCB{}
+	if err := p.Comdcb.decoder(buf); err != nil {
+		return errors.New("ProxyTransCommandRequest decode err: " + err.Error())
+	}
+	if err := binary.Read(buf, binary.BigEndian, &p.RecvTimeOut); err != nil {
+		return errors.New("ProxyTransCommandRequest decode err: " + err.Error())
+	}
+	if err := binary.Read(buf, binary.BigEndian, &p.ByteTimeOut); err != nil {
+		return errors.New("ProxyTransCommandRequest decode err: " + err.Error())
+	}
+	p.Command = &OctetString{}
+	return p.Command.decoder(buf)
+}
+
+func (p *ProxyTransCommandRequest) encoder() ([]byte, error) {
+	if len(p.Oad) != 4 {
+		return nil, errors.New("ProxyTransCommandRequest encode err: oad size != 4")
+	}
+	buf := new(bytes.Buffer)
+	buf.Write(p.Oad)
+	comdcbArray, err := p.Comdcb.encoder()
+	if err != nil {
+		return nil, err
+	}
+	buf.Write(comdcbArray)
+	if err = binary.Write(buf, binary.BigEndian, p.RecvTimeOut); err != nil {
+		return nil, err
+	}
+	if err = binary.Write(buf, binary.BigEndian, p.ByteTimeOut); err != nil {
+		return nil, err
+	}
+	commandArray, err := p.Command.encoder()
+	if err != nil {
+		return nil, err
+	}
+	buf.Write(commandArray)
+	return buf.Bytes(), nil
+}
+
+func (p *ProxyTransCommandRequest) APDUType() string {
+	return ProxyTransCommandRequestIdent
+}
+
+func (p *ProxyTransCommandRequest) APDUMark() string {
+	return "proxy_trans_command_request"
+}
+
+func (p *ProxyTransCommandRequest) hasFollowReport() bool {
+	return false
+}
+
+func (p *ProxyTransCommandRequest) hasTimeTag() bool {
+	return true
+}
+
+// decodeProxyHead 解析代理列表中一个服务器的目标地址、超时时间及元素个数
+func decodeProxyHead(buf *bytes.Reader) (*TSA, *LongUnsigned, byte, error) {
+	tsa, err := decodeProxyTsa(buf)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	timeOut := &LongUnsigned{}
+	if err = timeOut.decoder(buf); err != nil {
+		return nil, nil, 0, err
+	}
+	length, err := buf.ReadByte()
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return tsa, timeOut, length, nil
+}
+
+// encodeProxyHead 编码代理列表中一个服务器的目标地址、超时时间及元素个数
+func encodeProxyHead(tsa *TSA, timeOut *LongUnsigned, length int) ([]byte, error) {
+	encodeArray, err := encodeProxyTsa(tsa)
+	if err != nil {
+		return nil, err
+	}
+	if timeOut == nil {
+		return nil, errors.New("proxy time out must not be nil")
+	}
+	toArray, err := timeOut.encoder()
+	if err != nil {
+		return nil, err
+	}
+	encodeArray = append(encodeArray, toArray...)
+	return append(encodeArray, byte(length)), nil
+}
+
+// decodeProxyTsa 解析目标服务器地址
+func decodeProxyTsa(buf *bytes.Reader) (*TSA, error) {
+	tsa := &TSA{}
+	if err := tsa.decoder(buf); err != nil {
+		return nil, err
+	}
+	return tsa, nil
+}
+
+// encodeProxyTsa 编码目标服务器地址
+func encodeProxyTsa(tsa *TSA) ([]byte, error) {
+	if tsa == nil {
+		return nil, errors.New("proxy tsa must not be nil")
+	}
+	return tsa.encoder()
+}
@@ -0,0 +1,724 @@
+package dlt698
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var _ APDURegion = (*ProxyGetResponseList)(nil)
+var _ APDURegion = (*ProxyGetResponseRecord)(nil)
+var _ APDURegion = (*ProxySetResponseList)(nil)
+var _ APDURegion = (*ProxySetThenGetResponseList)(nil)
+var _ APDURegion = (*ProxyActionResponseList)(nil)
+var _ APDURegion = (*ProxyActionThenGetResponseList)(nil)
+var _ APDURegion = (*ProxyTransCommandResponse)(nil)
+
+var _ FrameRegion = (*ProxyGetResponseListItem)(nil)
+var _ FrameRegion = (*ProxySetResponseListItem)(nil)
+var _ FrameRegion = (*ProxySetThenGetResponseListItem)(nil)
+var _ FrameRegion = (*ProxyActionResponseListItem)(nil)
+var _ FrameRegion = (*ProxyActionThenGetResponseListItem)(nil)
+
+const (
+	ProxyGetResponseListIdent           string = "8901"
+	ProxyGetResponseRecordIdent         string = "8902"
+	ProxySetResponseListIdent           string = "8903"
+	ProxySetThenGetResponseListIdent    string = "8904"
+	ProxyActionResponseListIdent        string = "8905"
+	ProxyActionThenGetResponseListIdent string = "8906"
+	ProxyTransCommandResponseIdent      string = "8907"
+)
+
+func init() {
+	apduMap[ProxyGetResponseListIdent] = func() APDURegion {
+		return new(ProxyGetResponseList)
+	}
+	apduMap[ProxyGetResponseRecordIdent] = func() APDURegion {
+		return new(ProxyGetResponseRecord)
+	}
+	apduMap[ProxySetResponseListIdent] = func() APDURegion {
+		return new(ProxySetResponseList)
+	}
+	apduMap[ProxySetThenGetResponseListIdent] = func() APDURegion {
+		return new(ProxySetThenGetResponseList)
+	}
+	apduMap[ProxyActionResponseListIdent] = func() APDURegion {
+		return new(ProxyActionResponseList)
+	}
+	apduMap[ProxyActionThenGetResponseListIdent] = func() APDURegion {
+		return new(ProxyActionThenGetResponseList)
+	}
+	apduMap[ProxyTransCommandResponseIdent] = func() APDURegion {
+		return new(ProxyTransCommandResponse)
+	}
+}
+
+type ProxyGetResponseList struct {
+	Data []*ProxyGetResponseListItem `json:"data"` //若干个服务器的读取结果
+}
+
+func (p *ProxyGetResponseList) decoder(buf *bytes.Reader) error {
+	length, err := buf.ReadByte()
+	if err != nil {
+		return errors.New("ProxyGetResponseList decode err: " + err.Error())
+	}
+	p.Data = make([]*ProxyGetResponseListItem, length)
+	for i := 0; i < int(length); i++ {
+		p.Data[i] = &ProxyGetResponseListItem{}
+		if err = p.Data[i].decoder(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProxyGetResponseList) encoder() ([]byte, error) {
+	encodeArray := []byte{byte(len(p.Data))}
+	for _, item := range p.Data {
+		itemArray, err := item.encoder()
+		if err != nil {
+			return nil, err
+		}
+		encodeArray = append(encodeArray, itemArray...)
+	}
+	return encodeArray, nil
+}
+
+func (p *ProxyGetResponseList) APDUType() string {
+	return ProxyGetResponseListIdent
+}
+
+func (p *ProxyGetResponseList) APDUMark() string {
+	return "proxy_get_response_list"
+}
+
+func (p *ProxyGetResponseList) hasFollowReport() bool {
+	return true
+}
+
+func (p *ProxyGetResponseList) hasTimeTag() bool {
+	return true
+}
+
+// Err 合并所有服务器读取结果中的错误
+func (p *ProxyGetResponseList) Err() error {
+	errs := make([]error, 0, len(p.Data))
+	for _, item := range p.Data {
+		errs = append(errs, item.Err())
+	}
+	return joinErrors(errs)
+}
+
+type ProxyGetResponseListItem struct {
+	Tsa  *TSA            `json:"tsa"`  //一个目标服务器地址
+	Data []*ResultNormal `json:"data"` //若干个对象属性及结果
+}
+
+func (p *ProxyGetResponseListItem) decoder(buf *bytes.Reader) error {
+	var err error
+	if p.Tsa, err = decodeProxyTsa(buf); err != nil {
+		return errors.New("ProxyGetResponseListItem decode err: " + err.Error())
+	}
+	length, err := buf.ReadByte()
+	if err != nil {
+		return errors.New("ProxyGetResponseListItem decode err: " + err.Error())
+	}
+	p.Data = make([]*ResultNormal, length)
+	for i := 0; i < int(length); i++ {
+		p.Data[i] = &ResultNormal{}
+		if err = p.Data[i].decoder(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProxyGetResponseListItem) encoder() ([]byte, error) {
+	encodeArray, err := encodeProxyTsa(p.Tsa)
+	if err != nil {
+		return nil, err
+	}
+	encodeArray = append(encodeArray, byte(len(p.Data)))
+	for _, item := range p.Data {
+		itemArray, err := item.encoder()
+		if err != nil {
+			return nil, err
+		}
+		encodeArray = append(encodeArray, itemArray...)
+	}
+	return encodeArray, nil
+}
+
+// Err 合并该服务器所有读取结果中的错误
+func (p *ProxyGetResponseListItem) Err() error {
+	errs := make([]error, 0, len(p.Data))
+	for _, item := range p.Data {
+		errs = append(errs, item.Err())
+	}
+	return proxyTsaError(p.Tsa, joinErrors(errs))
+}
+
+/*-------------------------------------------*/
+
+type ProxyGetResponseRecord struct {
+	Tsa          *TSA          `json:"tsa"`           //目标服务器地址
+	ResultRecord *ResultRecord `json:"result_record"` //一个记录型对象属性及结果
+}
+
+func (p *ProxyGetResponseRecord) decoder(buf *bytes.Reader) error {
+	var err error
+	if p.Tsa, err = decodeProxyTsa(buf); err != nil {
+		return errors.New("ProxyGetResponseRecord decode err: " + err.Error())
+	}
+	p.ResultRecord = &ResultRecord{}
+	return p.ResultRecord.decoder(buf)
+}
+
+func (p *ProxyGetResponseRecord) encoder() ([]byte, error) {
+	encodeArray, err := encodeProxyTsa(p.Tsa)
+	if err != nil {
+		return nil, err
+	}
+	if p.ResultRecord == nil {
+		return nil, errors.New("ProxyGetResponseRecord encode err: result record is nil")
+	}
+	recordArray, err := p.ResultRecord.encoder()
+	if err != nil {
+		return nil, err
+	}
+	return append(encodeArray, recordArray...), nil
+}
+
+func (p *ProxyGetResponseRecord) APDUType() string {
+	return ProxyGetResponseRecordIdent
+}
+
+func (p *ProxyGetResponseRecord) APDUMark() string {
+	return "proxy_get_response_record"
+}
+
+func (p *ProxyGetResponseRecord) hasFollowReport() bool {
+	return true
+}
+
+func (p *ProxyGetResponseRecord) hasTimeTag() bool {
+	return true
+}
+
+// Err 读取结果为DAR时返回对应的错误
+func (p *ProxyGetResponseRecord) Err() error {
+	if p.ResultRecord == nil {
+		return nil
+	}
+	return proxyTsaError(p.Tsa, p.ResultRecord.Err())
+}
+
+/*-------------------------------------------*/
+
+type ProxySetResponseList struct {
+	Data []*ProxySetResponseListItem `json:"data"` //若干个服务器的设置结果
+}
+
+func (p *ProxySetResponseList) decoder(buf *bytes.Reader) error {
+	length, err := buf.ReadByte()
+	if err != nil {
+		return errors.New("ProxySetResponseList decode err: " + err.Error())
+	}
+	p.Data = make([]*ProxySetResponseListItem, length)
+	for i := 0; i < int(length); i++ {
+		p.Data[i] = &ProxySetResponseListItem{}
+		if err = p.Data[i].decoder(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProxySetResponseList) encoder() ([]byte, error) {
+	encodeArray := []byte{byte(len(p.Data))}
+	for _, item := range p.Data {
+		itemArray, err := item.encoder()
+		if err != nil {
+			return nil, err
+		}
+		encodeArray = append(encodeArray, itemArray...)
+	}
+	return encodeArray, nil
+}
+
+func (p *ProxySetResponseList) APDUType() string {
+	return ProxySetResponseListIdent
+}
+
+func (p *ProxySetResponseList) APDUMark() string {
+	return "proxy_set_response_list"
+}
+
+func (p *ProxySetResponseList) hasFollowReport() bool {
+	return true
+}
+
+func (p *ProxySetResponseList) hasTimeTag() bool {
+	return true
+}
+
+// Err 合并所有服务器设置结果中的错误
+func (p *ProxySetResponseList) Err() error {
+	errs := make([]error, 0, len(p.Data))
+	for _, item := range p.Data {
+		errs = append(errs, item.Err())
+	}
+	return joinErrors(errs)
+}
+
+type ProxySetResponseListItem struct {
+	Tsa  *TSA                 `json:"tsa"`  //一个目标服务器地址
+	Data []*SetResponseNormal `json:"data"` //若干个对象属性设置结果
+}
+
+func (p *ProxySetResponseListItem) decoder(buf *bytes.Reader) error {
+	var err error
+	if p.Tsa, err = decodeProxyTsa(buf); err != nil {
+		return errors.New("ProxySetResponseListItem decode err: " + err.Error())
+	}
+	length, err := buf.ReadByte()
+	if err != nil {
+		return errors.New("ProxySetResponseListItem decode err: " + err.Error())
+	}
+	p.Data = make([]*SetResponseNormal, length)
+	for i := 0; i < int(length); i++ {
+		p.Data[i] = &SetResponseNormal{}
+		if err = p.Data[i].decoder(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProxySetResponseListItem) encoder() ([]byte, error) {
+	encodeArray, err := encodeProxyTsa(p.Tsa)
+	if err != nil {
+		return nil, err
+	}
+	encodeArray = append(encodeArray, byte(len(p.Data)))
+	for _, item := range p.Data {
+		itemArray, err := item.encoder()
+		if err != nil {
+			return nil, err
+		}
+		encodeArray = append(encodeArray, itemArray...)
+	}
+	return encodeArray, nil
+}
+
+// Err 合并该服务器所有设置结果中的错误
+func (p *ProxySetResponseListItem) Err() error {
+	errs := make([]error, 0, len(p.Data))
+	for _, item := range p.Data {
+		errs = append(errs, item.Err())
+	}
+	return proxyTsaError(p.Tsa, joinErrors(errs))
+}
+
+/*-------------------------------------------*/
+
+type ProxySetThenGetResponseList struct {
+	Data []*ProxySetThenGetResponseListItem `json:"data"` //若干个服务器的设置后读取结果
+}
+
+func (p *ProxySetThenGetResponseList) decoder(buf *bytes.Reader) error {
+	length, err := buf.ReadByte()
+	if err != nil {
+		return errors.New("ProxySetThenGetResponseList decode err: " + err.Error())
+	}
+	p.Data = make([]*ProxySetThenGetResponseListItem, length)
+	for i := 0; i < int(length); i++ {
+		p.Data[i] = &ProxySetThenGetResponseListItem{}
+		if err = p.Data[i].decoder(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProxySetThenGetResponseList) encoder() ([]byte, error) {
+	encodeArray := []byte{byte(len(p.Data))}
+	for _, item := range p.Data {
+		itemArray, err := item.encoder()
+		if err != nil {
+			return nil, err
+		}
+		encodeArray = append(encodeArray, itemArray...)
+	}
+	return encodeArray, nil
+}
+
+func (p *ProxySetThenGetResponseList) APDUType() string {
+	return ProxySetThenGetResponseListIdent
+}
+
+func (p *ProxySetThenGetResponseList) APDUMark() string {
+	return "proxy_set_then_get_response_list"
+}
+
+func (p *ProxySetThenGetResponseList) hasFollowReport() bool {
+	return true
+}
+
+func (p *ProxySetThenGetResponseList) hasTimeTag() bool {
+	return true
+}
+
+// Err 合并所有服务器设置与读取结果中的错误
+func (p *ProxySetThenGetResponseList) Err() error {
+	errs := make([]error, 0, len(p.Data))
+	for _, item := range p.Data {
+		errs = append(errs, item.Err())
+	}
+	return joinErrors(errs)
+}
+
+type ProxySetThenGetResponseListItem struct {
+	Tsa  *TSA                                `json:"tsa"`  //一个目标服务器地址
+	Data []*SetThenGetResponseNormalListItem `json:"data"` //若干个对象属性设置后读取结果
+}
+
+func (p *ProxySetThenGetResponseListItem) decoder(buf *bytes.Reader) error {
+	var err error
+	if p.Tsa, err = decodeProxyTsa(buf); err != nil {
+		return errors.New("ProxySetThenGetResponseListItem decode err: " + err.Error())
+	}
+	length, err := buf.ReadByte()
+	if err != nil {
+		return errors.New("ProxySetThenGetResponseListItem decode err: " + err.Error())
+	}
+	p.Data = make([]*SetThenGetResponseNormalListItem, length)
+	for i := 0; i < int(length); i++ {
+		p.Data[i] = &SetThenGetResponseNormalListItem{}
+		if err = p.Data[i].decoder(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProxySetThenGetResponseListItem) encoder() ([]byte, error) {
+	encodeArray, err := encodeProxyTsa(p.Tsa)
+	if err != nil {
+		return nil, err
+	}
+	encodeArray = append(encodeArray, byte(len(p.Data)))
+	for _, item := range p.Data {
+		itemArray, err := item.encoder()
+		if err != nil {
+			return nil, err
+		}
+		encodeArray = append(encodeArray, itemArray...)
+	}
+	return encodeArray, nil
+}
+
+// Err 合并该服务器所有设置与读取结果中的错误
+func (p *ProxySetThenGetResponseListItem) Err() error {
+	errs := make([]error, 0, len(p.Data))
+	for _, item := range p.Data {
+		errs = append(errs, item.Err())
+	}
+	return proxyTsaError(p.Tsa, joinErrors(errs))
+}
+
+/*-------------------------------------------*/
+
+type ProxyActionResponseList struct {
+	Data []*ProxyActionResponseListItem `json:"data"` //若干个服务器的操作结果
+}
+
+func (p *ProxyActionResponseList) decoder(buf *bytes.Reader) error {
+	length, err := buf.ReadByte()
+	if err != nil {
+		return errors.New("ProxyActionResponseList decode err: " + err.Error())
+	}
+	p.Data = make([]*ProxyActionResponseListItem, length)
+	for i := 0; i < int(length); i++ {
+		p.Data[i] = &ProxyActionResponseListItem{}
+		if err = p.Data[i].decoder(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProxyActionResponseList) encoder() ([]byte, error) {
+	encodeArray := []byte{byte(len(p.Data))}
+	for _, item := range p.Data {
+		itemArray, err := item.encoder()
+		if err != nil {
+			return nil, err
+		}
+		encodeArray = append(encodeArray, itemArray...)
+	}
+	return encodeArray, nil
+}
+
+func (p *ProxyActionResponseList) APDUType() string {
+	return ProxyActionResponseListIdent
+}
+
+func (p *ProxyActionResponseList) APDUMark() string {
+	return "proxy_action_response_list"
+}
+
+func (p *ProxyActionResponseList) hasFollowReport() bool {
+	return true
+}
+
+func (p *ProxyActionResponseList) hasTimeTag() bool {
+	return true
+}
+
+// Err 合并所有服务器操作结果中的错误
+func (p *ProxyActionResponseList) Err() error {
+	errs := make([]error, 0, len(p.Data))
+	for _, item := range p.Data {
+		errs = append(errs, item.Err())
+	}
+	return joinErrors(errs)
+}
+
+type ProxyActionResponseListItem struct {
+	Tsa  *TSA                    `json:"tsa"`  //一个目标服务器地址
+	Data []*ActionResponseNormal `json:"data"` //若干个对象方法操作结果
+}
+
+func (p *ProxyActionResponseListItem) decoder(buf *bytes.Reader) error {
+	var err error
+	if p.Tsa, err = decodeProxyTsa(buf); err != nil {
+		return errors.New("ProxyActionResponseListItem decode err: " + err.Error())
+	}
+	length, err := buf.ReadByte()
+	if err != nil {
+		return errors.New("ProxyActionResponseListItem decode err: " + err.Error())
+	}
+	p.Data = make([]*ActionResponseNormal, length)
+	for i := 0; i < int(length); i++ {
+		p.Data[i] = &ActionResponseNormal{}
+		if err = p.Data[i].decoder(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProxyActionResponseListItem) encoder() ([]byte, error) {
+	encodeArray, err := encodeProxyTsa(p.Tsa)
+	if err != nil {
+		return nil, err
+	}
+	encodeArray = append(encodeArray, byte(len(p.Data)))
+	for _, item := range p.Data {
+		itemArray, err := item.encoder()
+		if err != nil {
+			return nil, err
+		}
+		encodeArray = append(encodeArray, itemArray...)
+	}
+	return encodeArray, nil
+}
+
+// Err 合并该服务器所有操作结果中的错误
+func (p *ProxyActionResponseListItem) Err() error {
+	errs := make([]error, 0, len(p.Data))
+	for _, item := range p.Data {
+		errs = append(errs, item.Err())
+	}
+	return proxyTsaError(p.Tsa, joinErrors(errs))
+}
+
+/*-------------------------------------------*/
+
+type ProxyActionThenGetResponseList struct {
+	Data []*ProxyActionThenGetResponseListItem `json:"data"` //若干个服务器的操作后读取结果
+}
+
+func (p *ProxyActionThenGetResponseList) decoder(buf *bytes.Reader) error {
+	length, err := buf.ReadByte()
+	if err != nil {
+		return errors.New("ProxyActionThenGetResponseList decode err: " + err.Error())
+	}
+	p.Data = make([]*ProxyActionThenGetResponseListItem, length)
+	for i := 0; i < int(length); i++ {
+		p.Data[i] = &ProxyActionThenGetResponseListItem{}
+		if err = p.Data[i].decoder(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProxyActionThenGetResponseList) encoder() ([]byte, error) {
+	encodeArray := []byte{byte(len(p.Data))}
+	for _, item := range p.Data {
+		itemArray, err := item.encoder()
+		if err != nil {
+			return nil, err
+		}
+		encodeArray = append(encodeArray, itemArray...)
+	}
+	return encodeArray, nil
+}
+
+func (p *ProxyActionThenGetResponseList) APDUType() string {
+	return ProxyActionThenGetResponseListIdent
+}
+
+func (p *ProxyActionThenGetResponseList) APDUMark() string {
+	return "proxy_action_then_get_response_list"
+}
+
+func (p *ProxyActionThenGetResponseList) hasFollowReport() bool {
+	return true
+}
+
+func (p *ProxyActionThenGetResponseList) hasTimeTag() bool {
+	return true
+}
+
+// Err 合并所有服务器操作与读取结果中的错误
+func (p *ProxyActionThenGetResponseList) Err() error {
+	errs := make([]error, 0, len(p.Data))
+	for _, item := range p.Data {
+		errs = append(errs, item.Err())
+	}
+	return joinErrors(errs)
+}
+
+type ProxyActionThenGetResponseListItem struct {
+	Tsa  *TSA                                   `json:"tsa"`  //一个目标服务器地址
+	Data []*ActionThenGetResponseNormalListItem `json:"data"` //若干个对象方法操作后读取结果
+}
+
+func (p *ProxyActionThenGetResponseListItem) decoder(buf *bytes.Reader) error {
+	var err error
+	if p.Tsa, err = decodeProxyTsa(buf); err != nil {
+		return errors.New("ProxyActionThenGetResponseListItem decode err: " + err.Error())
+	}
+	length, err := buf.ReadByte()
+	if err != nil {
+		return errors.New("ProxyActionThenGetResponseListItem decode err: " + err.Error())
+	}
+	p.Data = make([]*ActionThenGetResponseNormalListItem, length)
+	for i := 0; i < int(length); i++ {
+		p.Data[i] = &ActionThenGetResponseNormalListItem{}
+		if err = p.Data[i].decoder(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProxyActionThenGetResponseListItem) encoder() ([]byte, error) {
+	encodeArray, err := encodeProxyTsa(p.Tsa)
+	if err != nil {
+		return nil, err
+	}
+	encodeArray = append(encodeArray, byte(len(p.Data)))
+	for _, item := range p.Data {
+		itemArray, err := item.encoder()
+		if err != nil {
+			return nil, err
+		}
+		encodeArray = append(encodeArray, itemArray...)
+	}
+	return encodeArray, nil
+}
+
+// Err 合并该服务器所有操作与读取结果中的错误
+func (p *ProxyActionThenGetResponseListItem) Err() error {
+	errs := make([]error, 0, len(p.Data))
+	for _, item := range p.Data {
+		errs = append(errs, item.Err())
+	}
+	return proxyTsaError(p.Tsa, joinErrors(errs))
+}
+
+/*-------------------------------------------*/
+
+type ProxyTransCommandResponse struct {
+	Oad     []byte       `json:"oad"`     //数据转发端口
+	Dar     *DAR         `json:"dar"`     //错误信息，与Command二选一
+	Command *OctetString `json:"command"` //返回数据
+}
+
+func (p *ProxyTransCommandResponse) decoder(buf *bytes.Reader) error {
+	p.Oad = make([]byte, 4)
+	if err := binary.Read(buf, binary.LittleEndian, &p.Oad); err != nil {
+		return errors.New("ProxyTransCommandResponse decode err: " + err.Error())
+	}
+	resultType, err := buf.ReadByte()
+	if err != nil {
+		return errors.New("ProxyTransCommandResponse decode err: " + err.Error())
+	}
+	switch resultType {
+	case 0:
+		p.Dar = &DAR{}
+		return p.Dar.decoder(buf)
+	case 1:
+		p.Command = &OctetString{}
+		return p.Command.decoder(buf)
+	default:
+		return errors.New("ProxyTransCommandResponse decode err: unknown result type")
+	}
+}
+
+func (p *ProxyTransCommandResponse) encoder() ([]byte, error) {
+	if len(p.Oad) != 4 {
+		return nil, errors.New("ProxyTransCommandResponse encode err: oad size != 4")
+	}
+	encodeArray := append([]byte{}, p.Oad...)
+	if p.Dar != nil {
+		return append(encodeArray, 0x00, byte(p.Dar.Data)), nil
+	}
+	if p.Command == nil {
+		return nil, errors.New("ProxyTransCommandResponse encode err: dar and command are nil")
+	}
+	commandArray, err := p.Command.encoder()
+	if err != nil {
+		return nil, err
+	}
+	encodeArray = append(encodeArray, 0x01)
+	return append(encodeArray, commandArray...), nil
+}
+
+func (p *ProxyTransCommandResponse) APDUType() string {
+	return ProxyTransCommandResponseIdent
+}
+
+func (p *ProxyTransCommandResponse) APDUMark() string {
+	return "proxy_trans_command_response"
+}
+
+func (p *ProxyTransCommandResponse) hasFollowReport() bool {
+	return true
+}
+
+func (p *ProxyTransCommandResponse) hasTimeTag() bool {
+	return true
+}
+
+// Err 转发失败时返回附带端口OAD的错误
+func (p *ProxyTransCommandResponse) Err() error {
+	if p.Dar == nil {
+		return nil
+	}
+	return oadError(p.Oad, p.Dar)
+}
+
+// proxyTsaError 为代理结果的错误附加目标服务器地址
+func proxyTsaError(tsa *TSA, err error) error {
+	if err == nil || tsa == nil {
+		return err
+	}
+	return fmt.Errorf("tsa %s: %w", tsa.Address, err)
+}
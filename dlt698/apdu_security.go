@@ -0,0 +1,301 @@
+package dlt698
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+)
+
+var _ FrameRegion = (*RNMAC)(nil)
+
+var _ APDURegion = (*SecurityRequest)(nil)
+var _ APDURegion = (*SecurityResponse)(nil)
+
+const (
+	SecurityRequestIdent  string = "10"
+	SecurityResponseIdent string = "90"
+)
+
+const (
+	SecurityPlaintext  byte = 0 //明文应用数据单元
+	SecurityCiphertext byte = 1 //密文应用数据单元
+	SecurityDar        byte = 2 //异常错误，仅用于安全响应
+
+	SecurityVerifySIDMAC byte = 0 //数据验证码 SID_MAC
+	SecurityVerifyRN     byte = 1 //随机数 RN
+	SecurityVerifyRNMAC  byte = 2 //随机数+数据MAC RN_MAC
+	SecurityVerifySID    byte = 3 //安全标识 SID
+)
+
+func init() {
+	apduMap[SecurityRequestIdent] = func() APDURegion {
+		return new(SecurityRequest)
+	}
+	apduMap[SecurityResponseIdent] = func() APDURegion {
+		return new(SecurityResponse)
+	}
+}
+
+// securityRegion 安全传输的APDU，没有PIID、跟随上报信息域与时间标签
+type securityRegion interface {
+	APDURegion
+	security()
+}
+
+// SecurityRequest 安全请求
+type SecurityRequest struct {
+	DataType byte         `json:"data_type"` //应用数据单元类型 0-明文 1-密文
+	Data     *OctetString `json:"data"`      //应用数据单元
+	Apdu     *APDU        `json:"apdu"`      //明文应用数据单元的解析结果
+	Verify   FrameRegion  `json:"verify"`    //数据验证信息 SID_MAC/RN/RN_MAC/SID
+}
+
+func (s *SecurityRequest) decoder(buf *bytes.Reader) error {
+	var err error
+	s.DataType, s.Data, s.Apdu, err = decodeSecurityData(buf)
+	if err != nil {
+		return errors.New("SecurityRequest decode err: " + err.Error())
+	}
+	if s.DataType != SecurityPlaintext && s.DataType != SecurityCiphertext {
+		return errors.New("SecurityRequest decode err: unknown data type")
+	}
+	verifyType, err := buf.ReadByte()
+	if err != nil {
+		return errors.New("SecurityRequest decode err: " + err.Error())
+	}
+	switch verifyType {
+	case SecurityVerifySIDMAC:
+		s.Verify = &SIDMAC{}
+	case SecurityVerifyRN:
+		s.Verify = &RN{}
+	case SecurityVerifyRNMAC:
+		s.Verify = &RNMAC{}
+	case SecurityVerifySID:
+		s.Verify = &SID{}
+	default:
+		return errors.New("SecurityRequest decode err: unknown verify type")
+	}
+	return s.Verify.decoder(buf)
+}
+
+func (s *SecurityRequest) encoder() ([]byte, error) {
+	if s.DataType != SecurityPlaintext && s.DataType != SecurityCiphertext {
+		return nil, errors.New("SecurityRequest encode err: unknown data type")
+	}
+	encodeArray, err := encodeSecurityData(s.DataType, s.Data, s.Apdu)
+	if err != nil {
+		return nil, err
+	}
+	var verifyType byte
+	switch s.Verify.(type) {
+	case *SIDMAC:
+		verifyType = SecurityVerifySIDMAC
+	case *RN:
+		verifyType = SecurityVerifyRN
+	case *RNMAC:
+		verifyType = SecurityVerifyRNMAC
+	case *SID:
+		verifyType = SecurityVerifySID
+	default:
+		return nil, errors.New("SecurityRequest encode err: unknown verify type")
+	}
+	verifyArray, err := s.Verify.encoder()
+	if err != nil {
+		return nil, err
+	}
+	encodeArray = append(encodeArray, verifyType)
+	return append(encodeArray, verifyArray...), nil
+}
+
+func (s *SecurityRequest) APDUType() string {
+	return SecurityRequestIdent
+}
+
+func (s *SecurityRequest) APDUMark() string {
+	return "security_request"
+}
+
+func (s *SecurityRequest) hasFollowReport() bool {
+	return false
+}
+
+func (s *SecurityRequest) hasTimeTag() bool {
+	return false
+}
+
+func (s *SecurityRequest) security() {}
+
+/*--------------------------------SECURITY-Response---------------------------------*/
+
+// SecurityResponse 安全响应
+type SecurityResponse struct {
+	DataType byte         `json:"data_type"` //应用数据单元类型 0-明文 1-密文 2-异常错误
+	Data     *OctetString `json:"data"`      //应用数据单元
+	Apdu     *APDU        `json:"apdu"`      //明文应用数据单元的解析结果
+	Dar      *DAR         `json:"dar"`       //异常错误
+	Mac      *MAC         `json:"mac"`       //数据验证信息 数据MAC，可以为nil
+}
+
+func (s *SecurityResponse) decoder(buf *bytes.Reader) error {
+	dataType, err := buf.ReadByte()
+	if err != nil {
+		return errors.New("SecurityResponse decode err: " + err.Error())
+	}
+	if dataType == SecurityDar {
+		s.DataType = dataType
+		s.Dar = new(DAR)
+		if err = s.Dar.decoder(buf); err != nil {
+			return err
+		}
+	} else {
+		if err = buf.UnreadByte(); err != nil {
+			return err
+		}
+		s.DataType, s.Data, s.Apdu, err = decodeSecurityData(buf)
+		if err != nil {
+			return errors.New("SecurityResponse decode err: " + err.Error())
+		}
+		if s.DataType != SecurityPlaintext && s.DataType != SecurityCiphertext {
+			return errors.New("SecurityResponse decode err: unknown data type")
+		}
+	}
+	hasVerify, err := buf.ReadByte()
+	if err != nil {
+		return errors.New("SecurityResponse decode err: " + err.Error())
+	}
+	if hasVerify == 0 {
+		return nil
+	}
+	verifyType, err := buf.ReadByte()
+	if err != nil {
+		return errors.New("SecurityResponse decode err: " + err.Error())
+	}
+	if verifyType != 0 {
+		return errors.New("SecurityResponse decode err: unknown verify type")
+	}
+	s.Mac = &MAC{}
+	return s.Mac.decoder(buf)
+}
+
+func (s *SecurityResponse) encoder() ([]byte, error) {
+	var encodeArray []byte
+	var err error
+	switch s.DataType {
+	case SecurityPlaintext, SecurityCiphertext:
+		encodeArray, err = encodeSecurityData(s.DataType, s.Data, s.Apdu)
+		if err != nil {
+			return nil, err
+		}
+	case SecurityDar:
+		if s.Dar == nil {
+			return nil, errors.New("SecurityResponse encode err: dar is nil")
+		}
+		darArray, err := s.Dar.encoder()
+		if err != nil {
+			return nil, err
+		}
+		encodeArray = append([]byte{SecurityDar}, darArray...)
+	default:
+		return nil, errors.New("SecurityResponse encode err: unknown data type")
+	}
+	if s.Mac == nil {
+		return append(encodeArray, 0x00), nil
+	}
+	macArray, err := s.Mac.encoder()
+	if err != nil {
+		return nil, err
+	}
+	encodeArray = append(encodeArray, 0x01, 0x00)
+	return append(encodeArray, macArray...), nil
+}
+
+func (s *SecurityResponse) APDUType() string {
+	return SecurityResponseIdent
+}
+
+func (s *SecurityResponse) APDUMark() string {
+	return "security_response"
+}
+
+func (s *SecurityResponse) hasFollowReport() bool {
+	return false
+}
+
+func (s *SecurityResponse) hasTimeTag() bool {
+	return false
+}
+
+func (s *SecurityResponse) security() {}
+
+/*--------------------------------RN_MAC---------------------------------*/
+
+// RNMAC 随机数+数据MAC
+type RNMAC struct {
+	Rn  *RN  `json:"rn"`
+	Mac *MAC `json:"mac"`
+}
+
+func (r *RNMAC) decoder(buf *bytes.Reader) error {
+	r.Rn = &RN{}
+	if err := r.Rn.decoder(buf); err != nil {
+		return err
+	}
+	r.Mac = &MAC{}
+	return r.Mac.decoder(buf)
+}
+
+func (r *RNMAC) encoder() ([]byte, error) {
+	rnArray, err := r.Rn.encoder()
+	if err != nil {
+		return nil, err
+	}
+	macArray, err := r.Mac.encoder()
+	if err != nil {
+		return nil, err
+	}
+	return append(rnArray, macArray...), nil
+}
+
+// decodeSecurityData 解析应用数据单元，明文时递归解析内层APDU
+func decodeSecurityData(buf *bytes.Reader) (byte, *OctetString, *APDU, error) {
+	dataType, err := buf.ReadByte()
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	length, err := decodeVarLength(buf)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	array := make([]byte, length)
+	if err = binary.Read(buf, binary.BigEndian, &array); err != nil {
+		return 0, nil, nil, err
+	}
+	data := &OctetString{Data: hex.EncodeToString(array)}
+	if dataType != SecurityPlaintext {
+		return dataType, data, nil, nil
+	}
+	apdu := &APDU{}
+	if err = apdu.decoder(bytes.NewReader(array)); err != nil {
+		return 0, nil, nil, err
+	}
+	return dataType, data, apdu, nil
+}
+
+// encodeSecurityData 编码应用数据单元，明文时优先使用Apdu
+func encodeSecurityData(dataType byte, data *OctetString, apdu *APDU) ([]byte, error) {
+	var array []byte
+	var err error
+	if dataType == SecurityPlaintext && apdu != nil {
+		array, err = apdu.encoder()
+	} else if data != nil {
+		array, err = hex.DecodeString(data.Data)
+	} else {
+		err = errors.New("security data is nil")
+	}
+	if err != nil {
+		return nil, errors.New("encode security data err: " + err.Error())
+	}
+	encodeArray := append([]byte{dataType}, encodeVarLength(len(array))...)
+	return append(encodeArray, array...), nil
+}
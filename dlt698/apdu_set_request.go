@@ -208,6 +208,9 @@ func (s *SetThenGetRequestItem) decoder(buf *bytes.Reader) error {
 		return errors.New("SetThenGetRequestItem decode err: " + err.Error())
 	}
 	s.Data = dataTranslate(dataType)
+	if s.Data == nil {
+		return errors.New("SetThenGetRequestItem decode err: data type not found")
+	}
 	err := s.Data.decoder(buf)
 	if err != nil {
 		return err
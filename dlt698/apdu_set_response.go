@@ -46,7 +46,7 @@ func (s *SetResponseNormal) decoder(buf *bytes.Reader) error {
 }
 
 func (s *SetResponseNormal) encoder() ([]byte, error) {
-	return append(s.Oad, s.Dar.Data), nil
+	return append(s.Oad, byte(s.Dar.Data)), nil
 }
 
 func (s *SetResponseNormal) APDUType() string {
@@ -65,6 +65,11 @@ func (s *SetResponseNormal) hasTimeTag() bool {
 	return true
 }
 
+// Err 设置结果非成功时返回附带OAD的错误
+func (s *SetResponseNormal) Err() error {
+	return oadError(s.Oad, s.Dar)
+}
+
 /*----------------------------------*/
 
 type SetResponseNormalList struct {
@@ -122,6 +127,15 @@ func (s *SetResponseNormalList) hasTimeTag() bool {
 	return true
 }
 
+// Err 合并所有设置结果中的错误
+func (s *SetResponseNormalList) Err() error {
+	errs := make([]error, 0, len(s.Data))
+	for _, d := range s.Data {
+		errs = append(errs, d.Err())
+	}
+	return joinErrors(errs)
+}
+
 /*-------------------------------------------------*/
 
 type SetThenGetResponseNormalList struct {
@@ -180,6 +194,15 @@ func (s *SetThenGetResponseNormalList) hasTimeTag() bool {
 	return true
 }
 
+// Err 合并所有设置与读取结果中的错误
+func (s *SetThenGetResponseNormalList) Err() error {
+	errs := make([]error, 0, len(s.Data))
+	for _, d := range s.Data {
+		errs = append(errs, d.Err())
+	}
+	return joinErrors(errs)
+}
+
 type SetThenGetResponseNormalListItem struct {
 	Oad          []byte        `json:"oad"`
 	Dar          *DAR          `json:"dar"`
@@ -203,10 +226,19 @@ func (s *SetThenGetResponseNormalListItem) decoder(buf *bytes.Reader) error {
 }
 
 func (s *SetThenGetResponseNormalListItem) encoder() ([]byte, error) {
-	encodeArray := append(s.Oad, s.Dar.Data)
+	encodeArray := append(s.Oad, byte(s.Dar.Data))
 	dataArray, err := s.ResultNormal.encoder()
 	if err != nil {
 		return nil, err
 	}
 	return append(encodeArray, dataArray...), nil
 }
+
+// Err 合并设置结果与读取结果中的错误
+func (s *SetThenGetResponseNormalListItem) Err() error {
+	errs := []error{oadError(s.Oad, s.Dar)}
+	if s.ResultNormal != nil {
+		errs = append(errs, s.ResultNormal.Err())
+	}
+	return joinErrors(errs)
+}
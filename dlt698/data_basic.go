@@ -19,6 +19,9 @@ var _ DataInter = (*LongUnsigned)(nil)
 var _ DataInter = (*Enum)(nil)
 var _ DataInter = (*Double32)(nil)
 var _ DataInter = (*Double64)(nil)
+var _ DataInter = (*Long64)(nil)
+var _ DataInter = (*Long64Unsigned)(nil)
+var _ DataInter = (*BCD)(nil)
 
 const (
 	NullIdent               byte = 0
@@ -27,10 +30,13 @@ const (
 	BooleanIdent            byte = 3
 	DoubleLongIdent         byte = 5
 	DoubleLongUnsignedIdent byte = 6
+	BCDIdent                byte = 13
 	IntegerIdent            byte = 15
 	LongIdent               byte = 16
 	UnsignedIdent           byte = 17
 	LongUnsignedIdent       byte = 18
+	Long64Ident             byte = 20
+	Long64UnsignedIdent     byte = 21
 	EnumIdent               byte = 22
 	Double32Ident           byte = 23
 	Double64Ident           byte = 24
@@ -76,6 +82,121 @@ func init() {
 	dataMap[Double64Ident] = func() DataInter {
 		return &Double64{}
 	}
+	dataMap[Long64Ident] = func() DataInter {
+		return &Long64{}
+	}
+	dataMap[Long64UnsignedIdent] = func() DataInter {
+		return &Long64Unsigned{}
+	}
+	dataMap[BCDIdent] = func() DataInter {
+		return &BCD{}
+	}
+}
+
+/*-------------------------------------------------*/
+
+type Long64 struct {
+	Data int64 `json:"data"`
+}
+
+func NewLong64(data int64) *Long64 {
+	return &Long64{Data: data}
+}
+
+func (l *Long64) decoder(buf *bytes.Reader) error {
+	if err := binary.Read(buf, binary.BigEndian, &l.Data); err != nil {
+		return errors.New("decode data<Long64> err: " + err.Error())
+	}
+	return nil
+}
+
+func (l *Long64) encoder() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	err := binary.Write(buf, binary.BigEndian, l.Data)
+	if err != nil {
+		return nil, errors.New("encode data<Long64> err: " + err.Error())
+	}
+	return buf.Bytes(), nil
+}
+
+func (l *Long64) DataType() byte {
+	return Long64Ident
+}
+
+func (l *Long64) Value() interface{} {
+	return l.Data
+}
+
+/*-------------------------------------------------*/
+
+type Long64Unsigned struct {
+	Data uint64 `json:"data"`
+}
+
+func NewLong64Unsigned(data uint64) *Long64Unsigned {
+	return &Long64Unsigned{Data: data}
+}
+
+func (l *Long64Unsigned) decoder(buf *bytes.Reader) error {
+	if err := binary.Read(buf, binary.BigEndian, &l.Data); err != nil {
+		return errors.New("decode data<Long64Unsigned> err: " + err.Error())
+	}
+	return nil
+}
+
+func (l *Long64Unsigned) encoder() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	err := binary.Write(buf, binary.BigEndian, l.Data)
+	if err != nil {
+		return nil, errors.New("encode data<Long64Unsigned> err: " + err.Error())
+	}
+	return buf.Bytes(), nil
+}
+
+func (l *Long64Unsigned) DataType() byte {
+	return Long64UnsignedIdent
+}
+
+func (l *Long64Unsigned) Value() interface{} {
+	return l.Data
+}
+
+/*-------------------------------------------------*/
+
+// BCD 一个字节的BCD码，Data为对应的十进制值0-99
+type BCD struct {
+	Data uint8 `json:"data"`
+}
+
+func NewBCD(data uint8) *BCD {
+	return &BCD{Data: data}
+}
+
+func (b *BCD) decoder(buf *bytes.Reader) error {
+	value, err := buf.ReadByte()
+	if err != nil {
+		return errors.New("decode data<BCD> err: " + err.Error())
+	}
+	if value>>4 > 9 || value&0x0F > 9 {
+		return errors.New("decode data<BCD> err: invalid bcd code")
+	}
+	b.Data = (value>>4)*10 + value&0x0F
+	return nil
+}
+
+func (b *BCD) encoder() ([]byte, error) {
+	if b.Data > 99 {
+		return nil, errors.New("encode data<BCD> err: value must be 0-99")
+	}
+	return []byte{(b.Data/10)<<4 | b.Data%10}, nil
+}
+
+func (b *BCD) DataType() byte {
+	return BCDIdent
+}
+
+func (b *BCD) Value() interface{} {
+	return b.Data
 }
 
 /*-------------------------------------------------*/
@@ -226,18 +347,23 @@ func (u *Unsigned) Value() interface{} {
 /*-----------------------------------------------------------*/
 
 type Long struct {
-	Data int8 `json:"data"`
+	Data int16 `json:"data"`
 }
 
 func (l *Long) decoder(buf *bytes.Reader) error {
-	if err := binary.Read(buf, binary.LittleEndian, &l.Data); err != nil {
+	if err := binary.Read(buf, binary.BigEndian, &l.Data); err != nil {
 		return errors.New("decode data<Long> err" + err.Error())
 	}
 	return nil
 }
 
 func (l *Long) encoder() ([]byte, error) {
-	return []byte{byte(l.Data)}, nil
+	buf := new(bytes.Buffer)
+	err := binary.Write(buf, binary.BigEndian, l.Data)
+	if err != nil {
+		return nil, errors.New("encode data<Long> err: " + err.Error())
+	}
+	return buf.Bytes(), nil
 }
 
 func (l *Long) DataType() byte {
@@ -365,12 +491,12 @@ type Structure struct {
 }
 
 func (s *Structure) decoder(buf *bytes.Reader) error {
-	var arrayLen byte
-	if err := binary.Read(buf, binary.LittleEndian, &arrayLen); err != nil {
-		return errors.New("decode data<Array> length err:" + err.Error())
+	arrayLen, err := decodeVarLength(buf)
+	if err != nil {
+		return errors.New("decode data<Structure> length err:" + err.Error())
 	}
 	s.DataArray = make([]DataInter, arrayLen)
-	for i := 0; i < int(arrayLen); i++ {
+	for i := 0; i < arrayLen; i++ {
 		dataType, err := buf.ReadByte()
 		if err != nil {
 			return err
@@ -392,7 +518,7 @@ func (s *Structure) encoder() ([]byte, error) {
 	if length == 0 {
 		return []byte{0x00}, nil
 	}
-	encodeArray := make([]byte, 0)
+	encodeArray := encodeVarLength(length)
 	for _, datatype := range s.DataArray {
 		dataArray, err := datatype.encoder()
 		if err != nil {
@@ -419,12 +545,12 @@ type Array struct {
 }
 
 func (a *Array) decoder(buf *bytes.Reader) error {
-	var arrayLen byte
-	if err := binary.Read(buf, binary.LittleEndian, &arrayLen); err != nil {
+	arrayLen, err := decodeVarLength(buf)
+	if err != nil {
 		return errors.New("decode data<Array> length err:" + err.Error())
 	}
 	a.DataArray = make([]DataInter, arrayLen)
-	for i := 0; i < int(arrayLen); i++ {
+	for i := 0; i < arrayLen; i++ {
 		dataType, err := buf.ReadByte()
 		if err != nil {
 			return err
@@ -446,7 +572,7 @@ func (a *Array) encoder() ([]byte, error) {
 	if length == 0 {
 		return []byte{0x00}, nil
 	}
-	encodeArray := make([]byte, 0)
+	encodeArray := encodeVarLength(length)
 	for _, datatype := range a.DataArray {
 		dataArray, err := datatype.encoder()
 		if err != nil {
@@ -0,0 +1,168 @@
+package dlt698
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var _ DataInter = (*DAR)(nil)
+var _ error = DARCode(0)
+
+// DARCode 数据访问结果，非成功的结果可以直接作为error使用，支持errors.Is比较
+type DARCode byte
+
+const (
+	DarSuccess                 DARCode = 0   //成功
+	DarHardwareFault           DARCode = 1   //硬件失效
+	DarTemporaryFailure        DARCode = 2   //暂时失效
+	DarAccessDenied            DARCode = 3   //拒绝读写
+	DarObjectUndefined         DARCode = 4   //对象未定义
+	DarObjectClassInconsistent DARCode = 5   //对象接口类不符合
+	DarObjectNotExist          DARCode = 6   //对象不存在
+	DarTypeUnmatched           DARCode = 7   //类型不匹配
+	DarOutOfRange              DARCode = 8   //越界
+	DarDataBlockUnavailable    DARCode = 9   //数据块不可用
+	DarLongGetAborted          DARCode = 10  //分帧传输已取消
+	DarNoLongGetInProgress     DARCode = 11  //不处于分帧传输状态
+	DarLongWriteAborted        DARCode = 12  //块写取消
+	DarNoLongWriteInProgress   DARCode = 13  //不存在块写状态
+	DarDataBlockNumberInvalid  DARCode = 14  //数据块序号无效
+	DarUnauthorized            DARCode = 15  //密码错/未授权
+	DarBaudRateUnchangeable    DARCode = 16  //通信速率不能更改
+	DarYearZoneExceeded        DARCode = 17  //年时区数超
+	DarDayPeriodExceeded       DARCode = 18  //日时段数超
+	DarTariffExceeded          DARCode = 19  //费率数超
+	DarSecurityUnmatched       DARCode = 20  //安全认证不匹配
+	DarDuplicateRecharge       DARCode = 21  //重复充值
+	DarESAMVerifyFailed        DARCode = 22  //ESAM验证失败
+	DarSecurityFailed          DARCode = 23  //安全认证失败
+	DarCustomerNumberUnmatched DARCode = 24  //客户编号不匹配
+	DarRechargeCountError      DARCode = 25  //充值次数错误
+	DarPurchaseExceeded        DARCode = 26  //购电超囤积
+	DarAddressAbnormal         DARCode = 27  //地址异常
+	DarSymmetricDecryptError   DARCode = 28  //对称解密错误
+	DarAsymmetricDecryptError  DARCode = 29  //非对称解密错误
+	DarSignatureError          DARCode = 30  //签名错误
+	DarMeterSuspended          DARCode = 31  //电能表挂起
+	DarTimeTagInvalid          DARCode = 32  //时间标签无效
+	DarRequestTimeout          DARCode = 33  //请求超时
+	DarESAMP1P2Error           DARCode = 34  //ESAM的P1P2不正确
+	DarESAMLCError             DARCode = 35  //ESAM的LC错误
+	DarCompareFailed           DARCode = 36  //比对失败
+	DarOther                   DARCode = 255 //其它
+)
+
+var darNames = map[DARCode]string{
+	DarSuccess:                 "success",
+	DarHardwareFault:           "hardware fault",
+	DarTemporaryFailure:        "temporary failure",
+	DarAccessDenied:            "access denied",
+	DarObjectUndefined:         "object undefined",
+	DarObjectClassInconsistent: "object class inconsistent",
+	DarObjectNotExist:          "object not exist",
+	DarTypeUnmatched:           "type unmatched",
+	DarOutOfRange:              "out of range",
+	DarDataBlockUnavailable:    "data block unavailable",
+	DarLongGetAborted:          "long get aborted",
+	DarNoLongGetInProgress:     "no long get in progress",
+	DarLongWriteAborted:        "long write aborted",
+	DarNoLongWriteInProgress:   "no long write in progress",
+	DarDataBlockNumberInvalid:  "data block number invalid",
+	DarUnauthorized:            "password error or unauthorized",
+	DarBaudRateUnchangeable:    "baud rate unchangeable",
+	DarYearZoneExceeded:        "year time zone exceeded",
+	DarDayPeriodExceeded:       "day period exceeded",
+	DarTariffExceeded:          "tariff exceeded",
+	DarSecurityUnmatched:       "security authentication unmatched",
+	DarDuplicateRecharge:       "duplicate recharge",
+	DarESAMVerifyFailed:        "esam verify failed",
+	DarSecurityFailed:          "security authentication failed",
+	DarCustomerNumberUnmatched: "customer number unmatched",
+	DarRechargeCountError:      "recharge count error",
+	DarPurchaseExceeded:        "purchase exceeds hoarding limit",
+	DarAddressAbnormal:         "address abnormal",
+	DarSymmetricDecryptError:   "symmetric decrypt error",
+	DarAsymmetricDecryptError:  "asymmetric decrypt error",
+	DarSignatureError:          "signature error",
+	DarMeterSuspended:          "meter suspended",
+	DarTimeTagInvalid:          "time tag invalid",
+	DarRequestTimeout:          "request timeout",
+	DarESAMP1P2Error:           "esam P1P2 error",
+	DarESAMLCError:             "esam LC error",
+	DarCompareFailed:           "compare failed",
+	DarOther:                   "other",
+}
+
+func (d DARCode) String() string {
+	if name, ok := darNames[d]; ok {
+		return name
+	}
+	return "unknown(" + strconv.Itoa(int(d)) + ")"
+}
+
+func (d DARCode) Error() string {
+	return "dar " + strconv.Itoa(int(d)) + ": " + d.String()
+}
+
+// Err 成功时返回nil，否则返回自身
+func (d DARCode) Err() error {
+	if d == DarSuccess {
+		return nil
+	}
+	return d
+}
+
+// DAR 数据访问结果
+type DAR struct {
+	Data DARCode `json:"value"`
+}
+
+func (D *DAR) DataType() byte {
+	return 0
+}
+
+func (D *DAR) Value() interface{} {
+	return D.Data
+}
+
+// Err 成功时返回nil，否则返回对应的DARCode
+func (D *DAR) Err() error {
+	if D == nil {
+		return nil
+	}
+	return D.Data.Err()
+}
+
+func (D *DAR) decoder(buf *bytes.Reader) error {
+	return binary.Read(buf, binary.LittleEndian, &D.Data)
+}
+
+func (D *DAR) encoder() ([]byte, error) {
+	return []byte{byte(D.Data)}, nil
+}
+
+// oadError 为非成功的DAR附加OAD描述，保留errors.Is比较能力
+func oadError(oad []byte, dar *DAR) error {
+	err := dar.Err()
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("oad %x: %w", oad, err)
+}
+
+// omdError 为非成功的DAR附加OMD描述，保留errors.Is比较能力
+func omdError(omd *OMD, dar *DAR) error {
+	err := dar.Err()
+	if err == nil || omd == nil || omd.Oi == nil {
+		return err
+	}
+	return fmt.Errorf("omd %04x%02x%02x: %w", omd.Oi.Data, omd.FuncMark, omd.Mode, err)
+}
+
+// joinErrors 合并列表中各项的访问结果，全部成功时返回nil
+func joinErrors(errs []error) error {
+	return errors.Join(errs...)
+}
@@ -17,8 +17,8 @@ var _ DataInter = (*UTF8String)(nil)
 
 const (
 	BitStringIdent     byte = 4
-	VisibleStringIdent byte = 10
 	OctetStringIdent   byte = 9
+	VisibleStringIdent byte = 10
 	UTF8StringIdent    byte = 12
 )
 
@@ -42,26 +42,21 @@ type UTF8String struct {
 }
 
 func (U *UTF8String) decoder(buf *bytes.Reader) error {
-	var strLen byte
-	if err := binary.Read(buf, binary.BigEndian, &strLen); err != nil {
+	strLen, err := decodeVarLength(buf)
+	if err != nil {
 		return errors.New("decode data<UTF8String> len err: " + err.Error())
 	}
-	arr := make([]byte, int(strLen))
-	if err := binary.Read(buf, binary.BigEndian, arr); err != nil {
-		U.Data = string(arr)
+	arr := make([]byte, strLen)
+	if err = binary.Read(buf, binary.BigEndian, arr); err != nil {
+		return errors.New("decode data<UTF8String> err: " + err.Error())
 	}
+	U.Data = string(arr)
 	return nil
 }
 
 func (U *UTF8String) encoder() ([]byte, error) {
-	if U.Data == "" {
-		return []byte{0x00}, nil
-	}
-	arr, err := hex.DecodeString(U.Data)
-	if err != nil {
-		return nil, errors.New("decode data<UTF8String> err: " + err.Error())
-	}
-	return append([]byte{byte(len(U.Data))}, arr...), nil
+	arr := []byte(U.Data)
+	return append(encodeVarLength(len(arr)), arr...), nil
 }
 
 func (U *UTF8String) DataType() byte {
@@ -75,23 +70,25 @@ func (U *UTF8String) Value() interface{} {
 /*-----------------------------------------------------------------*/
 
 type BitString struct {
-	Size byte
+	Size int    //位数，超过127位时长度按可变长度编码
 	Data string `json:"data"`
 }
 
 func (b *BitString) decoder(buf *bytes.Reader) error {
-	if err := binary.Read(buf, binary.BigEndian, &b.Size); err != nil {
+	size, err := decodeVarLength(buf)
+	if err != nil {
 		return errors.New("decode data<BitString> length err: " + err.Error())
 	}
-	arr := make([]byte, int(b.Size/8))
-	err := binary.Read(buf, binary.BigEndian, arr)
-	if err != nil {
+	b.Size = size
+	arr := make([]byte, (size+7)/8)
+	if err := binary.Read(buf, binary.BigEndian, arr); err != nil {
 		return errors.New("decode data<BitString> err: " + err.Error())
 	}
+	var sb strings.Builder
 	for _, value := range arr {
-		bs := fmt.Sprintf("%08b", value)
-		b.Data += bs
+		sb.WriteString(fmt.Sprintf("%08b", value))
 	}
+	b.Data = sb.String()[:size]
 	return nil
 }
 
@@ -104,36 +101,33 @@ func (b *BitString) encoder() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	return append([]byte{byte(len(b.Data))}, arr...), nil
+	return append(encodeVarLength(b.Size), arr...), nil
 }
 
+// binaryStringToBytes 位串从第一个字节的最高位开始，最后一个字节不足8位时在低位补0
 func (b *BitString) binaryStringToBytes() ([]byte, error) {
 	var arr []byte
-	length := len(b.Data)
-	if length%8 != 0 {
-		padding := 8 - (length % 8)
-		b.Data = strings.Repeat("0", padding) + b.Data
-	}
-	for i := 0; i < len(b.Data); i += 8 {
-		chunk := b.Data[i : i+8]
-		b, err := strconv.ParseUint(chunk, 2, 8)
+	data := b.Data
+	if length := len(data); length%8 != 0 {
+		data += strings.Repeat("0", 8-length%8)
+	}
+	for i := 0; i < len(data); i += 8 {
+		chunk, err := strconv.ParseUint(data[i:i+8], 2, 8)
 		if err != nil {
 			return nil, err
 		}
-		arr = append(arr, byte(b))
+		arr = append(arr, byte(chunk))
 	}
-
 	return arr, nil
 }
 
 func (b *BitString) adjustStringLength() {
-	if byte(len(b.Data)) > b.Size {
+	if len(b.Data) > b.Size {
 		// 如果字符串比目标长度长，就截断它
 		b.Data = b.Data[:b.Size]
-	} else if byte(len(b.Data)) < b.Size {
+	} else if len(b.Data) < b.Size {
 		// 如果字符串比目标长度短，就在后面添加 "0" 直到达到目标长度
-		padding := b.Size - byte(len(b.Data))
-		b.Data += strings.Repeat("0", int(padding))
+		b.Data += strings.Repeat("0", b.Size-len(b.Data))
 	}
 }
 
@@ -215,8 +209,8 @@ type OctetString struct {
 }
 
 func (o *OctetString) decoder(buf *bytes.Reader) error {
-	var length byte
-	if err := binary.Read(buf, binary.LittleEndian, &length); err != nil {
+	length, err := decodeVarLength(buf)
+	if err != nil {
 		return errors.New("decode OctetString's length err: " + err.Error())
 	}
 	if length == 0 {
@@ -241,7 +235,7 @@ func (o *OctetString) encoder() ([]byte, error) {
 	if err != nil {
 		return nil, errors.New("encode OctetString's array err: " + err.Error())
 	}
-	return append([]byte{byte(len(array))}, array...), nil
+	return append(encodeVarLength(len(array)), array...), nil
 }
 
 func (o *OctetString) DataType() byte {
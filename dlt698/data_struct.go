@@ -5,7 +5,6 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
-	"fmt"
 	"strconv"
 )
 
@@ -110,7 +109,7 @@ func (O *OI) decoder(buf *bytes.Reader) error {
 
 func (O *OI) encoder() ([]byte, error) {
 	buf := new(bytes.Buffer)
-	err := binary.Write(buf, binary.BigEndian, O.Value)
+	err := binary.Write(buf, binary.BigEndian, O.Data)
 	return buf.Bytes(), err
 }
 
@@ -154,7 +153,7 @@ type ROAD struct {
 
 func (R *ROAD) decoder(buf *bytes.Reader) error {
 	R.Oad = &OAD{}
-	err := binary.Read(buf, binary.LittleEndian, &R.Oad)
+	err := R.Oad.decoder(buf)
 	if err != nil {
 		return errors.New("decode ROAD err: " + err.Error())
 	}
@@ -241,59 +240,50 @@ func (O *OMD) Value() interface{} {
 
 /*----------------------------------*/
 
+// TSA 目标服务器地址，编码为octet-string，首字节为地址特征，其后为服务器地址
 type TSA struct {
 	AddressType   int    `json:"address_type"`   //地址类型，0-单地址， 1-统配地址， 2-组地址， 3-广播地址
 	LogicAddress  int    `json:"logic_address"`  //逻辑地址
-	AddressLength int    `json:"address_length"` //地址长度 从0开始要加1
+	AddressLength int    `json:"address_length"` //地址长度，编码时按Address计算
 	Address       string `json:"address"`        //地址
-	CA            byte   `json:"ca"`             //客户机地址
 }
 
 func (T *TSA) decoder(buf *bytes.Reader) error {
-	at, err := buf.ReadByte()
+	length, err := decodeVarLength(buf)
 	if err != nil {
-		return err
+		return errors.New("decode TSA err:" + err.Error())
 	}
-	T.AddressType = int((at >> 6) & 0b11)
-	if T.AddressType != SingleAddress && T.AddressType != DistributionAddress && T.AddressType != GroupAddress && T.AddressType != BroadcastAddress {
-		return errors.New("TSA address type error：" + strconv.Itoa(T.AddressType))
+	if length < 2 {
+		return errors.New("decode TSA err: length must be >= 2")
 	}
-	//获取逻辑地址
-	T.LogicAddress = int((at >> 4) & 0b11)
-	T.AddressLength = int((at>>0)&0b1111) + 1
-	addressArray := make([]byte, T.AddressLength)
-	_, err = buf.Read(addressArray)
+	at, err := buf.ReadByte()
 	if err != nil {
 		return errors.New("decode TSA err:" + err.Error())
 	}
-	for i, j := 0, len(addressArray)-1; i < j; i, j = i+1, j-1 {
-		addressArray[i], addressArray[j] = addressArray[j], addressArray[i]
+	T.AddressType = int((at >> 6) & 0b11)
+	T.LogicAddress = int((at >> 4) & 0b11)
+	T.AddressLength = int(at&0b1111) + 1
+	if T.AddressLength != length-1 {
+		return errors.New("decode TSA err: address length " + strconv.Itoa(T.AddressLength) + " != " + strconv.Itoa(length-1))
 	}
-	T.Address = hex.EncodeToString(addressArray)
-	T.CA, err = buf.ReadByte()
-	if err != nil {
+	addressArray := make([]byte, T.AddressLength)
+	if err = binary.Read(buf, binary.BigEndian, addressArray); err != nil {
 		return errors.New("decode TSA err:" + err.Error())
 	}
+	T.Address = hex.EncodeToString(addressArray)
 	return nil
 }
 
 func (T *TSA) encoder() ([]byte, error) {
-	addressTypeStr := fmt.Sprintf("%d%d", (T.AddressType>>1)&1, T.AddressType&1)
-	logicAddressStr := fmt.Sprintf("%d%d", (T.LogicAddress>>1)&1, T.LogicAddress&1)
-	length := len(T.Address)/2 - 1
-	addressLengthStr := strconv.FormatInt(int64(length&0b1111), 2)
-	adType, err := strconv.ParseUint(addressTypeStr+logicAddressStr+addressLengthStr, 2, 8)
-	if err != nil {
-		return nil, errors.New("encode TSA err:" + err.Error())
-	}
 	addressArray, err := hex.DecodeString(T.Address)
 	if err != nil {
 		return nil, errors.New("encode TSA err:" + err.Error())
 	}
-	for i, j := 0, len(addressArray)-1; i < j; i, j = i+1, j-1 {
-		addressArray[i], addressArray[j] = addressArray[j], addressArray[i]
+	if len(addressArray) == 0 || len(addressArray) > 16 {
+		return nil, errors.New("encode TSA err: address length must be 1-16 bytes")
 	}
-	return append(append([]byte{byte(adType)}, addressArray...), []byte{T.CA}...), nil
+	at := byte(T.AddressType&0b11)<<6 | byte(T.LogicAddress&0b11)<<4 | byte(len(addressArray)-1)
+	return append([]byte{byte(len(addressArray) + 1), at}, addressArray...), nil
 }
 
 func (T *TSA) DataType() byte {
@@ -334,6 +324,7 @@ func (R *RN) Value() interface{} {
 
 /*---------------------------*/
 
+// Region 区间，Unit 0-前闭后开 1-前开后闭 2-前闭后闭 3-前开后开
 type Region struct {
 	Unit  byte      `json:"unit"`
 	Start DataInter `json:"start"`
@@ -351,6 +342,9 @@ func (r *Region) decoder(buf *bytes.Reader) error {
 		return errors.New("decode Region err:" + err.Error())
 	}
 	r.Start = dataTranslate(dataType)
+	if r.Start == nil {
+		return errors.New("decode Region err: start data type not found")
+	}
 	err = r.Start.decoder(buf)
 	if err != nil {
 		return errors.New("decode Region err:" + err.Error())
@@ -360,6 +354,9 @@ func (r *Region) decoder(buf *bytes.Reader) error {
 		return errors.New("decode Region err:" + err.Error())
 	}
 	r.End = dataTranslate(dataType)
+	if r.End == nil {
+		return errors.New("decode Region err: end data type not found")
+	}
 	err = r.End.decoder(buf)
 	if err != nil {
 		return errors.New("decode Region err:" + err.Error())
@@ -435,6 +432,35 @@ type Selector interface {
 	GetDataIdent() byte
 }
 
+// newSelector 根据选择方法序号创建Selector
+func newSelector(ident byte) Selector {
+	switch ident {
+	case 0:
+		return &Selector0{}
+	case 1:
+		return &Selector1{}
+	case 2:
+		return &Selector2{}
+	case 3:
+		return &Selector3{}
+	case 4:
+		return &Selector4{}
+	case 5:
+		return &Selector5{}
+	case 6:
+		return &Selector6{}
+	case 7:
+		return &Selector7{}
+	case 8:
+		return &Selector8{}
+	case 9:
+		return &Selector9{}
+	case 10:
+		return &Selector10{}
+	}
+	return nil
+}
+
 var _ Selector = (*Selector0)(nil)
 var _ Selector = (*Selector1)(nil)
 var _ Selector = (*Selector2)(nil)
@@ -456,30 +482,7 @@ func (R *RSD) decoder(buf *bytes.Reader) error {
 	if err != nil {
 		return err
 	}
-	switch rsdType {
-	case 0:
-		R.Selector = &Selector0{}
-	case 1:
-		R.Selector = &Selector1{}
-	case 2:
-		R.Selector = &Selector2{}
-	case 3:
-		R.Selector = &Selector3{}
-	case 4:
-		R.Selector = &Selector4{}
-	case 5:
-		R.Selector = &Selector5{}
-	case 6:
-		R.Selector = &Selector6{}
-	case 7:
-		R.Selector = &Selector7{}
-	case 8:
-		R.Selector = &Selector8{}
-	case 9:
-		R.Selector = &Selector9{}
-	case 10:
-		R.Selector = &Selector10{}
-	}
+	R.Selector = newSelector(rsdType)
 	if R.Selector == nil {
 		return errors.New("RSD Selector must be not null！")
 	}
@@ -510,7 +513,7 @@ func (s *Selector0) Decode(buf *bytes.Reader) error {
 }
 
 func (s *Selector0) Encode() ([]byte, error) {
-	return []byte{0x00}, nil
+	return nil, nil
 }
 
 func (s *Selector0) GetDataIdent() byte {
@@ -535,6 +538,9 @@ func (s *Selector1) Decode(buf *bytes.Reader) error {
 		return errors.New("decode Selector1 err:" + err.Error())
 	}
 	s.Data = dataTranslate(dataFlg)
+	if s.Data == nil {
+		return errors.New("decode Selector1 err: data type not found")
+	}
 	err = s.Data.decoder(buf)
 	if err != nil {
 		return errors.New("decode Selector1 err:" + err.Error())
@@ -699,85 +705,150 @@ func (s *Selector4) GetDataIdent() byte {
 	return 4
 }
 
+// Selector5 指定表计集合、指定采集存储时间
 type Selector5 struct {
-	Selector4
+	StoreTime *DateTimes `json:"store_time"` //采集存储时间
+	MS        *MS        `json:"ms"`         //表计集合
+}
+
+func (s *Selector5) Decode(buf *bytes.Reader) error {
+	s.StoreTime = &DateTimes{}
+	if err := s.StoreTime.decoder(buf); err != nil {
+		return err
+	}
+	s.MS = &MS{}
+	return s.MS.decoder(buf)
+}
+
+func (s *Selector5) Encode() ([]byte, error) {
+	storeTimeArray, err := s.StoreTime.encoder()
+	if err != nil {
+		return nil, err
+	}
+	msArray, err := s.MS.encoder()
+	if err != nil {
+		return nil, err
+	}
+	return append(storeTimeArray, msArray...), nil
 }
 
 func (s *Selector5) GetDataIdent() byte {
 	return 5
 }
 
+// Selector6 指定表计集合、指定采集启动时间区间内连续间隔值
 type Selector6 struct {
-	StartTime *DateTimes `json:"start_time"`
-	EndTime   *DateTimes `json:"end_time"`
-	Ti        *TI        `json:"ti"`
-	Ms        *MS        `json:"ms"`
+	StartTime *DateTimes `json:"start_time"` //采集启动时间起始值
+	EndTime   *DateTimes `json:"end_time"`   //采集启动时间结束值
+	Ti        *TI        `json:"ti"`         //时间间隔
+	Ms        *MS        `json:"ms"`         //表计集合
 }
 
 func (s *Selector6) Decode(buf *bytes.Reader) error {
-	s.StartTime = &DateTimes{}
-	if err := s.StartTime.decoder(buf); err != nil {
-		return err
-	}
-	s.EndTime = &DateTimes{}
-	if err := s.EndTime.decoder(buf); err != nil {
-		return err
-	}
-	s.Ti = &TI{}
-	if err := s.Ti.decoder(buf); err != nil {
-		return err
-	}
-	s.Ms = &MS{}
-	if err := s.Ms.decoder(buf); err != nil {
-		return err
-	}
-	return nil
+	var err error
+	s.StartTime, s.EndTime, s.Ti, s.Ms, err = decodeTimeRange(buf)
+	return err
 }
 
 func (s *Selector6) Encode() ([]byte, error) {
-	var err error
-	startTimeArray, err := s.StartTime.encoder()
-	if err != nil {
-		return nil, err
-	}
-	endTimeArray, err := s.EndTime.encoder()
-	if err != nil {
-		return nil, err
-	}
-	startTimeArray = append(startTimeArray, endTimeArray...)
-	tsArray, err := s.Ti.encoder()
-	if err != nil {
-		return nil, err
-	}
-	startTimeArray = append(startTimeArray, tsArray...)
-	msArray, err := s.Ms.encoder()
-	if err != nil {
-		return nil, err
-	}
-	startTimeArray = append(startTimeArray, msArray...)
-	return startTimeArray, nil
+	return encodeTimeRange(s.StartTime, s.EndTime, s.Ti, s.Ms)
 }
 
 func (s *Selector6) GetDataIdent() byte {
 	return 6
 }
 
+// Selector7 指定表计集合、指定采集存储时间区间内连续间隔值
 type Selector7 struct {
-	Selector6
+	StartTime *DateTimes `json:"start_time"` //采集存储时间起始值
+	EndTime   *DateTimes `json:"end_time"`   //采集存储时间结束值
+	Ti        *TI        `json:"ti"`         //时间间隔
+	Ms        *MS        `json:"ms"`         //表计集合
+}
+
+func (s *Selector7) Decode(buf *bytes.Reader) error {
+	var err error
+	s.StartTime, s.EndTime, s.Ti, s.Ms, err = decodeTimeRange(buf)
+	return err
+}
+
+func (s *Selector7) Encode() ([]byte, error) {
+	return encodeTimeRange(s.StartTime, s.EndTime, s.Ti, s.Ms)
 }
 
 func (s *Selector7) GetDataIdent() byte {
 	return 7
 }
 
+// Selector8 指定表计集合、指定采集成功时间区间内连续间隔值
 type Selector8 struct {
-	Selector6
+	StartTime *DateTimes `json:"start_time"` //采集成功时间起始值
+	EndTime   *DateTimes `json:"end_time"`   //采集成功时间结束值
+	Ti        *TI        `json:"ti"`         //时间间隔
+	Ms        *MS        `json:"ms"`         //表计集合
+}
+
+func (s *Selector8) Decode(buf *bytes.Reader) error {
+	var err error
+	s.StartTime, s.EndTime, s.Ti, s.Ms, err = decodeTimeRange(buf)
+	return err
+}
+
+func (s *Selector8) Encode() ([]byte, error) {
+	return encodeTimeRange(s.StartTime, s.EndTime, s.Ti, s.Ms)
 }
 
 func (s *Selector8) GetDataIdent() byte {
 	return 8
 }
 
+// decodeTimeRange 解析Selector6/7/8共用的 起始时间、结束时间、时间间隔、表计集合
+func decodeTimeRange(buf *bytes.Reader) (*DateTimes, *DateTimes, *TI, *MS, error) {
+	startTime := &DateTimes{}
+	if err := startTime.decoder(buf); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	endTime := &DateTimes{}
+	if err := endTime.decoder(buf); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	ti := &TI{}
+	if err := ti.decoder(buf); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	ms := &MS{}
+	if err := ms.decoder(buf); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return startTime, endTime, ti, ms, nil
+}
+
+// encodeTimeRange 编码Selector6/7/8共用的 起始时间、结束时间、时间间隔、表计集合
+func encodeTimeRange(startTime *DateTimes, endTime *DateTimes, ti *TI, ms *MS) ([]byte, error) {
+	if startTime == nil || endTime == nil || ti == nil || ms == nil {
+		return nil, errors.New("selector time range must not be nil")
+	}
+	encodeArray, err := startTime.encoder()
+	if err != nil {
+		return nil, err
+	}
+	endTimeArray, err := endTime.encoder()
+	if err != nil {
+		return nil, err
+	}
+	encodeArray = append(encodeArray, endTimeArray...)
+	tiArray, err := ti.encoder()
+	if err != nil {
+		return nil, err
+	}
+	encodeArray = append(encodeArray, tiArray...)
+	msArray, err := ms.encoder()
+	if err != nil {
+		return nil, err
+	}
+	return append(encodeArray, msArray...), nil
+}
+
 type Selector9 struct {
 	Last uint8 `json:"last_n"`
 }
@@ -807,6 +878,7 @@ func (s *Selector10) Decode(buf *bytes.Reader) error {
 	if err != nil {
 		return err
 	}
+	s.Ms = &MS{}
 	err = s.Ms.decoder(buf)
 	return err
 }
@@ -863,6 +935,29 @@ type MSer interface {
 	GetDataIdent() byte
 }
 
+// newMS 根据表计集合类型创建MSer
+func newMS(ident byte) MSer {
+	switch ident {
+	case 0:
+		return &MS0{}
+	case 1:
+		return &MS1{}
+	case 2:
+		return &MS2{}
+	case 3:
+		return &MS3{}
+	case 4:
+		return &MS4{}
+	case 5:
+		return &MS5{}
+	case 6:
+		return &MS6{}
+	case 7:
+		return &MS7{}
+	}
+	return nil
+}
+
 var _ MSer = (*MS0)(nil)
 var _ MSer = (*MS1)(nil)
 var _ MSer = (*MS2)(nil)
@@ -882,24 +977,7 @@ func (M *MS) decoder(buf *bytes.Reader) error {
 	if err != nil {
 		return errors.New("decode MS err : " + err.Error())
 	}
-	switch M.MSType {
-	case 0:
-		M.Ms = &MS0{}
-	case 1:
-		M.Ms = &MS1{}
-	case 2:
-		M.Ms = &MS2{}
-	case 3:
-		M.Ms = &MS3{}
-	case 4:
-		M.Ms = &MS4{}
-	case 5:
-		M.Ms = &MS5{}
-	case 6:
-		M.Ms = &MS6{}
-	case 7:
-		M.Ms = &MS7{}
-	}
+	M.Ms = newMS(M.MSType)
 	if M.Ms == nil {
 		return errors.New("MS type err！")
 	}
@@ -926,21 +1004,23 @@ func (M *MS) Value() interface{} {
 	return M
 }
 
+// MS0 无电能表
 type MS0 struct {
 }
 
-func (M MS0) Decode(buf *bytes.Reader) error {
+func (M *MS0) Decode(buf *bytes.Reader) error {
 	return nil
 }
 
-func (M MS0) Encode() ([]byte, error) {
-	return []byte{0x00}, nil
+func (M *MS0) Encode() ([]byte, error) {
+	return nil, nil
 }
 
-func (M MS0) GetDataIdent() byte {
+func (M *MS0) GetDataIdent() byte {
 	return 0
 }
 
+// MS1 全部用户地址
 type MS1 struct {
 }
 
@@ -949,13 +1029,14 @@ func (M *MS1) Decode(buf *bytes.Reader) error {
 }
 
 func (M *MS1) Encode() ([]byte, error) {
-	return []byte{0x01}, nil
+	return nil, nil
 }
 
 func (M *MS1) GetDataIdent() byte {
 	return 1
 }
 
+// MS2 一组用户类型
 type MS2 struct {
 	Meters []uint8 `json:"meters"`
 }
@@ -971,13 +1052,14 @@ func (M *MS2) Decode(buf *bytes.Reader) error {
 }
 
 func (M *MS2) Encode() ([]byte, error) {
-	return append([]byte{0x02}, M.Meters...), nil
+	return append([]byte{byte(len(M.Meters))}, M.Meters...), nil
 }
 
 func (M *MS2) GetDataIdent() byte {
 	return 2
 }
 
+// MS3 一组用户地址
 type MS3 struct {
 	TSAs []*TSA `json:"tsas"`
 }
@@ -1018,6 +1100,7 @@ func (M *MS3) GetDataIdent() byte {
 	return 3
 }
 
+// MS4 一组配置序号
 type MS4 struct {
 	Meters []uint16 `json:"meters"`
 }
@@ -1051,61 +1134,90 @@ func (M *MS4) GetDataIdent() byte {
 	return 4
 }
 
+// MS5 一组用户类型区间
 type MS5 struct {
 	Regions []*Region `json:"regions"`
 }
 
 func (M *MS5) Decode(buf *bytes.Reader) error {
-	regionLength, err := buf.ReadByte()
-	if err != nil {
-		return err
-	}
-	M.Regions = make([]*Region, regionLength)
-	for i := 0; i < int(regionLength); i++ {
-		M.Regions[i] = &Region{}
-		err = M.Regions[i].decoder(buf)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	var err error
+	M.Regions, err = decodeRegions(buf)
+	return err
 }
 
 func (M *MS5) Encode() ([]byte, error) {
-	if len(M.Regions) == 0 {
-		return []byte{0x00}, nil
-	}
-	encodeArray := []byte{byte(len(M.Regions))}
-	for _, region := range M.Regions {
-		regionArray, err := region.encoder()
-		if err != nil {
-			return nil, err
-		}
-		encodeArray = append(encodeArray, regionArray...)
-	}
-	return encodeArray, nil
+	return encodeRegions(M.Regions)
 }
 
 func (M *MS5) GetDataIdent() byte {
 	return 5
 }
 
+// MS6 一组用户地址区间，区间的起始值和结束值为TSA
 type MS6 struct {
-	MS5
+	Regions []*Region `json:"regions"`
+}
+
+func (M *MS6) Decode(buf *bytes.Reader) error {
+	var err error
+	M.Regions, err = decodeRegions(buf)
+	return err
+}
+
+func (M *MS6) Encode() ([]byte, error) {
+	return encodeRegions(M.Regions)
 }
 
 func (M *MS6) GetDataIdent() byte {
 	return 6
 }
 
+// MS7 一组配置序号区间，区间的起始值和结束值为long-unsigned
 type MS7 struct {
-	MS5
+	Regions []*Region `json:"regions"`
+}
+
+func (M *MS7) Decode(buf *bytes.Reader) error {
+	var err error
+	M.Regions, err = decodeRegions(buf)
+	return err
+}
+
+func (M *MS7) Encode() ([]byte, error) {
+	return encodeRegions(M.Regions)
 }
 
 func (M *MS7) GetDataIdent() byte {
 	return 7
 }
 
+func decodeRegions(buf *bytes.Reader) ([]*Region, error) {
+	regionLength, err := buf.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	regions := make([]*Region, regionLength)
+	for i := 0; i < int(regionLength); i++ {
+		regions[i] = &Region{}
+		if err = regions[i].decoder(buf); err != nil {
+			return nil, err
+		}
+	}
+	return regions, nil
+}
+
+func encodeRegions(regions []*Region) ([]byte, error) {
+	encodeArray := []byte{byte(len(regions))}
+	for _, region := range regions {
+		regionArray, err := region.encoder()
+		if err != nil {
+			return nil, err
+		}
+		encodeArray = append(encodeArray, regionArray...)
+	}
+	return encodeArray, nil
+}
+
 /*---------------*/
 
 type SID struct {
@@ -1141,7 +1253,7 @@ func (S *SID) encoder() ([]byte, error) {
 }
 
 func (S *SID) DataType() byte {
-	return RSDIdent
+	return SIDIdent
 }
 
 func (S *SID) Value() interface{} {
@@ -1197,7 +1309,7 @@ type COMDCB struct {
 }
 
 func (C *COMDCB) decoder(buf *bytes.Reader) error {
-	return binary.Read(buf, binary.BigEndian, &C)
+	return binary.Read(buf, binary.BigEndian, C)
 }
 
 func (C *COMDCB) encoder() ([]byte, error) {
@@ -0,0 +1,178 @@
+package dlt698
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// 测试用的报文片段
+const (
+	testStart    = "07e40101000000"
+	testEnd      = "07e40102000000"
+	testTI       = "01 000f"
+	testSelector = "20210200 1c" + testStart + "1c" + testEnd + "54" + testTI
+)
+
+func testHex(t *testing.T, s string) []byte {
+	t.Helper()
+	data, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func testStartTime() *DateTimes {
+	return &DateTimes{Year: 2020, Month: 1, Day: 1}
+}
+
+func testEndTime() *DateTimes {
+	return &DateTimes{Year: 2020, Month: 1, Day: 2}
+}
+
+func testSelector2() *Selector2 {
+	return &Selector2{
+		Oad:       []byte{0x20, 0x21, 0x02, 0x00},
+		StartData: testStartTime(),
+		EndData:   testEndTime(),
+		Interval:  &TI{TimeUnit: 1, Interval: 15},
+	}
+}
+
+var msTests = []struct {
+	name string
+	data string
+	ms   *MS
+}{
+	{"MS0", "00", &MS{MSType: 0, Ms: &MS0{}}},
+	{"MS1", "01", &MS{MSType: 1, Ms: &MS1{}}},
+	{"MS2", "02 03 01 02 03", &MS{MSType: 2, Ms: &MS2{Meters: []uint8{1, 2, 3}}}},
+	{"MS3", "03 02 07 05 010203040506 05 93 00112233", &MS{MSType: 3, Ms: &MS3{TSAs: []*TSA{
+		{AddressType: 0, LogicAddress: 0, AddressLength: 6, Address: "010203040506"},
+		{AddressType: 2, LogicAddress: 1, AddressLength: 4, Address: "00112233"},
+	}}}},
+	{"MS4", "04 02 0001 1234", &MS{MSType: 4, Ms: &MS4{Meters: []uint16{1, 0x1234}}}},
+	{"MS5", "05 02 00 11 01 11 0a 02 11 10 11 20", &MS{MSType: 5, Ms: &MS5{Regions: []*Region{
+		{Unit: 0, Start: &Unsigned{Data: 1}, End: &Unsigned{Data: 10}},
+		{Unit: 2, Start: &Unsigned{Data: 16}, End: &Unsigned{Data: 32}},
+	}}}},
+	{"MS6", "06 01 00 55 07 05 000000000001 55 07 15 000000000099", &MS{MSType: 6, Ms: &MS6{Regions: []*Region{
+		{
+			Unit:  0,
+			Start: &TSA{AddressType: 0, LogicAddress: 0, AddressLength: 6, Address: "000000000001"},
+			End:   &TSA{AddressType: 0, LogicAddress: 1, AddressLength: 6, Address: "000000000099"},
+		},
+	}}}},
+	{"MS7", "07 02 01 12 0001 12 0010 03 12 0100 12 ffff", &MS{MSType: 7, Ms: &MS7{Regions: []*Region{
+		{Unit: 1, Start: &LongUnsigned{Data: 1}, End: &LongUnsigned{Data: 16}},
+		{Unit: 3, Start: &LongUnsigned{Data: 0x0100}, End: &LongUnsigned{Data: 0xffff}},
+	}}}},
+}
+
+var rsdTests = []struct {
+	name string
+	data string
+	rsd  *RSD
+}{
+	{"Selector0", "00", &RSD{Selector: &Selector0{}}},
+	{"Selector1", "01 40010200 09 06 010203040506", &RSD{Selector: &Selector1{
+		Oad:  []byte{0x40, 0x01, 0x02, 0x00},
+		Data: &OctetString{Data: "010203040506"},
+	}}},
+	{"Selector2", "02" + testSelector, &RSD{Selector: testSelector2()}},
+	{"Selector3", "03 02" + testSelector + testSelector, &RSD{Selector: &Selector3{
+		Selectors: []*Selector2{testSelector2(), testSelector2()},
+	}}},
+	{"Selector4", "04" + testStart + "01", &RSD{Selector: &Selector4{
+		StartTime: testStartTime(),
+		MS:        &MS{MSType: 1, Ms: &MS1{}},
+	}}},
+	{"Selector5", "05" + testStart + "02 02 01 02", &RSD{Selector: &Selector5{
+		StoreTime: testStartTime(),
+		MS:        &MS{MSType: 2, Ms: &MS2{Meters: []uint8{1, 2}}},
+	}}},
+	{"Selector6", "06" + testStart + testEnd + testTI + "03 01 07 05 010203040506", &RSD{Selector: &Selector6{
+		StartTime: testStartTime(),
+		EndTime:   testEndTime(),
+		Ti:        &TI{TimeUnit: 1, Interval: 15},
+		Ms: &MS{MSType: 3, Ms: &MS3{TSAs: []*TSA{
+			{AddressType: 0, LogicAddress: 0, AddressLength: 6, Address: "010203040506"},
+		}}},
+	}}},
+	{"Selector7", "07" + testStart + testEnd + testTI + "04 01 0002", &RSD{Selector: &Selector7{
+		StartTime: testStartTime(),
+		EndTime:   testEndTime(),
+		Ti:        &TI{TimeUnit: 1, Interval: 15},
+		Ms:        &MS{MSType: 4, Ms: &MS4{Meters: []uint16{2}}},
+	}}},
+	{"Selector8", "08" + testStart + testEnd + testTI + "05 01 00 11 01 11 0a", &RSD{Selector: &Selector8{
+		StartTime: testStartTime(),
+		EndTime:   testEndTime(),
+		Ti:        &TI{TimeUnit: 1, Interval: 15},
+		Ms: &MS{MSType: 5, Ms: &MS5{Regions: []*Region{
+			{Unit: 0, Start: &Unsigned{Data: 1}, End: &Unsigned{Data: 10}},
+		}}},
+	}}},
+	{"Selector9", "09 01", &RSD{Selector: &Selector9{Last: 1}}},
+	{"Selector10", "0a 02 07 01 02 12 0001 12 0010", &RSD{Selector: &Selector10{
+		Last: 2,
+		Ms: &MS{MSType: 7, Ms: &MS7{Regions: []*Region{
+			{Unit: 2, Start: &LongUnsigned{Data: 1}, End: &LongUnsigned{Data: 16}},
+		}}},
+	}}},
+}
+
+func TestMS(t *testing.T) {
+	for _, tt := range msTests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := testHex(t, tt.data)
+			ms := &MS{}
+			buf := bytes.NewReader(data)
+			if err := ms.decoder(buf); err != nil {
+				t.Fatal(err)
+			}
+			if buf.Len() != 0 {
+				t.Fatalf("%d bytes left", buf.Len())
+			}
+			if !reflect.DeepEqual(ms, tt.ms) {
+				t.Fatalf("decode = %+v, want %+v", ms.Ms, tt.ms.Ms)
+			}
+			encoded, err := tt.ms.encoder()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(encoded, data) {
+				t.Fatalf("encode = %x, want %x", encoded, data)
+			}
+		})
+	}
+}
+
+func TestRSD(t *testing.T) {
+	for _, tt := range rsdTests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := testHex(t, tt.data)
+			rsd := &RSD{}
+			buf := bytes.NewReader(data)
+			if err := rsd.decoder(buf); err != nil {
+				t.Fatal(err)
+			}
+			if buf.Len() != 0 {
+				t.Fatalf("%d bytes left", buf.Len())
+			}
+			if !reflect.DeepEqual(rsd, tt.rsd) {
+				t.Fatalf("decode = %+v, want %+v", rsd.Selector, tt.rsd.Selector)
+			}
+			encoded, err := tt.rsd.encoder()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(encoded, data) {
+				t.Fatalf("encode = %x, want %x", encoded, data)
+			}
+		})
+	}
+}
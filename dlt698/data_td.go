@@ -124,22 +124,29 @@ type DateTime struct {
 }
 
 func (d *DateTime) Build() *DateTime {
-	now := time.Now()
-	year, month, day := now.Date()
-	hour, minute, second := now.Clock()
-	millisecond := now.Nanosecond() / 1e6
-	dayOfWeek := now.Weekday()
+	return d.BuildByTime(time.Now())
+}
+
+// BuildByTime 根据指定时间生成
+func (d *DateTime) BuildByTime(t time.Time) *DateTime {
+	year, month, day := t.Date()
+	hour, minute, second := t.Clock()
 	d.Year = uint16(year)
 	d.Month = uint8(month)
 	d.DayOfMonth = uint8(day)
-	d.DayOfWeek = uint8(dayOfWeek)
+	d.DayOfWeek = uint8(t.Weekday())
 	d.Hour = uint8(hour)
 	d.Minute = uint8(minute)
 	d.Second = uint8(second)
-	d.Millisecond = uint16(millisecond)
+	d.Millisecond = uint16(t.Nanosecond() / 1e6)
 	return d
 }
 
+// Time 转换为本地时间
+func (d *DateTime) Time() time.Time {
+	return time.Date(int(d.Year), time.Month(d.Month), int(d.DayOfMonth), int(d.Hour), int(d.Minute), int(d.Second), int(d.Millisecond)*1e6, time.Local)
+}
+
 func (d *DateTime) decoder(buf *bytes.Reader) error {
 	err := binary.Read(buf, binary.BigEndian, d)
 	if err != nil {
@@ -177,9 +184,13 @@ type DateTimes struct {
 }
 
 func (d *DateTimes) Build() *DateTimes {
-	now := time.Now()
-	year, month, day := now.Date()
-	hour, minute, second := now.Clock()
+	return d.BuildByTime(time.Now())
+}
+
+// BuildByTime 根据指定时间生成
+func (d *DateTimes) BuildByTime(t time.Time) *DateTimes {
+	year, month, day := t.Date()
+	hour, minute, second := t.Clock()
 	d.Year = uint16(year)
 	d.Month = uint8(month)
 	d.Day = uint8(day)
@@ -206,6 +217,11 @@ func (d *DateTimes) encoder() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// Time 转换为本地时间
+func (d *DateTimes) Time() time.Time {
+	return time.Date(int(d.Year), time.Month(d.Month), int(d.Day), int(d.Hour), int(d.Minute), int(d.Second), 0, time.Local)
+}
+
 func (d *DateTimes) DataType() byte {
 	return DateTimesIdent
 }
@@ -235,6 +251,25 @@ func (T *TI) decoder(buf *bytes.Reader) error {
 	return nil
 }
 
+// AddTo 返回t加上间隔时间后的时间，时间单位 0-秒 1-分 2-时 3-日 4-月 5-年
+func (T *TI) AddTo(t time.Time) time.Time {
+	n := int(T.Interval)
+	switch T.TimeUnit {
+	case 0:
+		return t.Add(time.Duration(n) * time.Second)
+	case 1:
+		return t.Add(time.Duration(n) * time.Minute)
+	case 2:
+		return t.Add(time.Duration(n) * time.Hour)
+	case 3:
+		return t.AddDate(0, 0, n)
+	case 4:
+		return t.AddDate(0, n, 0)
+	default:
+		return t.AddDate(n, 0, 0)
+	}
+}
+
 func (T *TI) encoder() ([]byte, error) {
 	if T.TimeUnit > 5 {
 		return nil, errors.New("TI's TimeUnit must be < 5")
@@ -0,0 +1,305 @@
+package dict
+
+import "dlt698"
+
+// Class 接口类
+type Class byte
+
+const (
+	IC1  Class = 1  //电能量
+	IC2  Class = 2  //最大需量
+	IC3  Class = 3  //分相变量
+	IC4  Class = 4  //功率
+	IC5  Class = 5  //谐波变量
+	IC6  Class = 6  //数据变量
+	IC7  Class = 7  //事件对象
+	IC8  Class = 8  //参数变量
+	IC9  Class = 9  //冻结数据
+	IC10 Class = 10 //采集监控
+	IC11 Class = 11 //集合
+	IC12 Class = 12 //脉冲计量
+	IC13 Class = 13 //负荷控制
+	IC14 Class = 14 //区间统计
+	IC15 Class = 15 //累加平均
+	IC16 Class = 16 //极值工具
+	IC17 Class = 17 //显示
+	IC18 Class = 18 //文件传输
+	IC19 Class = 19 //设备管理
+	IC20 Class = 20 //应用连接
+	IC21 Class = 21 //ESAM
+	IC22 Class = 22 //输入输出设备
+	IC23 Class = 23 //总加组
+	IC24 Class = 24 //分项事件
+	IC25 Class = 25 //无线公网通信
+	IC26 Class = 26 //以太网通信
+)
+
+var classNames = map[Class]string{
+	IC1:  "电能量",
+	IC2:  "最大需量",
+	IC3:  "分相变量",
+	IC4:  "功率",
+	IC5:  "谐波变量",
+	IC6:  "数据变量",
+	IC7:  "事件对象",
+	IC8:  "参数变量",
+	IC9:  "冻结数据",
+	IC10: "采集监控",
+	IC11: "集合",
+	IC12: "脉冲计量",
+	IC13: "负荷控制",
+	IC14: "区间统计",
+	IC15: "累加平均",
+	IC16: "极值工具",
+	IC17: "显示",
+	IC18: "文件传输",
+	IC19: "设备管理",
+	IC20: "应用连接",
+	IC21: "ESAM",
+	IC22: "输入输出设备",
+	IC23: "总加组",
+	IC24: "分项事件",
+	IC25: "无线公网通信",
+	IC26: "以太网通信",
+}
+
+func (c Class) String() string {
+	if name, ok := classNames[c]; ok {
+		return name
+	}
+	return "未知接口类"
+}
+
+// Attribute 对象属性
+type Attribute struct {
+	Index        byte   //属性序号
+	Name         string //属性名称
+	DataType     byte   //数据类型标签
+	ElementType  byte   //数组元素的数据类型标签，DataType为array时有效
+	Scaled       bool   //是否使用对象的换算及单位
+	scalerAdjust int8   //在对象换算基础上的附加换算，如高精度电能量
+}
+
+// valueType 数值属性的占位类型，实际数据类型由对象决定
+const valueType byte = 0xFF
+
+// classAttributes 各接口类的属性定义，数据类型为valueType的属性使用对象的数值类型
+var classAttributes = map[Class][]Attribute{
+	IC1: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "总及费率电能量数组", DataType: dlt698.ArrayIdent, ElementType: valueType, Scaled: true},
+		{Index: 3, Name: "换算及单位", DataType: dlt698.ScalerUnitIdent},
+		{Index: 4, Name: "高精度总及费率电能量数组", DataType: dlt698.ArrayIdent, ElementType: dlt698.Long64UnsignedIdent, Scaled: true, scalerAdjust: -2},
+		{Index: 5, Name: "高精度换算及单位", DataType: dlt698.ScalerUnitIdent},
+	},
+	IC2: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "总及费率最大需量数组", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent, Scaled: true},
+		{Index: 3, Name: "换算及单位", DataType: dlt698.ScalerUnitIdent},
+	},
+	IC3: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "分相数值组", DataType: dlt698.ArrayIdent, ElementType: valueType, Scaled: true},
+		{Index: 3, Name: "换算及单位", DataType: dlt698.ScalerUnitIdent},
+	},
+	IC4: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "总及分相数值组", DataType: dlt698.ArrayIdent, ElementType: valueType, Scaled: true},
+		{Index: 3, Name: "换算及单位", DataType: dlt698.ScalerUnitIdent},
+	},
+	IC5: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "A相数值", DataType: dlt698.ArrayIdent, ElementType: valueType, Scaled: true},
+		{Index: 3, Name: "B相数值", DataType: dlt698.ArrayIdent, ElementType: valueType, Scaled: true},
+		{Index: 4, Name: "C相数值", DataType: dlt698.ArrayIdent, ElementType: valueType, Scaled: true},
+		{Index: 5, Name: "谐波次数", DataType: dlt698.UnsignedIdent},
+		{Index: 6, Name: "换算及单位", DataType: dlt698.ScalerUnitIdent},
+	},
+	IC6: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "数值", DataType: valueType, Scaled: true},
+		{Index: 3, Name: "换算及单位", DataType: dlt698.ScalerUnitIdent},
+	},
+	IC7: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "事件记录表", DataType: dlt698.ArrayIdent},
+		{Index: 3, Name: "关联对象属性表", DataType: dlt698.ArrayIdent, ElementType: dlt698.OADIdent},
+		{Index: 4, Name: "当前记录数", DataType: dlt698.LongUnsignedIdent},
+		{Index: 5, Name: "最大记录数", DataType: dlt698.LongUnsignedIdent},
+		{Index: 6, Name: "配置参数", DataType: dlt698.StructureIdent},
+		{Index: 7, Name: "当前值记录表", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+		{Index: 8, Name: "上报标识", DataType: dlt698.BooleanIdent},
+		{Index: 9, Name: "有效标识", DataType: dlt698.BooleanIdent},
+		{Index: 10, Name: "时间状态记录表", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+	},
+	IC8: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "参数", DataType: valueType},
+	},
+	IC9: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "冻结数据表", DataType: dlt698.ArrayIdent},
+		{Index: 3, Name: "关联对象属性表", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+	},
+	IC10: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "配置表", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+		{Index: 3, Name: "记录表", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+		{Index: 4, Name: "当前元素个数", DataType: dlt698.LongUnsignedIdent},
+		{Index: 5, Name: "最大元素个数", DataType: dlt698.LongUnsignedIdent},
+	},
+	IC11: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "集合", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+		{Index: 3, Name: "当前元素个数", DataType: dlt698.LongUnsignedIdent},
+		{Index: 4, Name: "最大元素个数", DataType: dlt698.LongUnsignedIdent},
+	},
+	IC12: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "通信地址", DataType: dlt698.OctetStringIdent},
+		{Index: 3, Name: "互感器倍率", DataType: dlt698.StructureIdent},
+		{Index: 4, Name: "脉冲配置", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+		{Index: 5, Name: "有功功率", DataType: dlt698.DoubleLongIdent},
+		{Index: 6, Name: "无功功率", DataType: dlt698.DoubleLongIdent},
+		{Index: 7, Name: "当日正向有功电量", DataType: dlt698.ArrayIdent, ElementType: dlt698.DoubleLongUnsignedIdent},
+		{Index: 8, Name: "当月正向有功电量", DataType: dlt698.ArrayIdent, ElementType: dlt698.DoubleLongUnsignedIdent},
+		{Index: 9, Name: "当日反向有功电量", DataType: dlt698.ArrayIdent, ElementType: dlt698.DoubleLongUnsignedIdent},
+		{Index: 10, Name: "当月反向有功电量", DataType: dlt698.ArrayIdent, ElementType: dlt698.DoubleLongUnsignedIdent},
+		{Index: 11, Name: "当日正向无功电量", DataType: dlt698.ArrayIdent, ElementType: dlt698.DoubleLongUnsignedIdent},
+		{Index: 12, Name: "当月正向无功电量", DataType: dlt698.ArrayIdent, ElementType: dlt698.DoubleLongUnsignedIdent},
+		{Index: 13, Name: "当日反向无功电量", DataType: dlt698.ArrayIdent, ElementType: dlt698.DoubleLongUnsignedIdent},
+		{Index: 14, Name: "当月反向无功电量", DataType: dlt698.ArrayIdent, ElementType: dlt698.DoubleLongUnsignedIdent},
+		{Index: 15, Name: "正向有功电能示值", DataType: dlt698.ArrayIdent, ElementType: dlt698.DoubleLongUnsignedIdent},
+		{Index: 16, Name: "正向无功电能示值", DataType: dlt698.ArrayIdent, ElementType: dlt698.DoubleLongUnsignedIdent},
+		{Index: 17, Name: "反向有功电能示值", DataType: dlt698.ArrayIdent, ElementType: dlt698.DoubleLongUnsignedIdent},
+		{Index: 18, Name: "反向无功电能示值", DataType: dlt698.ArrayIdent, ElementType: dlt698.DoubleLongUnsignedIdent},
+	},
+	IC13: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "控制方案集", DataType: dlt698.ArrayIdent},
+		{Index: 3, Name: "控制投入状态", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+		{Index: 4, Name: "控制输出状态", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+		{Index: 5, Name: "越限告警状态", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+	},
+	IC14: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "区间统计值", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+		{Index: 3, Name: "关联对象属性表", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+	},
+	IC15: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "累加平均值", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+		{Index: 3, Name: "关联对象属性表", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+	},
+	IC16: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "极值", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+		{Index: 3, Name: "关联对象属性表", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+	},
+	IC17: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "显示对象列表", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+		{Index: 3, Name: "显示时间", DataType: dlt698.LongUnsignedIdent},
+		{Index: 4, Name: "显示参数", DataType: dlt698.StructureIdent},
+	},
+	IC18: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "文件信息", DataType: dlt698.StructureIdent},
+		{Index: 3, Name: "命令结果", DataType: dlt698.EnumIdent},
+		{Index: 4, Name: "传输块状态字", DataType: dlt698.BitStringIdent},
+	},
+	IC19: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "设备描述符", DataType: dlt698.VisibleStringIdent},
+		{Index: 3, Name: "版本信息", DataType: dlt698.StructureIdent},
+		{Index: 4, Name: "生产日期", DataType: dlt698.DateTimesIdent},
+		{Index: 5, Name: "子设备列表", DataType: dlt698.ArrayIdent, ElementType: dlt698.OIIdent},
+		{Index: 6, Name: "支持规约列表", DataType: dlt698.ArrayIdent, ElementType: dlt698.VisibleStringIdent},
+		{Index: 7, Name: "允许跟随上报", DataType: dlt698.BooleanIdent},
+		{Index: 8, Name: "允许主动上报", DataType: dlt698.BooleanIdent},
+		{Index: 9, Name: "允许与主站通话", DataType: dlt698.BooleanIdent},
+		{Index: 10, Name: "上报通道", DataType: dlt698.ArrayIdent, ElementType: dlt698.OADIdent},
+	},
+	IC20: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "对象列表", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+		{Index: 3, Name: "应用语境信息", DataType: dlt698.StructureIdent},
+		{Index: 4, Name: "当前连接的客户机地址", DataType: dlt698.UnsignedIdent},
+		{Index: 5, Name: "连接认证机制", DataType: dlt698.EnumIdent},
+	},
+	IC21: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "ESAM序列号", DataType: dlt698.OctetStringIdent},
+		{Index: 3, Name: "ESAM版本号", DataType: dlt698.OctetStringIdent},
+		{Index: 4, Name: "对称密钥版本", DataType: dlt698.OctetStringIdent},
+		{Index: 5, Name: "会话时效门限", DataType: dlt698.DoubleLongUnsignedIdent},
+		{Index: 6, Name: "会话时效剩余时间", DataType: dlt698.DoubleLongUnsignedIdent},
+		{Index: 7, Name: "当前计数器", DataType: dlt698.StructureIdent},
+		{Index: 8, Name: "证书版本", DataType: dlt698.StructureIdent},
+		{Index: 9, Name: "终端证书序列号", DataType: dlt698.OctetStringIdent},
+		{Index: 10, Name: "终端证书", DataType: dlt698.OctetStringIdent},
+		{Index: 11, Name: "主站证书序列号", DataType: dlt698.OctetStringIdent},
+		{Index: 12, Name: "主站证书", DataType: dlt698.OctetStringIdent},
+		{Index: 13, Name: "ESAM安全存储对象列表", DataType: dlt698.ArrayIdent, ElementType: dlt698.OADIdent},
+	},
+	IC22: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "设备对象列表", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+		{Index: 3, Name: "设备对象数量", DataType: dlt698.UnsignedIdent},
+	},
+	IC23: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "总加配置表", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+		{Index: 3, Name: "总加有功功率", DataType: dlt698.Long64Ident},
+		{Index: 4, Name: "总加无功功率", DataType: dlt698.Long64Ident},
+		{Index: 5, Name: "总加滑差时间内平均有功功率", DataType: dlt698.Long64Ident},
+		{Index: 6, Name: "总加滑差时间内平均无功功率", DataType: dlt698.Long64Ident},
+		{Index: 7, Name: "总加日有功电量", DataType: dlt698.ArrayIdent, ElementType: dlt698.Long64Ident},
+		{Index: 8, Name: "总加日无功电量", DataType: dlt698.ArrayIdent, ElementType: dlt698.Long64Ident},
+		{Index: 9, Name: "总加月有功电量", DataType: dlt698.ArrayIdent, ElementType: dlt698.Long64Ident},
+		{Index: 10, Name: "总加月无功电量", DataType: dlt698.ArrayIdent, ElementType: dlt698.Long64Ident},
+		{Index: 11, Name: "总加剩余电量(费)", DataType: dlt698.Long64Ident},
+		{Index: 12, Name: "当前功率下浮控控后总加有功功率冻结值", DataType: dlt698.Long64Ident},
+		{Index: 13, Name: "总加组滑差时间周期", DataType: dlt698.UnsignedIdent},
+		{Index: 14, Name: "总加组功控轮次配置", DataType: dlt698.BitStringIdent},
+		{Index: 15, Name: "总加组电控轮次配置", DataType: dlt698.BitStringIdent},
+		{Index: 16, Name: "总加组控制设置状态", DataType: dlt698.StructureIdent},
+		{Index: 17, Name: "总加组当前控制状态", DataType: dlt698.StructureIdent},
+		{Index: 18, Name: "换算及单位", DataType: dlt698.ScalerUnitIdent},
+	},
+	IC24: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "关联对象属性表", DataType: dlt698.ArrayIdent, ElementType: dlt698.OADIdent},
+		{Index: 3, Name: "当前记录数", DataType: dlt698.StructureIdent},
+		{Index: 4, Name: "最大记录数", DataType: dlt698.LongUnsignedIdent},
+		{Index: 5, Name: "配置参数", DataType: dlt698.StructureIdent},
+		{Index: 6, Name: "事件记录表1", DataType: dlt698.ArrayIdent},
+		{Index: 7, Name: "事件记录表2", DataType: dlt698.ArrayIdent},
+		{Index: 8, Name: "事件记录表3", DataType: dlt698.ArrayIdent},
+		{Index: 9, Name: "事件记录表4", DataType: dlt698.ArrayIdent},
+		{Index: 10, Name: "当前值记录表", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+		{Index: 11, Name: "上报标识", DataType: dlt698.EnumIdent},
+		{Index: 12, Name: "有效标识", DataType: dlt698.BooleanIdent},
+		{Index: 14, Name: "时间状态记录表", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+	},
+	IC25: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "通信配置", DataType: dlt698.StructureIdent},
+		{Index: 3, Name: "主站通信参数表", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+		{Index: 4, Name: "短信通信参数", DataType: dlt698.StructureIdent},
+		{Index: 5, Name: "版本信息", DataType: dlt698.StructureIdent},
+		{Index: 6, Name: "支持规约列表", DataType: dlt698.ArrayIdent, ElementType: dlt698.VisibleStringIdent},
+		{Index: 7, Name: "SIM卡的ICCID", DataType: dlt698.VisibleStringIdent},
+		{Index: 8, Name: "IMSI", DataType: dlt698.VisibleStringIdent},
+		{Index: 9, Name: "信号强度", DataType: dlt698.LongIdent},
+		{Index: 10, Name: "SIM卡号码", DataType: dlt698.VisibleStringIdent},
+		{Index: 11, Name: "拨号IP", DataType: dlt698.OctetStringIdent},
+	},
+	IC26: {
+		{Index: 1, Name: "逻辑名", DataType: dlt698.OctetStringIdent},
+		{Index: 2, Name: "通信配置", DataType: dlt698.StructureIdent},
+		{Index: 3, Name: "主站通信参数表", DataType: dlt698.ArrayIdent, ElementType: dlt698.StructureIdent},
+		{Index: 4, Name: "网络配置", DataType: dlt698.StructureIdent},
+		{Index: 5, Name: "MAC地址", DataType: dlt698.OctetStringIdent},
+	},
+}
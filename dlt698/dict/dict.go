@@ -0,0 +1,141 @@
+// Package dict DL/T 698.45 对象字典
+// 提供标准OI的接口类、属性名称、数据类型及默认换算单位，并可以将读取结果换算为带单位的工程量
+package dict
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Object 对象
+type Object struct {
+	OI        uint16      //对象标识
+	Name      string      //对象名称
+	Class     Class       //接口类
+	ValueType byte        //数值属性的数据类型标签
+	Scaler    int8        //默认换算
+	Unit      byte        //默认单位
+	Extra     []Attribute //对象特有的属性
+}
+
+var (
+	objects     = make(map[uint16]*Object)
+	objectNames = make(map[string]*Object)
+)
+
+func register(list ...*Object) {
+	for _, o := range list {
+		if _, ok := objects[o.OI]; ok {
+			panic(fmt.Sprintf("dict: duplicate oi %04X", o.OI))
+		}
+		if _, ok := objectNames[o.Name]; ok {
+			panic("dict: duplicate name " + o.Name)
+		}
+		objects[o.OI] = o
+		objectNames[o.Name] = o
+	}
+}
+
+// Lookup 根据OI查找对象
+func Lookup(oi uint16) (*Object, bool) {
+	o, ok := objects[oi]
+	return o, ok
+}
+
+// LookupName 根据名称查找对象，如"正向有功电能"
+func LookupName(name string) (*Object, bool) {
+	o, ok := objectNames[name]
+	return o, ok
+}
+
+// Attributes 返回对象的全部属性，数值属性的数据类型已替换为对象的数值类型
+func (o *Object) Attributes() []Attribute {
+	template := classAttributes[o.Class]
+	attributes := make([]Attribute, 0, len(template)+len(o.Extra))
+	for _, a := range template {
+		if a.DataType == valueType {
+			a.DataType = o.ValueType
+		}
+		if a.ElementType == valueType {
+			a.ElementType = o.ValueType
+		}
+		attributes = append(attributes, a)
+	}
+	return append(attributes, o.Extra...)
+}
+
+// Attribute 根据属性序号获取属性
+func (o *Object) Attribute(index byte) (Attribute, bool) {
+	for _, a := range o.Attributes() {
+		if a.Index == index {
+			return a, true
+		}
+	}
+	return Attribute{}, false
+}
+
+func (o *Object) String() string {
+	return fmt.Sprintf("%04X %s(%s)", o.OI, o.Name, o.Class)
+}
+
+/*----------------------OAD-----------------------------*/
+
+// Entry 字典中的一个对象属性描述符
+type Entry struct {
+	Object    *Object
+	Attribute Attribute
+	Index     byte //属性内元素索引，0表示整个属性
+}
+
+// OAD 根据OI字符串、属性序号及元素索引查找，如 OAD("0010", 2, 0)
+func OAD(oi string, attribute byte, index byte) (*Entry, error) {
+	value, err := strconv.ParseUint(oi, 16, 16)
+	if err != nil || len(oi) != 4 {
+		return nil, errors.New("dict: invalid oi " + oi)
+	}
+	o, ok := Lookup(uint16(value))
+	if !ok {
+		return nil, errors.New("dict: unknown oi " + oi)
+	}
+	return newEntry(o, attribute, index)
+}
+
+// OADByName 根据对象名称、属性序号及元素索引查找，如 OADByName("正向有功电能", 2, 0)
+func OADByName(name string, attribute byte, index byte) (*Entry, error) {
+	o, ok := LookupName(name)
+	if !ok {
+		return nil, errors.New("dict: unknown name " + name)
+	}
+	return newEntry(o, attribute, index)
+}
+
+// ParseOAD 根据4个字节的OAD查找，属性特征被忽略
+func ParseOAD(oad []byte) (*Entry, error) {
+	if len(oad) != 4 {
+		return nil, errors.New("dict: oad size != 4")
+	}
+	o, ok := Lookup(uint16(oad[0])<<8 | uint16(oad[1]))
+	if !ok {
+		return nil, errors.New("dict: unknown oad " + hex.EncodeToString(oad))
+	}
+	return newEntry(o, oad[2]&0x1F, oad[3])
+}
+
+func newEntry(o *Object, attribute byte, index byte) (*Entry, error) {
+	a, ok := o.Attribute(attribute)
+	if !ok {
+		return nil, fmt.Errorf("dict: %04X has no attribute %d", o.OI, attribute)
+	}
+	return &Entry{Object: o, Attribute: a, Index: index}, nil
+}
+
+// Bytes 返回4个字节的OAD，可以直接用于CreateGetRequestNormal等方法
+func (e *Entry) Bytes() []byte {
+	return []byte{byte(e.Object.OI >> 8), byte(e.Object.OI), e.Attribute.Index, e.Index}
+}
+
+func (e *Entry) String() string {
+	return fmt.Sprintf("%04X%02X%02X %s %s", e.Object.OI, e.Attribute.Index, e.Index, e.Object.Name, e.Attribute.Name)
+}
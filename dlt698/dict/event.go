@@ -0,0 +1,209 @@
+package dict
+
+import (
+	"bytes"
+	"context"
+	"dlt698"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// 事件记录的固定列
+var (
+	OADEventIndex  = []byte{0x20, 0x22, 0x02, 0x00} //事件记录序号
+	OADEventStart  = []byte{0x20, 0x1E, 0x02, 0x00} //事件发生时间
+	OADEventEnd    = []byte{0x20, 0x20, 0x02, 0x00} //事件结束时间
+	OADEventSource = []byte{0x20, 0x24, 0x02, 0x00} //事件发生源
+	OADEventReport = []byte{0x33, 0x00, 0x02, 0x00} //事件上报状态
+)
+
+// Event 事件记录
+type Event struct {
+	Entry   *Entry           //事件记录表，如30110200、30000700
+	Table   int              //分项事件(IC24)的事件记录表序号1~4，事件对象(IC7)为0
+	Index   uint32           //事件记录序号
+	Start   time.Time        //事件发生时间
+	End     time.Time        //事件结束时间，事件未结束时为零值
+	Source  dlt698.DataInter //事件发生源，未选择或为null时为nil
+	Reports []ReportStatus   //事件上报状态
+	Related []EventValue     //关联对象属性，按RCSD的顺序
+}
+
+// ReportStatus 通道上报状态
+type ReportStatus struct {
+	Channel []byte //通道OAD
+	Status  uint8  //上报状态
+}
+
+// EventValue 关联对象属性的值
+type EventValue struct {
+	OAD  []byte
+	Data dlt698.DataInter
+}
+
+// Value 根据OAD查找关联对象属性的值
+func (e *Event) Value(oad []byte) (dlt698.DataInter, bool) {
+	for _, v := range e.Related {
+		if string(v.OAD) == string(oad) {
+			return v.Data, true
+		}
+	}
+	return nil, false
+}
+
+// EventRCSD 读取事件记录的RCSD，包括全部固定列及指定的关联对象属性
+func EventRCSD(related ...[]byte) *dlt698.RCSD {
+	columns := append([][]byte{OADEventIndex, OADEventStart, OADEventEnd, OADEventSource, OADEventReport}, related...)
+	rcsd := &dlt698.RCSD{CSDs: make([]*dlt698.CSD, len(columns))}
+	for i, oad := range columns {
+		rcsd.CSDs[i] = &dlt698.CSD{CsdType: 0, Oad: oad}
+	}
+	return rcsd
+}
+
+// eventTable 事件记录表的序号，不是事件记录表时返回错误
+func eventTable(e *Entry) (int, error) {
+	switch {
+	case e.Object.Class == IC7 && e.Attribute.Index == 2:
+		return 0, nil
+	case e.Object.Class == IC24 && e.Attribute.Index >= 6 && e.Attribute.Index <= 9:
+		return int(e.Attribute.Index) - 5, nil
+	}
+	return 0, errors.New("dict: " + e.String() + " is not an event record table")
+}
+
+// DecodeEvents 将事件记录表的读取结果按RCSD映射为事件记录
+func DecodeEvents(rr *dlt698.ResultRecord) ([]*Event, error) {
+	if dar, ok := rr.Data.(*dlt698.DAR); ok {
+		return nil, dar.Err()
+	}
+	if rr.Rcsd == nil {
+		return nil, errors.New("dict: rcsd == nil")
+	}
+	rows := rr.Rows()
+	events := make([]*Event, len(rows))
+	for i, row := range rows {
+		event, err := DecodeEvent(rr.Oad, rr.Rcsd, row)
+		if err != nil {
+			return nil, err
+		}
+		events[i] = event
+	}
+	return events, nil
+}
+
+// DecodeEvent 将一行事件记录按RCSD映射为事件记录，oad为事件记录表
+func DecodeEvent(oad []byte, rcsd *dlt698.RCSD, row []dlt698.DataInter) (*Event, error) {
+	entry, err := ParseOAD(oad)
+	if err != nil {
+		return nil, err
+	}
+	table, err := eventTable(entry)
+	if err != nil {
+		return nil, err
+	}
+	if len(row) != len(rcsd.CSDs) {
+		return nil, errors.New("dict: event row size != rcsd size")
+	}
+	event := &Event{Entry: entry, Table: table}
+	for i, csd := range rcsd.CSDs {
+		oad, err := csdOAD(csd)
+		if err != nil {
+			return nil, err
+		}
+		data := row[i]
+		if _, ok := data.(*dlt698.Null); ok {
+			data = nil
+		}
+		switch {
+		case bytes.Equal(oad, OADEventIndex):
+			if data != nil {
+				index, ok := number(data)
+				if !ok {
+					return nil, errors.New("dict: event index is not a number")
+				}
+				event.Index = uint32(index)
+			}
+		case bytes.Equal(oad, OADEventStart):
+			if event.Start, err = recordTime(data); err != nil {
+				return nil, err
+			}
+		case bytes.Equal(oad, OADEventEnd):
+			if event.End, err = recordTime(data); err != nil {
+				return nil, err
+			}
+		case bytes.Equal(oad, OADEventSource):
+			event.Source = data
+		case bytes.Equal(oad, OADEventReport):
+			if event.Reports, err = reportStatus(data); err != nil {
+				return nil, err
+			}
+		default:
+			event.Related = append(event.Related, EventValue{OAD: oad, Data: row[i]})
+		}
+	}
+	return event, nil
+}
+
+// ReadEvents 读取事件记录，selector为nil时读取最近一次(方法9)
+func ReadEvents(ctx context.Context, c *dlt698.Client, oad []byte, selector dlt698.Selector, related ...[]byte) ([]*Event, error) {
+	if selector == nil {
+		selector = &dlt698.Selector9{Last: 1}
+	}
+	rr, err := c.GetRecord(ctx, oad, selector, EventRCSD(related...))
+	if err != nil {
+		return nil, err
+	}
+	return DecodeEvents(rr)
+}
+
+// csdOAD 记录列的OAD，记录列为ROAD或OAD长度错误时返回错误
+func csdOAD(csd *dlt698.CSD) ([]byte, error) {
+	if csd == nil || csd.CsdType != 0 || len(csd.Oad) != 4 {
+		return nil, errors.New("dict: record column is not an oad")
+	}
+	return csd.Oad, nil
+}
+
+// recordTime 记录中的时间，支持date_time_s及date_time，null为零值
+func recordTime(data dlt698.DataInter) (time.Time, error) {
+	switch t := data.(type) {
+	case nil:
+		return time.Time{}, nil
+	case *dlt698.DateTimes:
+		return t.Time(), nil
+	case *dlt698.DateTime:
+		return t.Time(), nil
+	}
+	return time.Time{}, errors.New("dict: record time is not date_time_s")
+}
+
+// reportStatus 解析 array 通道上报状态，通道上报状态∷=structure{通道 OAD, 上报状态 unsigned}
+func reportStatus(data dlt698.DataInter) ([]ReportStatus, error) {
+	if data == nil {
+		return nil, nil
+	}
+	array, ok := data.(*dlt698.Array)
+	if !ok {
+		return nil, errors.New("dict: event report status is not an array")
+	}
+	reports := make([]ReportStatus, 0, len(array.DataArray))
+	for _, element := range array.DataArray {
+		s, ok := element.(*dlt698.Structure)
+		if !ok || len(s.DataArray) != 2 {
+			return nil, errors.New("dict: invalid channel report status")
+		}
+		channel, ok1 := s.DataArray[0].(*dlt698.OAD)
+		status, ok2 := s.DataArray[1].(*dlt698.Unsigned)
+		if !ok1 || !ok2 {
+			return nil, errors.New("dict: invalid channel report status")
+		}
+		reports = append(reports, ReportStatus{Channel: channel.Data, Status: status.Data})
+	}
+	return reports, nil
+}
+
+func (e *Event) String() string {
+	return e.Entry.Object.Name + " #" + strconv.FormatUint(uint64(e.Index), 10) + " " + e.Start.Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,177 @@
+package dict
+
+import (
+	"bytes"
+	"context"
+	"dlt698"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 冻结记录的固定列
+var (
+	OADFreezeIndex = []byte{0x20, 0x23, 0x02, 0x00} //冻结记录序号
+	OADFreezeTime  = []byte{0x20, 0x21, 0x02, 0x00} //数据冻结时间
+)
+
+// FreezeColumn 冻结对象的关联对象属性(属性3)
+type FreezeColumn struct {
+	Period uint16 //冻结周期
+	OAD    []byte //关联对象属性描述符
+	Depth  uint16 //存储深度
+}
+
+// FreezeValue 冻结数据，每个冻结时间的每个关联对象属性一行
+type FreezeValue struct {
+	Time       time.Time        //数据冻结时间
+	OAD        []byte           //关联对象属性描述符
+	Entry      *Entry           //字典中没有该OAD时为nil
+	Data       dlt698.DataInter //原始数据
+	Quantities []Quantity       //换算后的工程量，不需要换算或无法换算时为nil
+}
+
+// freezeOAD 冻结对象的属性OAD，不是冻结对象(IC9)时返回错误
+func freezeOAD(oi uint16, attribute byte) ([]byte, error) {
+	o, ok := Lookup(oi)
+	if !ok || o.Class != IC9 {
+		return nil, fmt.Errorf("dict: %04X is not a freeze object", oi)
+	}
+	return []byte{byte(oi >> 8), byte(oi), attribute, 0x00}, nil
+}
+
+// FreezeColumns 解析关联对象属性表，关联对象属性∷=structure{冻结周期 long-unsigned, 关联对象属性描述符 OAD, 存储深度 long-unsigned}
+func FreezeColumns(data dlt698.DataInter) ([]FreezeColumn, error) {
+	if dar, ok := data.(*dlt698.DAR); ok {
+		return nil, dar.Err()
+	}
+	array, ok := data.(*dlt698.Array)
+	if !ok {
+		return nil, errors.New("dict: freeze columns is not an array")
+	}
+	columns := make([]FreezeColumn, 0, len(array.DataArray))
+	for _, element := range array.DataArray {
+		s, ok := element.(*dlt698.Structure)
+		if !ok || len(s.DataArray) != 3 {
+			return nil, errors.New("dict: invalid freeze column")
+		}
+		period, ok1 := s.DataArray[0].(*dlt698.LongUnsigned)
+		oad, ok2 := s.DataArray[1].(*dlt698.OAD)
+		depth, ok3 := s.DataArray[2].(*dlt698.LongUnsigned)
+		if !ok1 || !ok2 || !ok3 {
+			return nil, errors.New("dict: invalid freeze column")
+		}
+		columns = append(columns, FreezeColumn{Period: period.Data, OAD: oad.Data, Depth: depth.Data})
+	}
+	return columns, nil
+}
+
+// ReadFreezeColumns 读取冻结对象的关联对象属性表(属性3)
+func ReadFreezeColumns(ctx context.Context, c *dlt698.Client, oi uint16) ([]FreezeColumn, error) {
+	oad, err := freezeOAD(oi, 3)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.Get(ctx, oad)
+	if err != nil {
+		return nil, err
+	}
+	return FreezeColumns(data)
+}
+
+// FreezeRCSD 读取冻结数据的RCSD，第一列为数据冻结时间
+func FreezeRCSD(oads ...[]byte) *dlt698.RCSD {
+	rcsd := &dlt698.RCSD{CSDs: []*dlt698.CSD{{CsdType: 0, Oad: OADFreezeTime}}}
+	for _, oad := range oads {
+		rcsd.CSDs = append(rcsd.CSDs, &dlt698.CSD{CsdType: 0, Oad: oad})
+	}
+	return rcsd
+}
+
+// ReadFreeze 读取冻结时间在[start, end)内的冻结数据(方法2)，oads为空时使用关联对象属性表中的全部OAD
+func ReadFreeze(ctx context.Context, c *dlt698.Client, oi uint16, start time.Time, end time.Time, oads ...[]byte) ([]FreezeValue, error) {
+	selector := &dlt698.Selector2{
+		Oad:       OADFreezeTime,
+		StartData: (&dlt698.DateTimes{}).BuildByTime(start),
+		EndData:   (&dlt698.DateTimes{}).BuildByTime(end),
+		Interval:  &dlt698.Null{},
+	}
+	return readFreeze(ctx, c, oi, selector, oads)
+}
+
+// ReadFreezeLast 读取最近n次冻结数据(方法9)，oads为空时使用关联对象属性表中的全部OAD
+func ReadFreezeLast(ctx context.Context, c *dlt698.Client, oi uint16, n uint8, oads ...[]byte) ([]FreezeValue, error) {
+	return readFreeze(ctx, c, oi, &dlt698.Selector9{Last: n}, oads)
+}
+
+func readFreeze(ctx context.Context, c *dlt698.Client, oi uint16, selector dlt698.Selector, oads [][]byte) ([]FreezeValue, error) {
+	oad, err := freezeOAD(oi, 2)
+	if err != nil {
+		return nil, err
+	}
+	if len(oads) == 0 {
+		columns, err := ReadFreezeColumns(ctx, c, oi)
+		if err != nil {
+			return nil, err
+		}
+		for _, column := range columns {
+			oads = append(oads, column.OAD)
+		}
+	}
+	rr, err := c.GetRecord(ctx, oad, selector, FreezeRCSD(oads...))
+	if err != nil {
+		return nil, err
+	}
+	return DecodeFreeze(rr)
+}
+
+// DecodeFreeze 将冻结数据表的读取结果转换为(冻结时间, OAD, 工程量)的行
+// RCSD中须包括数据冻结时间，冻结记录序号不作为数据行
+func DecodeFreeze(rr *dlt698.ResultRecord) ([]FreezeValue, error) {
+	if dar, ok := rr.Data.(*dlt698.DAR); ok {
+		return nil, dar.Err()
+	}
+	if rr.Rcsd == nil {
+		return nil, errors.New("dict: rcsd == nil")
+	}
+	timeColumn := -1
+	oads := make([][]byte, len(rr.Rcsd.CSDs))
+	for i, csd := range rr.Rcsd.CSDs {
+		oad, err := csdOAD(csd)
+		if err != nil {
+			return nil, err
+		}
+		oads[i] = oad
+		if bytes.Equal(oad, OADFreezeTime) {
+			timeColumn = i
+		}
+	}
+	if timeColumn < 0 {
+		return nil, errors.New("dict: rcsd has no freeze time")
+	}
+	var values []FreezeValue
+	for _, row := range rr.Rows() {
+		data := row[timeColumn]
+		if _, ok := data.(*dlt698.Null); ok {
+			data = nil
+		}
+		freezeTime, err := recordTime(data)
+		if err != nil {
+			return nil, err
+		}
+		for i, oad := range oads {
+			if i == timeColumn || bytes.Equal(oad, OADFreezeIndex) {
+				continue
+			}
+			value := FreezeValue{Time: freezeTime, OAD: oad, Data: row[i]}
+			if entry, err := ParseOAD(oad); err == nil {
+				value.Entry = entry
+				if entry.Attribute.Scaled {
+					value.Quantities, _ = entry.Scale(row[i])
+				}
+			}
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
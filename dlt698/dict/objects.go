@@ -0,0 +1,333 @@
+package dict
+
+import "dlt698"
+
+var phaseNames = []string{"A相", "B相", "C相"}
+
+func init() {
+	registerEnergy()
+	registerDemand()
+	registerVariables()
+	registerEvents()
+	registerParameters()
+	registerFreezes()
+	registerOthers()
+	dlt698.RegisterOADNamer(oadName)
+}
+
+// oadName Dump中显示的OAD名称
+func oadName(oad []byte) string {
+	e, err := ParseOAD(oad)
+	if err != nil {
+		return ""
+	}
+	return e.Object.Name + " " + e.Attribute.Name
+}
+
+// registerPhases 注册总及分相对象，分相对象的OI依次加1
+func registerPhases(oi uint16, name string, class Class, valueType byte, scaler int8, unit byte) {
+	register(&Object{OI: oi, Name: name, Class: class, ValueType: valueType, Scaler: scaler, Unit: unit})
+	for i, phase := range phaseNames {
+		register(&Object{OI: oi + uint16(i) + 1, Name: phase + name, Class: class, ValueType: valueType, Scaler: scaler, Unit: unit})
+	}
+}
+
+/*----------------------电能量 IC1-----------------------------*/
+
+func registerEnergy() {
+	registerPhases(0x0000, "组合有功电能", IC1, dlt698.DoubleLongIdent, -2, UnitKWh)
+	registerPhases(0x0010, "正向有功电能", IC1, dlt698.DoubleLongUnsignedIdent, -2, UnitKWh)
+	registerPhases(0x0020, "反向有功电能", IC1, dlt698.DoubleLongUnsignedIdent, -2, UnitKWh)
+	registerPhases(0x0030, "组合无功1电能", IC1, dlt698.DoubleLongIdent, -2, UnitKVarh)
+	registerPhases(0x0040, "组合无功2电能", IC1, dlt698.DoubleLongIdent, -2, UnitKVarh)
+	registerPhases(0x0050, "第一象限无功电能", IC1, dlt698.DoubleLongUnsignedIdent, -2, UnitKVarh)
+	registerPhases(0x0060, "第二象限无功电能", IC1, dlt698.DoubleLongUnsignedIdent, -2, UnitKVarh)
+	registerPhases(0x0070, "第三象限无功电能", IC1, dlt698.DoubleLongUnsignedIdent, -2, UnitKVarh)
+	registerPhases(0x0080, "第四象限无功电能", IC1, dlt698.DoubleLongUnsignedIdent, -2, UnitKVarh)
+	registerPhases(0x0090, "正向视在电能", IC1, dlt698.DoubleLongUnsignedIdent, -2, UnitKVAh)
+	registerPhases(0x00A0, "反向视在电能", IC1, dlt698.DoubleLongUnsignedIdent, -2, UnitKVAh)
+}
+
+/*----------------------最大需量 IC2-----------------------------*/
+
+func registerDemand() {
+	registerPhases(0x1010, "正向有功最大需量", IC2, dlt698.DoubleLongUnsignedIdent, -4, UnitKiloWatt)
+	registerPhases(0x1020, "反向有功最大需量", IC2, dlt698.DoubleLongUnsignedIdent, -4, UnitKiloWatt)
+	registerPhases(0x1030, "组合无功1最大需量", IC2, dlt698.DoubleLongIdent, -4, UnitKiloVar)
+	registerPhases(0x1040, "组合无功2最大需量", IC2, dlt698.DoubleLongIdent, -4, UnitKiloVar)
+	registerPhases(0x1050, "第一象限最大需量", IC2, dlt698.DoubleLongUnsignedIdent, -4, UnitKiloVar)
+	registerPhases(0x1060, "第二象限最大需量", IC2, dlt698.DoubleLongUnsignedIdent, -4, UnitKiloVar)
+	registerPhases(0x1070, "第三象限最大需量", IC2, dlt698.DoubleLongUnsignedIdent, -4, UnitKiloVar)
+	registerPhases(0x1080, "第四象限最大需量", IC2, dlt698.DoubleLongUnsignedIdent, -4, UnitKiloVar)
+	registerPhases(0x1090, "正向视在最大需量", IC2, dlt698.DoubleLongUnsignedIdent, -4, UnitKiloVA)
+	registerPhases(0x10A0, "反向视在最大需量", IC2, dlt698.DoubleLongUnsignedIdent, -4, UnitKiloVA)
+}
+
+/*----------------------变量 IC3/IC4/IC6-----------------------------*/
+
+func registerVariables() {
+	register(
+		&Object{OI: 0x2000, Name: "电压", Class: IC3, ValueType: dlt698.LongUnsignedIdent, Scaler: -1, Unit: UnitVolt},
+		&Object{OI: 0x2001, Name: "电流", Class: IC3, ValueType: dlt698.DoubleLongIdent, Scaler: -3, Unit: UnitAmpere},
+		&Object{OI: 0x2002, Name: "电压相角", Class: IC3, ValueType: dlt698.LongUnsignedIdent, Scaler: -1, Unit: UnitDegree},
+		&Object{OI: 0x2003, Name: "电压电流相角", Class: IC3, ValueType: dlt698.LongUnsignedIdent, Scaler: -1, Unit: UnitDegree},
+		&Object{OI: 0x2004, Name: "有功功率", Class: IC4, ValueType: dlt698.DoubleLongIdent, Scaler: -1, Unit: UnitWatt},
+		&Object{OI: 0x2005, Name: "无功功率", Class: IC4, ValueType: dlt698.DoubleLongIdent, Scaler: -1, Unit: UnitVar},
+		&Object{OI: 0x2006, Name: "视在功率", Class: IC4, ValueType: dlt698.DoubleLongIdent, Scaler: -1, Unit: UnitVA},
+		&Object{OI: 0x2007, Name: "一分钟平均有功功率", Class: IC4, ValueType: dlt698.DoubleLongIdent, Scaler: -1, Unit: UnitWatt},
+		&Object{OI: 0x2008, Name: "一分钟平均无功功率", Class: IC4, ValueType: dlt698.DoubleLongIdent, Scaler: -1, Unit: UnitVar},
+		&Object{OI: 0x2009, Name: "一分钟平均视在功率", Class: IC4, ValueType: dlt698.DoubleLongIdent, Scaler: -1, Unit: UnitVA},
+		&Object{OI: 0x200A, Name: "功率因数", Class: IC4, ValueType: dlt698.LongIdent, Scaler: -3, Unit: UnitNone},
+		&Object{OI: 0x200B, Name: "电压波形失真度", Class: IC3, ValueType: dlt698.LongIdent, Scaler: -2, Unit: UnitPercent},
+		&Object{OI: 0x200C, Name: "电流波形失真度", Class: IC3, ValueType: dlt698.LongIdent, Scaler: -2, Unit: UnitPercent},
+		&Object{OI: 0x200F, Name: "电网频率", Class: IC6, ValueType: dlt698.LongUnsignedIdent, Scaler: -2, Unit: UnitHertz},
+		&Object{OI: 0x2010, Name: "表内温度", Class: IC6, ValueType: dlt698.LongIdent, Scaler: -1, Unit: UnitCelsius},
+		&Object{OI: 0x2011, Name: "时钟电池电压", Class: IC6, ValueType: dlt698.LongUnsignedIdent, Scaler: -2, Unit: UnitVolt},
+		&Object{OI: 0x2012, Name: "停电抄表电池电压", Class: IC6, ValueType: dlt698.LongUnsignedIdent, Scaler: -2, Unit: UnitVolt},
+		&Object{OI: 0x2013, Name: "时钟电池工作时间", Class: IC6, ValueType: dlt698.DoubleLongUnsignedIdent, Scaler: 0, Unit: UnitMinute},
+		&Object{OI: 0x2014, Name: "电能表运行状态字", Class: IC6, ValueType: dlt698.ArrayIdent, Unit: UnitNone},
+		&Object{OI: 0x2017, Name: "当前有功需量", Class: IC6, ValueType: dlt698.DoubleLongIdent, Scaler: -4, Unit: UnitKiloWatt},
+		&Object{OI: 0x2018, Name: "当前无功需量", Class: IC6, ValueType: dlt698.DoubleLongIdent, Scaler: -4, Unit: UnitKiloVar},
+		&Object{OI: 0x2019, Name: "当前视在需量", Class: IC6, ValueType: dlt698.DoubleLongIdent, Scaler: -4, Unit: UnitKiloVA},
+		&Object{OI: 0x201A, Name: "当前电价", Class: IC6, ValueType: dlt698.DoubleLongUnsignedIdent, Scaler: -4, Unit: UnitPrice},
+		&Object{OI: 0x201B, Name: "当前费率电价", Class: IC6, ValueType: dlt698.DoubleLongUnsignedIdent, Scaler: -4, Unit: UnitPrice},
+		&Object{OI: 0x201C, Name: "当前阶梯电价", Class: IC6, ValueType: dlt698.DoubleLongUnsignedIdent, Scaler: -4, Unit: UnitPrice},
+		&Object{OI: 0x2026, Name: "电压不平衡率", Class: IC6, ValueType: dlt698.LongUnsignedIdent, Scaler: -2, Unit: UnitPercent},
+		&Object{OI: 0x2027, Name: "电流不平衡率", Class: IC6, ValueType: dlt698.LongUnsignedIdent, Scaler: -2, Unit: UnitPercent},
+		&Object{OI: 0x2029, Name: "安时值", Class: IC6, ValueType: dlt698.ArrayIdent, Scaler: -2, Unit: UnitAmpereHour},
+		&Object{OI: 0x202C, Name: "当前钱包文件", Class: IC8, ValueType: dlt698.StructureIdent},
+		&Object{OI: 0x202D, Name: "当前透支金额", Class: IC6, ValueType: dlt698.DoubleLongUnsignedIdent, Scaler: -2, Unit: UnitCurrency},
+		&Object{OI: 0x2031, Name: "月度用电量", Class: IC6, ValueType: dlt698.DoubleLongUnsignedIdent, Scaler: -2, Unit: UnitKWh},
+		&Object{OI: 0x2032, Name: "阶梯结算用电量", Class: IC6, ValueType: dlt698.DoubleLongUnsignedIdent, Scaler: -2, Unit: UnitKWh},
+		&Object{OI: 0x2131, Name: "A相电压合格率", Class: IC6, ValueType: dlt698.StructureIdent},
+		&Object{OI: 0x2132, Name: "B相电压合格率", Class: IC6, ValueType: dlt698.StructureIdent},
+		&Object{OI: 0x2133, Name: "C相电压合格率", Class: IC6, ValueType: dlt698.StructureIdent},
+		&Object{OI: 0x201E, Name: "事件发生时间", Class: IC8, ValueType: dlt698.DateTimesIdent},
+		&Object{OI: 0x2020, Name: "事件结束时间", Class: IC8, ValueType: dlt698.DateTimesIdent},
+		&Object{OI: 0x2021, Name: "数据冻结时间", Class: IC8, ValueType: dlt698.DateTimesIdent},
+		&Object{OI: 0x2022, Name: "事件记录序号", Class: IC8, ValueType: dlt698.DoubleLongUnsignedIdent},
+		&Object{OI: 0x2023, Name: "冻结记录序号", Class: IC8, ValueType: dlt698.DoubleLongUnsignedIdent},
+		&Object{OI: 0x2024, Name: "事件发生源", Class: IC8},
+		&Object{OI: 0x2025, Name: "事件当前值", Class: IC8, ValueType: dlt698.StructureIdent},
+		&Object{OI: 0x202A, Name: "目标服务器地址", Class: IC8, ValueType: dlt698.TSAIdent},
+		&Object{OI: 0x2200, Name: "通信流量", Class: IC6, ValueType: dlt698.StructureIdent, Unit: UnitByte},
+		&Object{OI: 0x2203, Name: "供电时间", Class: IC6, ValueType: dlt698.StructureIdent, Unit: UnitMinute},
+		&Object{OI: 0x2204, Name: "复位次数", Class: IC6, ValueType: dlt698.StructureIdent},
+	)
+}
+
+/*----------------------事件 IC7/IC24-----------------------------*/
+
+func registerEvents() {
+	for oi, name := range map[uint16]string{
+		0x3000: "电能表失压事件",
+		0x3001: "电能表欠压事件",
+		0x3002: "电能表过压事件",
+		0x3003: "电能表断相事件",
+		0x3004: "电能表失流事件",
+		0x3005: "电能表过流事件",
+		0x3006: "电能表断流事件",
+		0x3007: "电能表功率反向事件",
+		0x3008: "电能表过载事件",
+		0x300B: "电能表无功需量超限事件",
+	} {
+		register(&Object{OI: oi, Name: name, Class: IC24})
+	}
+	for oi, name := range map[uint16]string{
+		0x3009: "电能表正向有功需量超限事件",
+		0x300A: "电能表反向有功需量超限事件",
+		0x300C: "电能表功率因数超下限事件",
+		0x300D: "电能表全失压事件",
+		0x300E: "电能表辅助电源掉电事件",
+		0x300F: "电能表电压逆相序事件",
+		0x3010: "电能表电流逆相序事件",
+		0x3011: "电能表掉电事件",
+		0x3012: "电能表编程事件",
+		0x3013: "电能表清零事件",
+		0x3014: "电能表需量清零事件",
+		0x3015: "电能表事件清零事件",
+		0x3016: "电能表校时事件",
+		0x3017: "电能表时段表编程事件",
+		0x3018: "电能表时区表编程事件",
+		0x3019: "电能表周休日编程事件",
+		0x301A: "电能表结算日编程事件",
+		0x301B: "电能表开盖事件",
+		0x301C: "电能表开端钮盒事件",
+		0x301D: "电能表电压不平衡事件",
+		0x301E: "电能表电流不平衡事件",
+		0x301F: "电能表跳闸事件",
+		0x3020: "电能表合闸事件",
+		0x3021: "电能表节假日编程事件",
+		0x3022: "电能表有功组合方式编程事件",
+		0x3023: "电能表无功组合方式编程事件",
+		0x3024: "电能表费率参数表编程事件",
+		0x3025: "电能表阶梯表编程事件",
+		0x3026: "电能表密钥更新事件",
+		0x3027: "电能表异常插卡事件",
+		0x3028: "电能表购电记录",
+		0x3029: "电能表退费记录",
+		0x302A: "电能表恒定磁场干扰事件",
+		0x302B: "电能表负荷开关误动作事件",
+		0x302C: "电能表电源异常事件",
+		0x302D: "电能表电流严重不平衡事件",
+		0x302E: "电能表时钟故障事件",
+		0x302F: "电能表计量芯片故障事件",
+		0x3030: "通信模块变更事件",
+		0x3100: "终端初始化事件",
+		0x3101: "终端版本变更事件",
+		0x3104: "终端状态量变位事件",
+		0x3105: "电能表时钟超差事件",
+		0x3106: "终端停上电事件",
+		0x3107: "终端直流模拟量越上限事件",
+		0x3108: "终端直流模拟量越下限事件",
+		0x3109: "终端消息认证错误事件",
+		0x310A: "设备故障记录",
+		0x310B: "电能表示度下降事件",
+		0x310C: "电能量超差事件",
+		0x310D: "电能表飞走事件",
+		0x310E: "电能表停走事件",
+		0x310F: "终端抄表失败事件",
+		0x3110: "月通信流量超限事件",
+		0x3111: "发现未知电能表事件",
+		0x3112: "跨台区电能表事件",
+		0x3114: "终端对时事件",
+		0x3115: "遥控跳闸记录",
+		0x3116: "有功总电能量差动越限事件记录",
+		0x3117: "输出回路接入状态变位事件记录",
+		0x3118: "终端编程记录",
+		0x3119: "终端电流回路异常事件",
+		0x311A: "电能表在网状态切换事件",
+		0x311B: "终端对电表校时记录",
+		0x311C: "电能表数据变更监控记录",
+		0x3200: "功控跳闸记录",
+		0x3201: "电控跳闸记录",
+		0x3202: "购电参数设置记录",
+		0x3203: "电控告警事件记录",
+	} {
+		register(&Object{OI: oi, Name: name, Class: IC7})
+	}
+}
+
+/*----------------------参数 IC8-----------------------------*/
+
+func registerParameters() {
+	register(
+		&Object{OI: 0x4000, Name: "日期时间", Class: IC8, ValueType: dlt698.DateTimesIdent, Extra: []Attribute{
+			{Index: 3, Name: "校时模式", DataType: dlt698.EnumIdent},
+			{Index: 4, Name: "精准校时参数", DataType: dlt698.StructureIdent},
+		}},
+		&Object{OI: 0x4001, Name: "通信地址", Class: IC8, ValueType: dlt698.OctetStringIdent},
+		&Object{OI: 0x4002, Name: "表号", Class: IC8, ValueType: dlt698.OctetStringIdent},
+		&Object{OI: 0x4003, Name: "客户编号", Class: IC8, ValueType: dlt698.OctetStringIdent},
+		&Object{OI: 0x4004, Name: "设备地理位置", Class: IC8, ValueType: dlt698.StructureIdent},
+		&Object{OI: 0x4005, Name: "组地址", Class: IC8, ValueType: dlt698.ArrayIdent},
+		&Object{OI: 0x4006, Name: "时钟源", Class: IC8, ValueType: dlt698.StructureIdent},
+		&Object{OI: 0x4007, Name: "LCD参数", Class: IC8, ValueType: dlt698.StructureIdent},
+		&Object{OI: 0x4008, Name: "备用套时区表切换时间", Class: IC8, ValueType: dlt698.DateTimesIdent},
+		&Object{OI: 0x4009, Name: "备用套日时段切换时间", Class: IC8, ValueType: dlt698.DateTimesIdent},
+		&Object{OI: 0x400A, Name: "备用套分时费率切换时间", Class: IC8, ValueType: dlt698.DateTimesIdent},
+		&Object{OI: 0x400B, Name: "备用套阶梯电价切换时间", Class: IC8, ValueType: dlt698.DateTimesIdent},
+		&Object{OI: 0x400C, Name: "时区时段数", Class: IC8, ValueType: dlt698.StructureIdent},
+		&Object{OI: 0x400D, Name: "阶梯数", Class: IC8, ValueType: dlt698.UnsignedIdent},
+		&Object{OI: 0x400E, Name: "谐波分析次数", Class: IC8, ValueType: dlt698.UnsignedIdent},
+		&Object{OI: 0x400F, Name: "密钥总条数", Class: IC8, ValueType: dlt698.UnsignedIdent},
+		&Object{OI: 0x4010, Name: "计量元件数", Class: IC8, ValueType: dlt698.UnsignedIdent},
+		&Object{OI: 0x4011, Name: "公共假日表", Class: IC8, ValueType: dlt698.ArrayIdent},
+		&Object{OI: 0x4012, Name: "周休日特征字", Class: IC8, ValueType: dlt698.BitStringIdent},
+		&Object{OI: 0x4013, Name: "周休日用的日时段表号", Class: IC8, ValueType: dlt698.UnsignedIdent},
+		&Object{OI: 0x4014, Name: "当前套时区表", Class: IC8, ValueType: dlt698.ArrayIdent},
+		&Object{OI: 0x4015, Name: "备用套时区表", Class: IC8, ValueType: dlt698.ArrayIdent},
+		&Object{OI: 0x4016, Name: "当前套日时段表", Class: IC8, ValueType: dlt698.ArrayIdent},
+		&Object{OI: 0x4017, Name: "备用套日时段表", Class: IC8, ValueType: dlt698.ArrayIdent},
+		&Object{OI: 0x4018, Name: "当前套费率电价", Class: IC8, ValueType: dlt698.ArrayIdent},
+		&Object{OI: 0x4019, Name: "备用套费率电价", Class: IC8, ValueType: dlt698.ArrayIdent},
+		&Object{OI: 0x401A, Name: "当前套阶梯电价", Class: IC8, ValueType: dlt698.StructureIdent},
+		&Object{OI: 0x401B, Name: "备用套阶梯电价", Class: IC8, ValueType: dlt698.StructureIdent},
+		&Object{OI: 0x401C, Name: "电流互感器变比", Class: IC8, ValueType: dlt698.DoubleLongUnsignedIdent},
+		&Object{OI: 0x401D, Name: "电压互感器变比", Class: IC8, ValueType: dlt698.DoubleLongUnsignedIdent},
+		&Object{OI: 0x401E, Name: "报警金额限值", Class: IC8, ValueType: dlt698.StructureIdent},
+		&Object{OI: 0x401F, Name: "其它金额限值", Class: IC8, ValueType: dlt698.StructureIdent},
+		&Object{OI: 0x4020, Name: "报警电量限值", Class: IC8, ValueType: dlt698.StructureIdent},
+		&Object{OI: 0x4021, Name: "其它电量限值", Class: IC8, ValueType: dlt698.StructureIdent},
+		&Object{OI: 0x4030, Name: "电压合格率参数", Class: IC8, ValueType: dlt698.StructureIdent},
+		&Object{OI: 0x4100, Name: "最大需量周期", Class: IC8, ValueType: dlt698.UnsignedIdent},
+		&Object{OI: 0x4101, Name: "滑差时间", Class: IC8, ValueType: dlt698.UnsignedIdent},
+		&Object{OI: 0x4102, Name: "校表脉冲宽度", Class: IC8, ValueType: dlt698.UnsignedIdent},
+		&Object{OI: 0x4103, Name: "资产管理编码", Class: IC8, ValueType: dlt698.VisibleStringIdent},
+		&Object{OI: 0x4104, Name: "额定电压", Class: IC8, ValueType: dlt698.VisibleStringIdent},
+		&Object{OI: 0x4105, Name: "额定电流基本电流", Class: IC8, ValueType: dlt698.VisibleStringIdent},
+		&Object{OI: 0x4106, Name: "最大电流", Class: IC8, ValueType: dlt698.VisibleStringIdent},
+		&Object{OI: 0x4107, Name: "有功准确度等级", Class: IC8, ValueType: dlt698.VisibleStringIdent},
+		&Object{OI: 0x4108, Name: "无功准确度等级", Class: IC8, ValueType: dlt698.VisibleStringIdent},
+		&Object{OI: 0x4109, Name: "电能表有功常数", Class: IC8, ValueType: dlt698.DoubleLongUnsignedIdent},
+		&Object{OI: 0x410A, Name: "电能表无功常数", Class: IC8, ValueType: dlt698.DoubleLongUnsignedIdent},
+		&Object{OI: 0x410B, Name: "电能表型号", Class: IC8, ValueType: dlt698.VisibleStringIdent},
+		&Object{OI: 0x4111, Name: "软件备案号", Class: IC8, ValueType: dlt698.VisibleStringIdent},
+		&Object{OI: 0x4112, Name: "有功组合方式特征字", Class: IC8, ValueType: dlt698.BitStringIdent},
+		&Object{OI: 0x4113, Name: "无功组合方式1特征字", Class: IC8, ValueType: dlt698.BitStringIdent},
+		&Object{OI: 0x4114, Name: "无功组合方式2特征字", Class: IC8, ValueType: dlt698.BitStringIdent},
+		&Object{OI: 0x4116, Name: "结算日", Class: IC8, ValueType: dlt698.ArrayIdent},
+		&Object{OI: 0x4117, Name: "期间需量冻结周期", Class: IC8, ValueType: dlt698.TIIdent},
+		&Object{OI: 0x4300, Name: "电气设备", Class: IC19},
+		&Object{OI: 0x4400, Name: "应用连接", Class: IC20},
+		&Object{OI: 0x4401, Name: "应用连接认证密码", Class: IC8, ValueType: dlt698.VisibleStringIdent},
+		&Object{OI: 0x4500, Name: "公网通信模块1", Class: IC25},
+		&Object{OI: 0x4501, Name: "公网通信模块2", Class: IC25},
+		&Object{OI: 0x4510, Name: "以太网通信模块1", Class: IC26},
+		&Object{OI: 0x4511, Name: "以太网通信模块2", Class: IC26},
+	)
+}
+
+/*----------------------冻结 IC9-----------------------------*/
+
+func registerFreezes() {
+	register(
+		&Object{OI: 0x5000, Name: "瞬时冻结", Class: IC9},
+		&Object{OI: 0x5001, Name: "秒冻结", Class: IC9},
+		&Object{OI: 0x5002, Name: "分钟冻结", Class: IC9},
+		&Object{OI: 0x5003, Name: "小时冻结", Class: IC9},
+		&Object{OI: 0x5004, Name: "日冻结", Class: IC9},
+		&Object{OI: 0x5005, Name: "结算日冻结", Class: IC9},
+		&Object{OI: 0x5006, Name: "月冻结", Class: IC9},
+		&Object{OI: 0x5007, Name: "年冻结", Class: IC9},
+		&Object{OI: 0x5008, Name: "时区表切换冻结", Class: IC9},
+		&Object{OI: 0x5009, Name: "日时段表切换冻结", Class: IC9},
+		&Object{OI: 0x500A, Name: "费率电价切换冻结", Class: IC9},
+		&Object{OI: 0x500B, Name: "阶梯切换冻结", Class: IC9},
+		&Object{OI: 0x5011, Name: "阶梯结算冻结", Class: IC9},
+	)
+}
+
+/*----------------------采集监控、文件、ESAM、输入输出-----------------------------*/
+
+func registerOthers() {
+	register(
+		&Object{OI: 0x6000, Name: "采集档案配置表", Class: IC11},
+		&Object{OI: 0x6002, Name: "搜表", Class: IC11},
+		&Object{OI: 0x6012, Name: "任务配置表", Class: IC11},
+		&Object{OI: 0x6014, Name: "普通采集方案集", Class: IC11},
+		&Object{OI: 0x6016, Name: "事件采集方案集", Class: IC11},
+		&Object{OI: 0x6018, Name: "透明方案集", Class: IC11},
+		&Object{OI: 0x601C, Name: "上报方案集", Class: IC11},
+		&Object{OI: 0x601E, Name: "采集规则库", Class: IC11},
+		&Object{OI: 0x6032, Name: "采集状态集", Class: IC11},
+		&Object{OI: 0x6034, Name: "采集任务监控集", Class: IC11},
+		&Object{OI: 0xF000, Name: "文件分帧传输管理", Class: IC18},
+		&Object{OI: 0xF001, Name: "文件分块传输管理", Class: IC18},
+		&Object{OI: 0xF002, Name: "文件扩展传输管理", Class: IC18},
+		&Object{OI: 0xF100, Name: "ESAM", Class: IC21},
+		&Object{OI: 0xF101, Name: "安全模式参数", Class: IC8, ValueType: dlt698.EnumIdent},
+		&Object{OI: 0xF200, Name: "RS232", Class: IC22},
+		&Object{OI: 0xF201, Name: "RS485", Class: IC22},
+		&Object{OI: 0xF202, Name: "红外", Class: IC22},
+		&Object{OI: 0xF203, Name: "开关量输入", Class: IC22},
+		&Object{OI: 0xF204, Name: "直流模拟量", Class: IC22},
+		&Object{OI: 0xF205, Name: "继电器输出", Class: IC22},
+		&Object{OI: 0xF206, Name: "告警输出", Class: IC22},
+		&Object{OI: 0xF207, Name: "多功能端子", Class: IC22},
+		&Object{OI: 0xF208, Name: "交采接口", Class: IC22},
+		&Object{OI: 0xF209, Name: "载波无线接口", Class: IC22},
+		&Object{OI: 0xF20A, Name: "脉冲输入设备", Class: IC22},
+		&Object{OI: 0xF20B, Name: "蓝牙", Class: IC22},
+	)
+}
@@ -0,0 +1,115 @@
+package dict
+
+import (
+	"dlt698"
+	"errors"
+	"math"
+)
+
+// Quantity 换算后的工程量
+type Quantity struct {
+	Value float64 `json:"value"` //换算后的数值
+	Unit  string  `json:"unit"`  //单位符号
+	Valid bool    `json:"valid"` //数据为null时为false
+}
+
+// ScalerUnit 返回属性的默认换算及单位，不需要换算的属性返回nil
+func (e *Entry) ScalerUnit() *dlt698.ScalerUnit {
+	if !e.Attribute.Scaled {
+		return nil
+	}
+	return &dlt698.ScalerUnit{
+		Conver: &dlt698.Integer{Data: e.Object.Scaler + e.Attribute.scalerAdjust},
+		Unit:   e.Object.Unit,
+	}
+}
+
+// Scale 使用默认换算及单位换算读取到的数据
+// 数组按元素依次换算，最大需量等结构体取第一个元素，数据为DAR时返回对应的错误
+func (e *Entry) Scale(data dlt698.DataInter) ([]Quantity, error) {
+	su := e.ScalerUnit()
+	if su == nil {
+		return nil, errors.New("dict: " + e.String() + " is not scalable")
+	}
+	return ScaleWith(data, su)
+}
+
+// ScaleWith 使用指定的换算及单位换算数据，如从设备读取的属性3
+func ScaleWith(data dlt698.DataInter, su *dlt698.ScalerUnit) ([]Quantity, error) {
+	if dar, ok := data.(*dlt698.DAR); ok {
+		return nil, dar.Err()
+	}
+	var scaler int8
+	if su.Conver != nil {
+		scaler = su.Conver.Data
+	}
+	//负换算使用除法，避免 2201*0.1 得到 220.10000000000002
+	scale := func(v float64) float64 {
+		if scaler < 0 {
+			return v / math.Pow10(-int(scaler))
+		}
+		return v * math.Pow10(int(scaler))
+	}
+	unit := UnitSymbol(su.Unit)
+	var elements []dlt698.DataInter
+	if array, ok := data.(*dlt698.Array); ok {
+		elements = array.DataArray
+	} else {
+		elements = []dlt698.DataInter{data}
+	}
+	quantities := make([]Quantity, len(elements))
+	for i, element := range elements {
+		if s, ok := element.(*dlt698.Structure); ok && len(s.DataArray) > 0 {
+			element = s.DataArray[0]
+		}
+		if _, ok := element.(*dlt698.Null); ok {
+			quantities[i] = Quantity{Unit: unit}
+			continue
+		}
+		value, ok := number(element)
+		if !ok {
+			return nil, errors.New("dict: data is not a number")
+		}
+		quantities[i] = Quantity{Value: scale(value), Unit: unit, Valid: true}
+	}
+	return quantities, nil
+}
+
+// ScaleResult 根据读取结果中的OAD查找字典并换算
+func ScaleResult(rn *dlt698.ResultNormal) (*Entry, []Quantity, error) {
+	e, err := ParseOAD(rn.OAD)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rn.GetResult == nil {
+		return e, nil, errors.New("dict: get result == nil")
+	}
+	quantities, err := e.Scale(rn.GetResult.Data)
+	return e, quantities, err
+}
+
+func number(data dlt698.DataInter) (float64, bool) {
+	switch v := data.Value().(type) {
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
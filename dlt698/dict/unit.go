@@ -0,0 +1,102 @@
+package dict
+
+// 物理单位，见DL/T 698.45附录
+const (
+	UnitYear        byte = 1   //年
+	UnitMonth       byte = 2   //月
+	UnitWeek        byte = 3   //周
+	UnitDay         byte = 4   //日
+	UnitHour        byte = 5   //小时
+	UnitMinute      byte = 6   //分
+	UnitSecond      byte = 7   //秒
+	UnitDegree      byte = 8   //相位角 度
+	UnitCelsius     byte = 9   //温度 摄氏度
+	UnitCurrency    byte = 10  //本地货币
+	UnitWatt        byte = 27  //有功功率 W
+	UnitKiloWatt    byte = 28  //有功功率 kW
+	UnitVA          byte = 29  //视在功率 VA
+	UnitKiloVA      byte = 30  //视在功率 kVA
+	UnitVar         byte = 31  //无功功率 var
+	UnitKiloVar     byte = 32  //无功功率 kvar
+	UnitKWh         byte = 33  //有功能量 kWh
+	UnitKVAh        byte = 34  //视在能量 kVAh
+	UnitKVarh       byte = 35  //无功能量 kvarh
+	UnitAmpere      byte = 36  //电流 A
+	UnitCoulomb     byte = 37  //电量 C
+	UnitVolt        byte = 38  //电压 V
+	UnitHertz       byte = 47  //频率 Hz
+	UnitPercent     byte = 51  //百分比 %
+	UnitByte        byte = 52  //字节
+	UnitDBm         byte = 53  //分贝毫瓦
+	UnitPrice       byte = 54  //电价 元/kWh
+	UnitAmpereHour  byte = 55  //安时
+	UnitMillisecond byte = 56  //毫秒
+	UnitOther       byte = 254 //其他单位
+	UnitNone        byte = 255 //无单位
+)
+
+var unitSymbols = map[byte]string{
+	1:   "a",
+	2:   "mo",
+	3:   "wk",
+	4:   "d",
+	5:   "h",
+	6:   "min",
+	7:   "s",
+	8:   "°",
+	9:   "℃",
+	10:  "currency",
+	11:  "m",
+	12:  "m/s",
+	13:  "m³",
+	14:  "m³",
+	15:  "m³/h",
+	16:  "m³/h",
+	17:  "m³/d",
+	18:  "m³/d",
+	19:  "l",
+	20:  "kg",
+	21:  "N",
+	22:  "Nm",
+	23:  "P",
+	24:  "bar",
+	25:  "J",
+	26:  "J/h",
+	27:  "W",
+	28:  "kW",
+	29:  "VA",
+	30:  "kVA",
+	31:  "var",
+	32:  "kvar",
+	33:  "kWh",
+	34:  "kVAh",
+	35:  "kvarh",
+	36:  "A",
+	37:  "C",
+	38:  "V",
+	39:  "V/m",
+	40:  "F",
+	41:  "Ω",
+	42:  "Ωm²/m",
+	43:  "Wb",
+	44:  "T",
+	45:  "A/m",
+	46:  "H",
+	47:  "Hz",
+	48:  "1/(Wh)",
+	49:  "1/(varh)",
+	50:  "1/(VAh)",
+	51:  "%",
+	52:  "byte",
+	53:  "dBm",
+	54:  "元/kWh",
+	55:  "Ah",
+	56:  "ms",
+	254: "",
+	255: "",
+}
+
+// UnitSymbol 获取单位符号，无单位时返回空字符串
+func UnitSymbol(unit byte) string {
+	return unitSymbols[unit]
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var csTabs []int
@@ -88,6 +89,7 @@ type ProtocolDlt698Model struct {
 	Control *ControlRegion `json:"control_region"` //控制域
 	Address *AddressRegion `json:"address_region"` //地址域
 	Data    *APDU          `json:"data_region"`    //数据域
+	Segment *SegmentRegion `json:"segment"`        //分帧数据，分帧标志为1时代替数据域
 }
 
 // DecodeByStr 根据字符串解析
@@ -100,64 +102,92 @@ func (p *ProtocolDlt698Model) DecodeByStr(str string) error {
 }
 
 // DecodeByBytes 根据字节数组解析
+// 分帧标志为1时只解析分帧格式域到Segment中，完整的APDU需要通过Reassembler组装
 func (p *ProtocolDlt698Model) DecodeByBytes(frame []byte) error {
+	apduArray, err := p.decodeLink(frame)
+	if err != nil {
+		return err
+	}
+	if p.Control.Framing == "1" {
+		p.Data = nil
+		p.Segment = &SegmentRegion{}
+		return p.Segment.decoder(bytes.NewReader(apduArray))
+	}
+	p.Segment = nil
+	p.Data = &APDU{}
+	return p.Data.decoder(bytes.NewReader(apduArray))
+}
+
+const frameLengthMask = 0x3FFF //长度域bit0~bit13为长度
+
+// frameLength 长度域表示的帧长度，不含起始字符和结束字符
+// bit14为长度单位，0表示字节、1表示千字节，bit15保留；只支持以字节为单位，bit14或bit15为1时返回错误
+func frameLength(field uint16) (int, error) {
+	if field&^frameLengthMask != 0 {
+		return 0, errors.New("frame length's bit14 and bit15 != 0！")
+	}
+	return int(field), nil
+}
+
+// decodeLink 解析链路层，返回去除扰码后的链路用户数据
+func (p *ProtocolDlt698Model) decodeLink(frame []byte) ([]byte, error) {
 	buf := bytes.NewReader(frame)
 	//解析起始字符
 	var startChar byte
 	if err := binary.Read(buf, binary.BigEndian, &startChar); err != nil {
-		return errors.New("decode startChar err:" + err.Error())
+		return nil, errors.New("decode startChar err:" + err.Error())
 	}
 	if startChar != StartChar {
-		return errors.New("decode startChar err: start char err, != 0x68")
+		return nil, errors.New("decode startChar err: start char err, != 0x68")
 	}
 	//解析长度域
 	if err := binary.Read(buf, binary.LittleEndian, &p.Length); err != nil {
-		return errors.New("decode frame length err:" + err.Error())
+		return nil, errors.New("decode frame length err:" + err.Error())
 	}
-	if (p.Length & 0x6000) != 0 {
-		return errors.New("frame length's bit14 and bit15 != 0！")
+	if _, err := frameLength(p.Length); err != nil {
+		return nil, err
 	}
 	//解析控制域
 	p.Control = &ControlRegion{}
 	if err := p.Control.decoder(buf); err != nil {
-		return errors.New("decode control err:" + err.Error())
+		return nil, errors.New("decode control err:" + err.Error())
 	}
 	//解析地址域
 	p.Address = &AddressRegion{}
 	if err := p.Address.decoder(buf); err != nil {
-		return errors.New("decode address err:" + err.Error())
+		return nil, errors.New("decode address err:" + err.Error())
 	}
 	//校验帧头校验
 	hcsLen := 2 + 1 + 1 + p.Address.AddressLength + 1
 	checkHcs := p.Cs(frame[1 : hcsLen+1])
 	hcs := make([]byte, 2)
 	if err := binary.Read(buf, binary.BigEndian, &hcs); err != nil {
-		return errors.New("decode HCS err:" + err.Error())
+		return nil, errors.New("decode HCS err:" + err.Error())
 	}
 	if checkHcs[0] != hcs[0] || checkHcs[1] != hcs[1] {
-		return errors.New("decode HCS err！")
+		return nil, errors.New("decode HCS err！")
 	}
 	//长度域 - 控制域长度 - 地址域长度 - hcs长度 - fcs长度
 	dataLen := p.Length - 2 - 1 - 1 - uint16(p.Address.AddressLength) - 1 - 2 - 2
 	apduArray := make([]byte, dataLen)
 	if err := binary.Read(buf, binary.BigEndian, &apduArray); err != nil {
-		return errors.New("decode APDU err:" + err.Error())
+		return nil, errors.New("decode APDU err:" + err.Error())
 	}
 	//计算帧校验
 	checkFcs := p.Cs(frame[1 : len(frame)-3])
 	fcs := make([]byte, 2)
 	if err := binary.Read(buf, binary.LittleEndian, &fcs); err != nil {
-		return errors.New("decode FCS err:" + err.Error())
+		return nil, errors.New("decode FCS err:" + err.Error())
 	}
 	if checkFcs[0] != fcs[0] || checkFcs[1] != fcs[1] {
-		return errors.New("decode FCS err！")
+		return nil, errors.New("decode FCS err！")
 	}
 	var endChar byte
 	if err := binary.Read(buf, binary.BigEndian, &endChar); err != nil {
-		return errors.New("decode endChar err:" + err.Error())
+		return nil, errors.New("decode endChar err:" + err.Error())
 	}
 	if endChar != EndChar {
-		return errors.New("decode endChar err: endChar err, != 0x16")
+		return nil, errors.New("decode endChar err: endChar err, != 0x16")
 	}
 	//解析数据域
 	if p.Control.Sc == "1" {
@@ -165,9 +195,7 @@ func (p *ProtocolDlt698Model) DecodeByBytes(frame []byte) error {
 			apduArray[index] = value - ScCode
 		}
 	}
-	p.Data = &APDU{}
-	err := p.Data.decoder(bytes.NewReader(apduArray))
-	return err
+	return apduArray, nil
 }
 
 func (p *ProtocolDlt698Model) Encoder() ([]byte, error) {
@@ -179,7 +207,14 @@ func (p *ProtocolDlt698Model) Encoder() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	dataArray, err := p.Data.encoder()
+	var dataArray []byte
+	if p.Segment != nil {
+		dataArray, err = p.Segment.encoder()
+	} else if p.Data != nil {
+		dataArray, err = p.Data.encoder()
+	} else {
+		err = errors.New("apdu data == nil")
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -188,7 +223,11 @@ func (p *ProtocolDlt698Model) Encoder() ([]byte, error) {
 			dataArray[index] = value + ScCode
 		}
 	}
-	p.Length = uint16(len(controlArray) + len(addressArray) + len(dataArray) + 4 + 2)
+	length := len(controlArray) + len(addressArray) + len(dataArray) + 4 + 2
+	if length > frameLengthMask {
+		return nil, errors.New("encode length err: frame length > " + strconv.Itoa(frameLengthMask))
+	}
+	p.Length = uint16(length)
 	buf := new(bytes.Buffer)
 	err = binary.Write(buf, binary.LittleEndian, p.Length)
 	if err != nil {
@@ -203,7 +242,7 @@ func (p *ProtocolDlt698Model) Encoder() ([]byte, error) {
 		return nil, errors.New("encode address err:" + err.Error())
 	}
 	//编码HCS
-	hcs := p.Cs(append(buf.Bytes(), 0x00))
+	hcs := p.Cs(buf.Bytes())
 	err = binary.Write(buf, binary.LittleEndian, hcs)
 	if err != nil {
 		return nil, errors.New("encode HCS err:" + err.Error())
@@ -213,7 +252,7 @@ func (p *ProtocolDlt698Model) Encoder() ([]byte, error) {
 		return nil, errors.New("encode apdu err:" + err.Error())
 	}
 	//编码FCS
-	fcs := p.Cs(append(buf.Bytes(), 0x00))
+	fcs := p.Cs(buf.Bytes())
 	err = binary.Write(buf, binary.LittleEndian, fcs)
 	if err != nil {
 		return nil, errors.New("encode FCS err:" + err.Error())
@@ -224,28 +263,16 @@ func (p *ProtocolDlt698Model) Encoder() ([]byte, error) {
 	return encodeArray, nil
 }
 
+// Cs 计算帧校验(CRC16)，返回低字节在前的两个字节
 func (p *ProtocolDlt698Model) Cs(data []byte) []byte {
-	data = append(data, 0x00, 0x00)
-	fcs := p.cs16(data)
-	fcs ^= 0xffff
-	data[len(data)-2] = byte(fcs & 0x00ff)
-	data[len(data)-2+1] = byte((fcs >> 8) & 0x00ff)
-	c := fcs & 0x00ff
-	c1 := (fcs >> 8) & 0x00ff
-	return []byte{byte(c), byte(c1)}
+	fcs := p.cs16(data) ^ 0xffff
+	return []byte{byte(fcs & 0x00ff), byte((fcs >> 8) & 0x00ff)}
 }
 
 func (p *ProtocolDlt698Model) cs16(data []byte) int {
-	length := len(data) - 2
-	index := 0
 	CS16 := 0xffff
-	for {
-		length--
-		if length == 0 {
-			break
-		}
-		CS16 = (CS16 >> 8) ^ csTabs[(CS16^int(data[index]))&0xff]
-		index++
+	for _, value := range data {
+		CS16 = (CS16 >> 8) ^ csTabs[(CS16^int(value))&0xff]
 	}
 	return CS16
 }
@@ -318,14 +345,8 @@ func (a *AddressRegion) decoder(buf *bytes.Reader) error {
 }
 
 func (a *AddressRegion) encoder() ([]byte, error) {
-	addressTypeStr := fmt.Sprintf("%d%d", (a.AddressType>>1)&1, a.AddressType&1)
-	logicAddressStr := fmt.Sprintf("%d%d", (a.LogicAddress>>1)&1, a.LogicAddress&1)
 	length := len(a.Address)/2 - 1
-	addressLengthStr := strconv.FormatInt(int64(length&0b1111), 2)
-	adType, err := strconv.ParseUint(addressTypeStr+logicAddressStr+addressLengthStr, 2, 8)
-	if err != nil {
-		return nil, err
-	}
+	adType := (a.AddressType&0b11)<<6 | (a.LogicAddress&0b11)<<4 | byte(length&0b1111)
 	addressArray, err := hex.DecodeString(a.Address)
 	if err != nil {
 		return nil, err
@@ -361,8 +382,10 @@ func (a *APDU) decoder(buf *bytes.Reader) error {
 			return errors.New("decode APDU type err, not such type")
 		}
 	}
-	if err := binary.Read(buf, binary.BigEndian, &a.Pid); err != nil {
-		return errors.New("decode APDU pid err:" + err.Error())
+	if _, ok := a.Data.(securityRegion); !ok {
+		if err := binary.Read(buf, binary.BigEndian, &a.Pid); err != nil {
+			return errors.New("decode APDU pid err:" + err.Error())
+		}
 	}
 	if err := a.Data.decoder(buf); err != nil {
 		return err
@@ -375,7 +398,7 @@ func (a *APDU) decoder(buf *bytes.Reader) error {
 		}
 		if hasFollowReport == 0x01 {
 			a.FollowReport = &FollowReport{}
-			if err := a.TimeTag.decoder(buf); err != nil {
+			if err := a.FollowReport.decoder(buf); err != nil {
 				return err
 			}
 		}
@@ -404,7 +427,9 @@ func (a *APDU) encoder() ([]byte, error) {
 	if err != nil {
 		return nil, errors.New("encode APDU type err:" + err.Error())
 	}
-	encodeArray = append(encodeArray, a.Pid)
+	if _, ok := a.Data.(securityRegion); !ok {
+		encodeArray = append(encodeArray, a.Pid)
+	}
 	dataArray, err := a.Data.encoder()
 	if err != nil {
 		return nil, errors.New("encode APDU data err:" + err.Error())
@@ -440,6 +465,47 @@ func (a *APDU) encoder() ([]byte, error) {
 	return encodeArray, nil
 }
 
+// decodeVarLength 解析A-XDR可变长度，最高位为1时低7位表示长度所占字节数
+func decodeVarLength(buf *bytes.Reader) (int, error) {
+	first, err := buf.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if first&0x80 == 0 {
+		return int(first), nil
+	}
+	size := int(first & 0x7F)
+	if size == 0 || size > 4 {
+		return 0, errors.New("var length size err")
+	}
+	length := 0
+	for i := 0; i < size; i++ {
+		b, err := buf.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		length = length<<8 | int(b)
+	}
+	//长度不会超过剩余数据的位数，避免错误报文导致分配过大的内存
+	if length > buf.Len()*8 {
+		return 0, errors.New("var length exceeds remaining data")
+	}
+	return length, nil
+}
+
+// encodeVarLength 编码A-XDR可变长度
+func encodeVarLength(length int) []byte {
+	if length < 0x80 {
+		return []byte{byte(length)}
+	}
+	var array []byte
+	for length > 0 {
+		array = append([]byte{byte(length)}, array...)
+		length >>= 8
+	}
+	return append([]byte{0x80 | byte(len(array))}, array...)
+}
+
 /*----------------------时间标签-----------------------------*/
 
 type TimeTag struct {
@@ -447,6 +513,14 @@ type TimeTag struct {
 	Ti       *TI        `xml:"ti"`        //允许传输延时时间
 }
 
+// Expired 当前时间是否已超过发送时标加上允许传输延时时间，间隔时间为0时不判断
+func (t *TimeTag) Expired(now time.Time) bool {
+	if t == nil || t.SendTime == nil || t.Ti == nil || t.Ti.Interval == 0 {
+		return false
+	}
+	return now.After(t.Ti.AddTo(t.SendTime.Time()))
+}
+
 func (t *TimeTag) decoder(buf *bytes.Reader) error {
 	t.SendTime = &DateTimes{}
 	if err := t.SendTime.decoder(buf); err != nil {
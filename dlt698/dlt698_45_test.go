@@ -0,0 +1,63 @@
+package dlt698
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFrameLength(t *testing.T) {
+	tests := []struct {
+		field  uint16
+		length int
+		ok     bool
+	}{
+		{0x0010, 0x0010, true},
+		{0x2000, 0x2000, true},
+		{0x3FFF, 0x3FFF, true},
+		{0x4010, 0, false},
+		{0x8010, 0, false},
+	}
+	for _, tt := range tests {
+		length, err := frameLength(tt.field)
+		if (err == nil) != tt.ok || length != tt.length {
+			t.Errorf("frameLength(%04x) = %d, %v", tt.field, length, err)
+		}
+	}
+}
+
+// TestFrameLengthDecoders 报文解析和数据流使用同一个长度域规则
+func TestFrameLengthDecoders(t *testing.T) {
+	//长度超过0x2000的报文，bit13为长度的一部分
+	long, err := CreateGetResponseNormal("010203040506", 1, 1, []byte{0x40, 0x01, 0x02, 0x00}, &OctetString{Data: strings.Repeat("aa", 0x2100)}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//长度单位为千字节的报文
+	unit, err := CreateGetResponseNormal("010203040506", 1, 1, []byte{0x40, 0x01, 0x02, 0x00}, &OctetString{Data: "010203040506"}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unit[2] |= 0x40
+
+	if err = (&ProtocolDlt698Model{}).DecodeByBytes(long); err != nil {
+		t.Fatalf("decode long frame: %v", err)
+	}
+	if err = (&ProtocolDlt698Model{}).DecodeByBytes(unit); err == nil {
+		t.Fatal("decode frame with length unit")
+	}
+
+	stream := NewStreamDecoder(bytes.NewReader(append(append([]byte{}, unit...), long...)))
+	frame, err := stream.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(frame, long) {
+		t.Fatalf("stream frame = %d bytes, want the long frame", len(frame))
+	}
+	if _, err = stream.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("stream err = %v", err)
+	}
+}
@@ -0,0 +1,468 @@
+package dlt698
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultClientTimeout ctx未设置截止时间时单个请求的默认超时时间
+const DefaultClientTimeout = 10 * time.Second
+
+const piidServiceMask byte = 0x3F //PIID的服务序号 bit0~bit5
+
+var (
+	ErrClientClosed    = errors.New("client closed")
+	ErrPiidExhausted   = errors.New("client: no free piid")
+	ErrUnexpectedReply = errors.New("client: unexpected response")
+)
+
+// Client 主站客户机会话
+// 自动分配PIID，按APDU类型和PIID服务序号匹配请求与响应，支持多个请求同时等待响应，
+// 同时等待的请求数不超过协商的接收窗口尺寸(未建立应用连接前为1)
+type Client struct {
+	Timeout      time.Duration //ctx未设置截止时间时单个请求的超时时间(包括读取后续帧)，为0时使用DefaultClientTimeout，须在发起请求前设置
+	NextMaxPages int           //读取后续帧时最多帧数，为0时使用DefaultNextMaxPages
+	NextMaxBytes int           //读取后续帧时最多字节数，为0时使用DefaultNextMaxBytes
+
+	conn        net.Conn
+	address     string
+	ca          byte
+	decoder     *StreamDecoder
+	reassembler *Reassembler
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	piid    byte
+	pending map[byte]*clientCall
+	window  chan struct{}
+	handler func(p *ProtocolDlt698Model)
+	err     error
+
+	negotiated *Negotiated
+	done       chan struct{}
+}
+
+type clientCall struct {
+	mark   string
+	next   bool //读取请求，响应可能为GetResponseNext
+	reply  chan *APDU
+	window chan struct{}
+}
+
+// Dial 连接终端并创建客户机会话
+// address 服务器地址SA
+// ca 客户机地址
+func Dial(ctx context.Context, network string, addr string, address string, ca byte) (*Client, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn, address, ca), nil
+}
+
+// NewClient 使用已建立的连接(如监听时accept得到的连接)创建客户机会话
+// address 服务器地址SA
+// ca 客户机地址
+func NewClient(conn net.Conn, address string, ca byte) *Client {
+	c := &Client{
+		conn:        conn,
+		address:     address,
+		ca:          ca,
+		decoder:     NewStreamDecoder(conn),
+		reassembler: NewReassembler(),
+		pending:     make(map[byte]*clientCall),
+		window:      make(chan struct{}, 1),
+		done:        make(chan struct{}),
+	}
+	go c.readLoop()
+	return c
+}
+
+// HandleUnsolicited 设置非请求响应报文(如主动上报、链路请求)的处理函数
+// 处理函数在读取协程中执行，不应长时间阻塞
+func (c *Client) HandleUnsolicited(handler func(p *ProtocolDlt698Model)) {
+	c.mu.Lock()
+	c.handler = handler
+	c.mu.Unlock()
+}
+
+// Close 关闭连接，所有等待中的请求返回ErrClientClosed
+func (c *Client) Close() error {
+	err := c.conn.Close()
+	c.shutdown(ErrClientClosed)
+	return err
+}
+
+// Address 服务器地址
+func (c *Client) Address() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.address
+}
+
+func (c *Client) setAddress(address string) {
+	c.mu.Lock()
+	c.address = address
+	c.mu.Unlock()
+}
+
+// Done 会话结束时关闭
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err 会话结束的原因
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// SetWindowSize 设置同时等待响应的请求数上限，Connect成功后会自动设置为协商的窗口尺寸
+func (c *Client) SetWindowSize(size int) {
+	if size < 1 {
+		size = 1
+	}
+	if size > int(piidServiceMask)+1 {
+		size = int(piidServiceMask) + 1
+	}
+	c.mu.Lock()
+	c.window = make(chan struct{}, size)
+	c.mu.Unlock()
+}
+
+/*----------------------------------请求服务-----------------------------------*/
+
+// Connect 建立应用连接，成功后按协商的窗口尺寸(客户机接收窗口尺寸与服务器接收窗口尺寸中较小的值)限制同时等待的请求数
+func (c *Client) Connect(ctx context.Context, connectRequest *ConnectRequest) (*ConnectResponse, error) {
+	apdu, err := c.Request(ctx, connectRequest, nil)
+	if err != nil {
+		return nil, err
+	}
+	response := apdu.Data.(*ConnectResponse)
+	negotiated, err := Negotiate(connectRequest, response)
+	if err != nil {
+		return response, err
+	}
+	c.mu.Lock()
+	c.negotiated = negotiated
+	c.mu.Unlock()
+	c.SetWindowSize(int(negotiated.WindowSize))
+	return response, nil
+}
+
+// Associate 使用认证生成认证请求对象并建立应用连接，成功后校验服务器返回的认证附加信息
+func (c *Client) Associate(ctx context.Context, connectRequest *ConnectRequest, auth Authenticator) (*Negotiated, error) {
+	mechanism, err := auth.Mechanism()
+	if err != nil {
+		return nil, err
+	}
+	connectRequest.ConnectMechanismInfo = mechanism
+	response, err := c.Connect(ctx, connectRequest)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Verify(response.ConnectResponseInfo); err != nil {
+		return nil, err
+	}
+	return c.Negotiated(), nil
+}
+
+// Negotiated 协商后的应用连接参数，未建立应用连接时返回nil
+func (c *Client) Negotiated() *Negotiated {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.negotiated
+}
+
+// Release 断开应用连接，连接本身不会关闭
+func (c *Client) Release(ctx context.Context) error {
+	apdu, err := c.Request(ctx, &ReleaseRequest{}, nil)
+	if err != nil {
+		return err
+	}
+	if result := apdu.Data.(*ReleaseResponse).Result; result != 0 {
+		return errors.New("release response result: " + hex.EncodeToString([]byte{result}))
+	}
+	return nil
+}
+
+// Get 读取一个对象属性，结果为DAR时返回附带OAD的错误
+func (c *Client) Get(ctx context.Context, oad []byte) (DataInter, error) {
+	if len(oad) != 4 {
+		return nil, errors.New("oad length is not 4")
+	}
+	apdu, err := c.Request(ctx, &GetRequestNormal{OAD: oad}, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := apdu.Data.(*GetResponseNormal).ResultNormal
+	if result == nil || result.GetResult == nil {
+		return nil, ErrUnexpectedReply
+	}
+	if err := result.Err(); err != nil {
+		return nil, err
+	}
+	return result.GetResult.Data, nil
+}
+
+// GetList 读取若干个对象属性，每个属性的结果通过ResultNormal.Err()判断
+func (c *Client) GetList(ctx context.Context, oad ...[]byte) ([]*ResultNormal, error) {
+	for i := 0; i < len(oad); i++ {
+		if len(oad[i]) != 4 {
+			return nil, errors.New("oad length is not 4")
+		}
+	}
+	apdu, err := c.Request(ctx, &GetRequestNormalList{OADs: oad}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return apdu.Data.(*GetResponseNormalList).ResultNormals, nil
+}
+
+// GetRecord 读取一个记录型对象属性，结果为DAR时返回附带OAD的错误
+func (c *Client) GetRecord(ctx context.Context, oad []byte, selector Selector, rcsd *RCSD) (*ResultRecord, error) {
+	if len(oad) != 4 {
+		return nil, errors.New("oad length is not 4")
+	}
+	request := &GetRequestRecord{GetRecord: &GetRecord{OAD: oad, Rsd: &RSD{Selector: selector}, Rcsd: rcsd}}
+	apdu, err := c.Request(ctx, request, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := apdu.Data.(*GetResponseRecord).ResultRecord
+	if result == nil {
+		return nil, ErrUnexpectedReply
+	}
+	return result, result.Err()
+}
+
+// Set 设置一个对象属性
+func (c *Client) Set(ctx context.Context, oad []byte, data DataInter) error {
+	if len(oad) != 4 {
+		return errors.New("oad length is not 4")
+	}
+	apdu, err := c.Request(ctx, &SetRequestNormal{Oad: oad, Data: data}, nil)
+	if err != nil {
+		return err
+	}
+	return apdu.Data.(*SetResponseNormal).Err()
+}
+
+// Action 操作一个对象方法，返回操作返回数据
+func (c *Client) Action(ctx context.Context, omd *OMD, data DataInter) (DataInter, error) {
+	apdu, err := c.Request(ctx, &ActionRequestNormal{OMD: omd, Data: data}, nil)
+	if err != nil {
+		return nil, err
+	}
+	response := apdu.Data.(*ActionResponseNormal)
+	if err := response.Err(); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// Request 发送一个客户机请求并等待对应的响应
+// 响应APDU的类型一定与请求匹配，服务器回复异常响应时返回ErrorResponse.Err()
+// 读取请求的响应为GetResponseNext时自动请求后续帧，合并后转换为与请求对应的响应类型
+func (c *Client) Request(ctx context.Context, request APDURegion, timeTag *TimeTag) (*APDU, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		timeout := c.Timeout
+		if timeout <= 0 {
+			timeout = DefaultClientTimeout
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	apdu, err := c.exchange(ctx, request, timeTag)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := apdu.Data.(*GetResponseNext); ok {
+		if _, isNext := request.(*GetRequestNext); !isNext {
+			return c.readNext(ctx, request, apdu, timeTag)
+		}
+	}
+	return apdu, nil
+}
+
+// exchange 发送一个请求并等待一个响应
+func (c *Client) exchange(ctx context.Context, request APDURegion, timeTag *TimeTag) (*APDU, error) {
+	mark := responseMark(request)
+	if mark == "" {
+		return nil, errors.New("client: " + request.APDUMark() + " has no response")
+	}
+	call, piid, err := c.begin(ctx, mark, strings.HasPrefix(request.APDUType(), "05"))
+	if err != nil {
+		return nil, err
+	}
+	defer c.finish(piid, call)
+	model := &ProtocolDlt698Model{
+		Control: &ControlRegion{Dir: "0", Prm: "1", Framing: "0", Sc: "0", Func: "011"},
+		Address: &AddressRegion{AddressType: 0, Address: c.Address(), CA: c.ca},
+		Data:    &APDU{Pid: piid, Data: request, TimeTag: timeTag},
+	}
+	frame, err := model.Encoder()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.write(ctx, frame); err != nil {
+		return nil, err
+	}
+	select {
+	case apdu := <-call.reply:
+		if e, ok := apdu.Data.(*ErrorResponse); ok {
+			return apdu, e.Err()
+		}
+		return apdu, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.done:
+		return nil, c.Err()
+	}
+}
+
+// begin 占用一个窗口并分配PIID
+func (c *Client) begin(ctx context.Context, mark string, next bool) (*clientCall, byte, error) {
+	c.mu.Lock()
+	window := c.window
+	c.mu.Unlock()
+	select {
+	case window <- struct{}{}:
+	case <-ctx.Done():
+		return nil, 0, ctx.Err()
+	case <-c.done:
+		return nil, 0, c.Err()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		<-window
+		return nil, 0, c.err
+	}
+	for i := 0; i <= int(piidServiceMask); i++ {
+		piid := c.piid
+		c.piid = (c.piid + 1) & piidServiceMask
+		if _, ok := c.pending[piid]; !ok {
+			call := &clientCall{mark: mark, next: next, reply: make(chan *APDU, 1), window: window}
+			c.pending[piid] = call
+			return call, piid, nil
+		}
+	}
+	<-window
+	return nil, 0, ErrPiidExhausted
+}
+
+func (c *Client) finish(piid byte, call *clientCall) {
+	c.mu.Lock()
+	if c.pending[piid] == call {
+		delete(c.pending, piid)
+	}
+	c.mu.Unlock()
+	<-call.window
+}
+
+func (c *Client) write(ctx context.Context, frame []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	deadline, _ := ctx.Deadline()
+	if err := c.conn.SetWriteDeadline(deadline); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+/*----------------------------------接收-----------------------------------*/
+
+func (c *Client) readLoop() {
+	for {
+		frame, err := c.decoder.Next()
+		if err != nil {
+			if err == io.EOF {
+				err = ErrClientClosed
+			}
+			c.shutdown(err)
+			return
+		}
+		model := &ProtocolDlt698Model{}
+		if err := model.DecodeByBytes(frame); err != nil {
+			continue
+		}
+		if model.Segment != nil {
+			confirm, apdu, err := c.reassembler.Push(model)
+			if err != nil {
+				continue
+			}
+			if confirm != nil {
+				_ = c.write(context.Background(), confirm)
+			}
+			if apdu == nil {
+				continue
+			}
+			model.Data = apdu
+			model.Segment = nil
+		}
+		c.dispatch(model)
+	}
+}
+
+func (c *Client) dispatch(model *ProtocolDlt698Model) {
+	apdu := model.Data
+	c.mu.Lock()
+	handler := c.handler
+	call, ok := c.pending[apdu.Pid&piidServiceMask]
+	if ok {
+		mark := apdu.Data.APDUMark()
+		if _, isError := apdu.Data.(*ErrorResponse); isError || mark == call.mark || (call.next && mark == "get_response_next") {
+			delete(c.pending, apdu.Pid&piidServiceMask)
+			c.mu.Unlock()
+			call.reply <- apdu
+			return
+		}
+	}
+	c.mu.Unlock()
+	if handler != nil {
+		handler(model)
+	}
+}
+
+func (c *Client) shutdown(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	close(c.done)
+}
+
+// responseMark 请求对应的响应APDU类型，响应的类型标识为请求类型标识最高位置1
+func responseMark(request APDURegion) string {
+	ident, err := hex.DecodeString(request.APDUType())
+	if err != nil || len(ident) == 0 || ident[0]&0x80 != 0 {
+		return ""
+	}
+	ident[0] |= 0x80
+	if response := translate(ident...); response != nil {
+		return response.APDUMark()
+	}
+	return ""
+}
+
+// ConnectError 建立应用连接被拒绝
+type ConnectError struct {
+	Result byte //认证结果 1-密码错误 2-对称解密错误 3-非对称解密错误 4-签名错误 5-协议版本不匹配 6-ESAM通信故障 255-其他错误
+}
+
+func (e *ConnectError) Error() string {
+	return "connect refused, result: " + hex.EncodeToString([]byte{e.Result})
+}
@@ -0,0 +1,409 @@
+package dlt698
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// 报文分析输出
+// Dump 将报文解析为带注释的树形文本，显示控制域各位的含义、地址类型、HCS/FCS是否正确、APDU类型、PIID、
+// 每个OAD的名称、每个数据的类型标签及值、时间标签及跟随上报信息域；解析失败时输出已解析的部分并指出失败的字节偏移
+
+var oadNamer func(oad []byte) string
+
+// RegisterOADNamer 设置Dump中OAD名称的查找函数，导入dlt698/dict包时自动设置
+func RegisterOADNamer(namer func(oad []byte) string) {
+	oadNamer = namer
+}
+
+var dataTypeNames = map[byte]string{
+	NullIdent:               "null",
+	ArrayIdent:              "array",
+	StructureIdent:          "structure",
+	BooleanIdent:            "bool",
+	BitStringIdent:          "bit-string",
+	DoubleLongIdent:         "double-long",
+	DoubleLongUnsignedIdent: "double-long-unsigned",
+	OctetStringIdent:        "octet-string",
+	VisibleStringIdent:      "visible-string",
+	UTF8StringIdent:         "UTF8-string",
+	BCDIdent:                "bcd",
+	IntegerIdent:            "integer",
+	LongIdent:               "long",
+	UnsignedIdent:           "unsigned",
+	LongUnsignedIdent:       "long-unsigned",
+	Long64Ident:             "long64",
+	Long64UnsignedIdent:     "long64-unsigned",
+	EnumIdent:               "enum",
+	Double32Ident:           "float32",
+	Double64Ident:           "float64",
+	DataTimeIdent:           "date_time",
+	DateIdent:               "date",
+	TimeIdent:               "time",
+	DateTimesIdent:          "date_time_s",
+	OIIdent:                 "OI",
+	OADIdent:                "OAD",
+	ROADIdent:               "ROAD",
+	OMDIdent:                "OMD",
+	TIIdent:                 "TI",
+	TSAIdent:                "TSA",
+	MACIdent:                "MAC",
+	RNIdent:                 "RN",
+	RegionIdent:             "Region",
+	ScalerUnitIdent:         "Scaler_Unit",
+	RSDIdent:                "RSD",
+	CSDIdent:                "CSD",
+	MSIdent:                 "MS",
+	SIDIdent:                "SID",
+	SIDMACIdent:             "SID_MAC",
+	COMDCBIdent:             "COMDCB",
+	RCSDIdent:               "RCSD",
+}
+
+var addressTypeNames = []string{"单地址", "通配地址", "组地址", "广播地址"}
+
+var funcNames = map[string]string{
+	"001": "链路管理",
+	"011": "用户数据",
+}
+
+var dataInterType = reflect.TypeOf((*DataInter)(nil)).Elem()
+
+type dumper struct {
+	sb strings.Builder
+}
+
+func (d *dumper) line(depth int, format string, args ...interface{}) {
+	d.sb.WriteString(strings.Repeat("  ", depth))
+	d.sb.WriteString(fmt.Sprintf(format, args...))
+	d.sb.WriteByte('\n')
+}
+
+// failed 输出解析失败的位置及剩余字节
+func (d *dumper) failed(depth int, offset int, rest []byte, err error) string {
+	d.line(depth, "!! 解析失败 @%d: %s", offset, err.Error())
+	if len(rest) > 0 {
+		d.line(depth, "!! 未解析: % X", rest)
+	}
+	return d.sb.String()
+}
+
+// Dump 解析报文并输出树形文本
+func Dump(frame []byte) string {
+	d := &dumper{}
+	p := &ProtocolDlt698Model{}
+	buf := bytes.NewReader(frame)
+	offset := func() int {
+		return len(frame) - buf.Len()
+	}
+	d.line(0, "报文 %d字节", len(frame))
+	if start, err := buf.ReadByte(); err != nil || start != StartChar {
+		return d.failed(1, 0, frame, errors.New("start char != 0x68"))
+	}
+	d.line(1, "[0] 起始字符 68")
+	if err := binary.Read(buf, binary.LittleEndian, &p.Length); err != nil {
+		return d.failed(1, offset(), frame[offset():], err)
+	}
+	d.line(1, "[1] 长度域 %d", p.Length&frameLengthMask)
+	control := frame[offset():]
+	p.Control = &ControlRegion{}
+	if err := p.Control.decoder(buf); err != nil {
+		return d.failed(1, offset(), frame[offset():], err)
+	}
+	d.line(1, "[3] 控制域 %02X", control[0])
+	d.dumpControl(2, p.Control)
+	addressOffset := offset()
+	p.Address = &AddressRegion{}
+	if err := p.Address.decoder(buf); err != nil {
+		return d.failed(1, addressOffset, frame[addressOffset:], err)
+	}
+	d.line(1, "[%d] 地址域 % X", addressOffset, frame[addressOffset:offset()])
+	d.line(2, "地址类型 %d %s", p.Address.AddressType, addressTypeNames[p.Address.AddressType&3])
+	d.line(2, "逻辑地址 %d", p.Address.LogicAddress)
+	d.line(2, "服务器地址SA %s", p.Address.Address)
+	d.line(2, "客户机地址CA %02X", p.Address.CA)
+	hcsOffset := offset()
+	hcs := make([]byte, 2)
+	if _, err := buf.Read(hcs); err != nil || hcsOffset+2 > len(frame) {
+		return d.failed(1, hcsOffset, frame[hcsOffset:], errors.New("hcs missing"))
+	}
+	d.line(1, "[%d] HCS % X %s", hcsOffset, hcs, checkResult(hcs, p.Cs(frame[1:hcsOffset])))
+	apduOffset := offset()
+	apduEnd := int(p.Length&frameLengthMask) + 1 - 2
+	if apduEnd < apduOffset || apduEnd+3 > len(frame) {
+		return d.failed(1, apduOffset, frame[apduOffset:], errors.New("frame length "+strconv.Itoa(int(p.Length&frameLengthMask))+" exceeds "+strconv.Itoa(len(frame))+" bytes"))
+	}
+	apduArray := append([]byte{}, frame[apduOffset:apduEnd]...)
+	fcs := frame[apduEnd : apduEnd+2]
+	if p.Control.Sc == "1" {
+		for index, value := range apduArray {
+			apduArray[index] = value - ScCode
+		}
+	}
+	if p.Control.Framing == "1" {
+		d.line(1, "[%d] 分帧 %d字节", apduOffset, len(apduArray))
+		segment := &SegmentRegion{}
+		apduBuf := bytes.NewReader(apduArray)
+		err := segment.decoder(apduBuf)
+		d.dumpValue(2, "", reflect.ValueOf(segment))
+		if err != nil {
+			return d.failed(2, apduOffset+len(apduArray)-apduBuf.Len(), apduArray[len(apduArray)-apduBuf.Len():], err)
+		}
+	} else if !d.dumpAPDU(1, apduOffset, apduArray) {
+		return d.sb.String()
+	}
+	d.line(1, "[%d] FCS % X %s", apduEnd, fcs, checkResult(fcs, p.Cs(frame[1:apduEnd])))
+	if frame[apduEnd+2] != EndChar {
+		return d.failed(1, apduEnd+2, frame[apduEnd+2:], errors.New("end char != 0x16"))
+	}
+	d.line(1, "[%d] 结束字符 16", apduEnd+2)
+	if apduEnd+3 < len(frame) {
+		d.line(1, "!! 多余字节: % X", frame[apduEnd+3:])
+	}
+	return d.sb.String()
+}
+
+// Tree 输出已解析模型的树形文本，不包括HCS/FCS
+func (p *ProtocolDlt698Model) Tree() string {
+	d := &dumper{}
+	if p.Control != nil {
+		d.line(0, "控制域")
+		d.dumpControl(1, p.Control)
+	}
+	if p.Address != nil {
+		d.line(0, "地址域")
+		d.line(1, "地址类型 %d %s", p.Address.AddressType, addressTypeNames[p.Address.AddressType&3])
+		d.line(1, "逻辑地址 %d", p.Address.LogicAddress)
+		d.line(1, "服务器地址SA %s", p.Address.Address)
+		d.line(1, "客户机地址CA %02X", p.Address.CA)
+	}
+	if p.Segment != nil {
+		d.line(0, "分帧")
+		d.dumpValue(1, "", reflect.ValueOf(p.Segment))
+	}
+	if p.Data != nil {
+		d.dumpAPDUModel(0, p.Data)
+	}
+	return d.sb.String()
+}
+
+func (d *dumper) dumpControl(depth int, c *ControlRegion) {
+	dir := map[string]string{"0": "客户机发出", "1": "服务器发出"}
+	prm := map[string]string{"0": "服务器发起", "1": "客户机发起"}
+	yes := map[string]string{"0": "否", "1": "是"}
+	d.line(depth, "DIR=%s %s", c.Dir, dir[c.Dir])
+	d.line(depth, "PRM=%s %s", c.Prm, prm[c.Prm])
+	d.line(depth, "分帧标志=%s %s", c.Framing, yes[c.Framing])
+	d.line(depth, "扰码标志=%s %s", c.Sc, yes[c.Sc])
+	name, ok := funcNames[c.Func]
+	if !ok {
+		name = "保留"
+	}
+	d.line(depth, "功能码=%s %s", c.Func, name)
+}
+
+// dumpAPDU 解析并输出APDU，解析失败时返回false
+func (d *dumper) dumpAPDU(depth int, offset int, apduArray []byte) bool {
+	d.line(depth, "[%d] APDU %d字节", offset, len(apduArray))
+	apdu := &APDU{}
+	buf := bytes.NewReader(apduArray)
+	err := apdu.decoder(buf)
+	d.dumpAPDUModel(depth+1, apdu)
+	if err != nil {
+		d.failed(depth+1, offset+len(apduArray)-buf.Len(), apduArray[len(apduArray)-buf.Len():], err)
+		return false
+	}
+	if buf.Len() > 0 {
+		d.line(depth+1, "!! APDU多余字节: % X", apduArray[len(apduArray)-buf.Len():])
+	}
+	return true
+}
+
+func (d *dumper) dumpAPDUModel(depth int, apdu *APDU) {
+	if apdu.Data == nil {
+		return
+	}
+	d.line(depth, "%s(%s) %s", reflect.Indirect(reflect.ValueOf(apdu.Data)).Type().Name(), apdu.Data.APDUType(), apdu.Data.APDUMark())
+	if _, ok := apdu.Data.(securityRegion); !ok {
+		d.line(depth+1, "PIID %02X 优先级=%d 服务序号=%d", apdu.Pid, apdu.Pid>>7, apdu.Pid&piidServiceMask)
+	}
+	d.dumpFields(depth+1, reflect.ValueOf(apdu.Data))
+	if apdu.FollowReport != nil {
+		d.line(depth, "跟随上报信息域")
+		for i, normal := range apdu.FollowReport.aResultNormal {
+			d.dumpValue(depth+1, fmt.Sprintf("[%d]", i), reflect.ValueOf(normal))
+		}
+		for i, record := range apdu.FollowReport.aResultRecord {
+			d.dumpValue(depth+1, fmt.Sprintf("[%d]", i), reflect.ValueOf(record))
+		}
+	}
+	if apdu.TimeTag != nil {
+		d.dumpValue(depth, "时间标签", reflect.ValueOf(apdu.TimeTag))
+	}
+}
+
+// dumpFields 输出结构体的导出字段
+func (d *dumper) dumpFields(depth int, v reflect.Value) {
+	v = reflect.Indirect(v)
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		if field.Anonymous {
+			d.dumpFields(depth, v.Field(i))
+			continue
+		}
+		d.dumpValue(depth, field.Name, v.Field(i))
+	}
+}
+
+func (d *dumper) dumpValue(depth int, name string, v reflect.Value) {
+	label := name
+	if label != "" {
+		label += " "
+	}
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface || v.Kind() == reflect.Slice) && v.IsNil() {
+		d.line(depth, "%s-", label)
+		return
+	}
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if apdu, ok := v.Interface().(*APDU); ok {
+		d.line(depth, "%s", name)
+		d.dumpAPDUModel(depth+1, apdu)
+		return
+	}
+	if v.Type().Implements(dataInterType) && isData(v.Interface().(DataInter)) {
+		d.dumpData(depth, label, v.Interface().(DataInter))
+		return
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			d.line(depth, "%s%s", label, describeBytes(name, v.Bytes()))
+			return
+		}
+		d.line(depth, "%s(%d)", label, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			d.dumpValue(depth+1, fmt.Sprintf("[%d]", i), v.Index(i))
+		}
+	case reflect.Ptr, reflect.Struct:
+		if s, ok := v.Interface().(fmt.Stringer); ok {
+			d.line(depth, "%s%s", label, s.String())
+			return
+		}
+		if name == "" {
+			name = reflect.Indirect(v).Type().Name()
+		}
+		d.line(depth, "%s", name)
+		d.dumpFields(depth+1, v)
+	default:
+		d.line(depth, "%s%v", label, v.Interface())
+	}
+}
+
+func (d *dumper) dumpData(depth int, label string, data DataInter) {
+	if data == nil || reflect.ValueOf(data).IsNil() {
+		d.line(depth, "%s-", label)
+		return
+	}
+	tag := data.DataType()
+	typeName, ok := dataTypeNames[tag]
+	if !ok {
+		typeName = reflect.Indirect(reflect.ValueOf(data)).Type().Name()
+	}
+	switch value := data.(type) {
+	case *Array:
+		d.line(depth, "%s%s(%d) [%d]", label, typeName, tag, len(value.DataArray))
+		for i, element := range value.DataArray {
+			d.dumpData(depth+1, fmt.Sprintf("[%d] ", i), element)
+		}
+	case *Structure:
+		d.line(depth, "%s%s(%d) [%d]", label, typeName, tag, len(value.DataArray))
+		for i, element := range value.DataArray {
+			d.dumpData(depth+1, fmt.Sprintf("[%d] ", i), element)
+		}
+	case *OAD:
+		d.line(depth, "%s%s(%d) %s", label, typeName, tag, describeOAD(value.Data))
+	case *DAR:
+		d.line(depth, "%sDAR %d %s", label, value.Data, value.Data.String())
+	case *DateTimes:
+		d.line(depth, "%s%s(%d) %s", label, typeName, tag, value.Time().Format("2006-01-02 15:04:05"))
+	case *DateTime:
+		d.line(depth, "%s%s(%d) %s", label, typeName, tag, value.Time().Format("2006-01-02 15:04:05.000"))
+	case *MAC:
+		d.line(depth, "%s%s(%d) %s", label, typeName, tag, strings.ToUpper(value.Data))
+	case *RN:
+		d.line(depth, "%s%s(%d) %s", label, typeName, tag, strings.ToUpper(value.Data))
+	case *OI:
+		d.line(depth, "%s%s(%d) %04X", label, typeName, tag, value.Data)
+	case *BitString:
+		d.line(depth, "%s%s(%d) %d位 %s", label, typeName, tag, value.Size, value.Data)
+	case *Null:
+		d.line(depth, "%s%s(%d)", label, typeName, tag)
+	default:
+		v := reflect.Indirect(reflect.ValueOf(data))
+		if v.Kind() == reflect.Struct && v.NumField() == 1 && isScalar(v.Field(0)) {
+			d.line(depth, "%s%s(%d) %v", label, typeName, tag, data.Value())
+			return
+		}
+		d.line(depth, "%s%s(%d)", label, typeName, tag)
+		d.dumpFields(depth+1, v)
+	}
+}
+
+// isData 是否为数据类型或DAR，ResultNormal等结构虽然实现了DataInter，但按字段输出
+func isData(data DataInter) bool {
+	if _, ok := data.(*DAR); ok {
+		return true
+	}
+	instance := dataTranslate(data.DataType())
+	return instance != nil && reflect.TypeOf(instance) == reflect.TypeOf(data)
+}
+
+// isScalar 可以在一行中显示的字段
+func isScalar(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Struct, reflect.Map:
+		return false
+	case reflect.Slice:
+		return v.Type().Elem().Kind() == reflect.Uint8
+	}
+	return true
+}
+
+// describeBytes 字段名为OAD的4字节数组显示对象名称，其它字节数组显示十六进制
+func describeBytes(name string, array []byte) string {
+	if len(array) == 4 && strings.Contains(strings.ToLower(name), "oad") {
+		return describeOAD(array)
+	}
+	return strings.ToUpper(hex.EncodeToString(array))
+}
+
+func describeOAD(oad []byte) string {
+	text := strings.ToUpper(hex.EncodeToString(oad))
+	if oadNamer != nil && len(oad) == 4 {
+		if name := oadNamer(oad); name != "" {
+			text += " " + name
+		}
+	}
+	return text
+}
+
+func checkResult(actual []byte, expect []byte) string {
+	if bytes.Equal(actual, expect) {
+		return "正确"
+	}
+	return fmt.Sprintf("错误(应为 % X)", expect)
+}
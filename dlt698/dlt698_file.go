@@ -0,0 +1,487 @@
+package dlt698
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 文件分块传输管理F001
+const (
+	FileBlockOI uint16 = 0xF001
+
+	FileMethodStart   byte = 7  //启动传输
+	FileMethodWrite   byte = 8  //写文件
+	FileMethodRead    byte = 9  //读文件
+	FileMethodCompare byte = 10 //软件比对
+
+	FileAttributeInfo   byte = 2 //文件信息
+	FileAttributeResult byte = 3 //命令结果
+	FileAttributeStatus byte = 4 //传输块状态字
+)
+
+// 文件校验类型
+const (
+	FileChecksumCRC  byte = 0 //CRC校验，与帧校验相同的CRC16
+	FileChecksumMD5  byte = 1 //md5校验
+	FileChecksumSHA1 byte = 2 //SHA1校验
+)
+
+// 传输块大小
+const (
+	DefaultFileBlockSize = 512
+	fileBlockOverhead    = 64 //写文件请求中块数据以外的帧及APDU长度
+	fileMaxRounds        = 3  //补传缺失块的最多轮数
+	fileMaxBlockSize     = 0xFFFF
+	fileMaxBlocks        = 0x10000 //块序号为long-unsigned
+)
+
+var (
+	ErrFileIncomplete = errors.New("file transfer: blocks still missing after retries")
+	ErrFileChecksum   = errors.New("file transfer: checksum mismatch")
+	ErrFileTooLarge   = errors.New("file transfer: more than 65536 blocks")
+	ErrFileBlockSize  = errors.New("file transfer: resume requires BlockSize")
+)
+
+// FileInfo 文件信息
+type FileInfo struct {
+	Source    string //源文件
+	Target    string //目标文件
+	Size      uint32 //文件大小
+	Attribute string //文件属性，bit-string(SIZE(3))，如"110"表示可读可写
+	Version   string //文件版本
+	Category  uint8  //文件类别
+}
+
+func (f *FileInfo) data() DataInter {
+	attribute := f.Attribute
+	if attribute == "" {
+		attribute = "110"
+	}
+	return &Structure{DataArray: []DataInter{
+		&VisibleString{Data: f.Source},
+		&VisibleString{Data: f.Target},
+		&DoubleLongUnsigned{Data: f.Size},
+		&BitString{Size: 3, Data: attribute},
+		&VisibleString{Data: f.Version},
+		&Enum{Data: f.Category},
+	}}
+}
+
+func parseFileInfo(data DataInter) (*FileInfo, error) {
+	s, ok := data.(*Structure)
+	if !ok || len(s.DataArray) != 6 {
+		return nil, errors.New("file transfer: invalid file info")
+	}
+	source, ok1 := s.DataArray[0].(*VisibleString)
+	target, ok2 := s.DataArray[1].(*VisibleString)
+	size, ok3 := s.DataArray[2].(*DoubleLongUnsigned)
+	attribute, ok4 := s.DataArray[3].(*BitString)
+	version, ok5 := s.DataArray[4].(*VisibleString)
+	category, ok6 := s.DataArray[5].(*Enum)
+	if !ok1 || !ok2 || !ok3 || !ok4 || !ok5 || !ok6 {
+		return nil, errors.New("file transfer: invalid file info")
+	}
+	return &FileInfo{
+		Source:    source.Data,
+		Target:    target.Data,
+		Size:      size.Data,
+		Attribute: attribute.Data,
+		Version:   version.Data,
+		Category:  category.Data,
+	}, nil
+}
+
+// FileChecksum 计算文件校验值
+func FileChecksum(checksumType byte, data []byte) ([]byte, error) {
+	switch checksumType {
+	case FileChecksumCRC:
+		return (&ProtocolDlt698Model{}).Cs(data), nil
+	case FileChecksumMD5:
+		sum := md5.Sum(data)
+		return sum[:], nil
+	case FileChecksumSHA1:
+		sum := sha1.Sum(data)
+		return sum[:], nil
+	}
+	return nil, errors.New("file transfer: unsupported checksum type " + strconv.Itoa(int(checksumType)))
+}
+
+func fileBlocks(size int, blockSize int) int {
+	return (size + blockSize - 1) / blockSize
+}
+
+/*----------------------------------发送端-----------------------------------*/
+
+// FileTransfer 通过客户机会话向服务器分块传输文件
+// 启动传输后按块写文件，每轮写完后读取传输块状态字补传缺失的块；连接中断后设置Resume重新调用Send，只传输缺失的块
+type FileTransfer struct {
+	Client       *Client
+	Info         FileInfo
+	BlockSize    int                       //传输块大小，为0时根据协商的APDU尺寸计算，未协商时使用DefaultFileBlockSize；启动传输后保存实际使用的大小
+	ChecksumType byte                      //校验类型
+	Resume       bool                      //不启动传输，直接按传输块状态字续传，BlockSize必须与启动传输时相同
+	Progress     func(done int, total int) //每写完一块调用一次，done为已确认及已写入的块数
+}
+
+// blockSize 传输块大小，不超过协商的发送帧尺寸及可处理APDU尺寸
+func (f *FileTransfer) blockSize() int {
+	size := f.BlockSize
+	limit := 0
+	if negotiated := f.Client.Negotiated(); negotiated != nil {
+		limit = int(minSize(negotiated.MaxAPDUSize, negotiated.SendMaxSize)) - fileBlockOverhead
+	}
+	if size <= 0 {
+		size = DefaultFileBlockSize
+		if limit > 0 {
+			size = limit
+		}
+	}
+	if limit > 0 && size > limit {
+		size = limit
+	}
+	if size > fileMaxBlockSize {
+		size = fileMaxBlockSize
+	}
+	if size <= 0 {
+		size = 1
+	}
+	return size
+}
+
+// transferBlocks 本次传输的块大小及块数，续传时使用启动传输时的块大小
+func (f *FileTransfer) transferBlocks(size int) (int, int, error) {
+	blockSize := f.blockSize()
+	if f.Resume {
+		if f.BlockSize <= 0 || f.BlockSize > fileMaxBlockSize {
+			return 0, 0, ErrFileBlockSize
+		}
+		blockSize = f.BlockSize
+	}
+	total := fileBlocks(size, blockSize)
+	if total > fileMaxBlocks {
+		return 0, 0, ErrFileTooLarge
+	}
+	return blockSize, total, nil
+}
+
+// Send 传输文件，文件大小为len(data)
+func (f *FileTransfer) Send(ctx context.Context, data []byte) error {
+	blockSize, total, err := f.transferBlocks(len(data))
+	if err != nil {
+		return err
+	}
+	f.Info.Size = uint32(len(data))
+	missing := make([]int, total)
+	for i := range missing {
+		missing[i] = i
+	}
+	if f.Resume {
+		status, err := f.Status(ctx)
+		if err != nil {
+			return err
+		}
+		missing = missingBlocks(status, total)
+	} else {
+		if err = f.start(ctx, data, blockSize); err != nil {
+			return err
+		}
+		f.BlockSize = blockSize
+	}
+	for round := 0; len(missing) > 0; round++ {
+		if round == fileMaxRounds {
+			return ErrFileIncomplete
+		}
+		done := total - len(missing)
+		for _, index := range missing {
+			end := (index + 1) * blockSize
+			if end > len(data) {
+				end = len(data)
+			}
+			if err := f.WriteBlock(ctx, index, data[index*blockSize:end]); err != nil {
+				return err
+			}
+			done++
+			if f.Progress != nil {
+				f.Progress(done, total)
+			}
+		}
+		status, err := f.Status(ctx)
+		if err != nil {
+			return err
+		}
+		missing = missingBlocks(status, total)
+	}
+	return nil
+}
+
+func (f *FileTransfer) start(ctx context.Context, data []byte, blockSize int) error {
+	checksum, err := FileChecksum(f.ChecksumType, data)
+	if err != nil {
+		return err
+	}
+	parameter := &Structure{DataArray: []DataInter{
+		f.Info.data(),
+		&LongUnsigned{Data: uint16(blockSize)},
+		&Structure{DataArray: []DataInter{
+			&Enum{Data: f.ChecksumType},
+			&OctetString{Data: hex.EncodeToString(checksum)},
+		}},
+	}}
+	_, err = f.Client.Action(ctx, fileOMD(FileMethodStart), parameter)
+	return err
+}
+
+// WriteBlock 写文件，块序号从0开始
+func (f *FileTransfer) WriteBlock(ctx context.Context, index int, block []byte) error {
+	parameter := &Structure{DataArray: []DataInter{
+		&LongUnsigned{Data: uint16(index)},
+		&OctetString{Data: hex.EncodeToString(block)},
+	}}
+	_, err := f.Client.Action(ctx, fileOMD(FileMethodWrite), parameter)
+	return err
+}
+
+// ReadBlock 读文件，块序号从0开始
+func (f *FileTransfer) ReadBlock(ctx context.Context, index int) ([]byte, error) {
+	parameter := &Structure{DataArray: []DataInter{&LongUnsigned{Data: uint16(index)}}}
+	data, err := f.Client.Action(ctx, fileOMD(FileMethodRead), parameter)
+	if err != nil {
+		return nil, err
+	}
+	block, ok := data.(*OctetString)
+	if !ok {
+		return nil, errors.New("file transfer: read block result is not octet-string")
+	}
+	return hex.DecodeString(block.Data)
+}
+
+// Status 读取传输块状态字，第i位为1表示第i块已收到
+func (f *FileTransfer) Status(ctx context.Context) (string, error) {
+	data, err := f.Client.Get(ctx, fileOAD(FileAttributeStatus))
+	if err != nil {
+		return "", err
+	}
+	status, ok := data.(*BitString)
+	if !ok {
+		return "", errors.New("file transfer: status is not bit-string")
+	}
+	return status.Data, nil
+}
+
+// Verify 按块读回文件并与data比较校验值
+func (f *FileTransfer) Verify(ctx context.Context, data []byte) error {
+	_, total, err := f.transferBlocks(len(data))
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for index := 0; index < total; index++ {
+		block, err := f.ReadBlock(ctx, index)
+		if err != nil {
+			return err
+		}
+		buf.Write(block)
+	}
+	expect, err := FileChecksum(f.ChecksumType, data)
+	if err != nil {
+		return err
+	}
+	actual, err := FileChecksum(f.ChecksumType, buf.Bytes())
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(expect, actual) {
+		return ErrFileChecksum
+	}
+	return nil
+}
+
+func fileOAD(attribute byte) []byte {
+	oi := FileBlockOI
+	return []byte{byte(oi >> 8), byte(oi), attribute, 0x00}
+}
+
+func fileOMD(method byte) *OMD {
+	return &OMD{Oi: &OI{Data: FileBlockOI}, FuncMark: method, Mode: 0}
+}
+
+// missingBlocks 传输块状态字中未收到的块
+func missingBlocks(status string, total int) []int {
+	var missing []int
+	for i := 0; i < total; i++ {
+		if i >= len(status) || status[i] != '1' {
+			missing = append(missing, i)
+		}
+	}
+	return missing
+}
+
+/*----------------------------------接收端-----------------------------------*/
+
+// FileReceiver 文件分块传输的接收端，用于模拟终端
+// 收到全部块后校验文件，校验通过时调用Complete，校验失败时最后一块的写文件应答DarCompareFailed
+type FileReceiver struct {
+	Complete func(info *FileInfo, data []byte) error //文件接收完成
+
+	mu           sync.Mutex
+	info         *FileInfo
+	blockSize    int
+	checksumType byte
+	checksum     []byte
+	blocks       [][]byte
+	received     []bool
+}
+
+// NewFileReceiver 创建文件接收端
+func NewFileReceiver() *FileReceiver {
+	return &FileReceiver{}
+}
+
+// Register 在服务器上注册F001的启动传输、写文件、读文件方法及文件信息、传输块状态字属性
+func (r *FileReceiver) Register(s *Server) {
+	s.HandleAction(FileBlockOI, FileMethodStart, r.start)
+	s.HandleAction(FileBlockOI, FileMethodWrite, r.write)
+	s.HandleAction(FileBlockOI, FileMethodRead, r.read)
+	s.HandleGet(fileOAD(FileAttributeInfo), func(_ *ServerRequest, _ []byte) (DataInter, error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.info == nil {
+			return &Null{}, nil
+		}
+		return r.info.data(), nil
+	})
+	s.HandleGet(fileOAD(FileAttributeStatus), func(_ *ServerRequest, _ []byte) (DataInter, error) {
+		return r.Status(), nil
+	})
+}
+
+// Status 传输块状态字
+func (r *FileReceiver) Status() *BitString {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sb strings.Builder
+	for _, ok := range r.received {
+		if ok {
+			sb.WriteByte('1')
+		} else {
+			sb.WriteByte('0')
+		}
+	}
+	return &BitString{Size: len(r.received), Data: sb.String()}
+}
+
+func (r *FileReceiver) start(_ *ServerRequest, _ *OMD, data DataInter) (DataInter, error) {
+	s, ok := data.(*Structure)
+	if !ok || len(s.DataArray) != 3 {
+		return nil, DarTypeUnmatched
+	}
+	info, err := parseFileInfo(s.DataArray[0])
+	if err != nil {
+		return nil, DarTypeUnmatched
+	}
+	blockSize, ok := s.DataArray[1].(*LongUnsigned)
+	check, ok2 := s.DataArray[2].(*Structure)
+	if !ok || !ok2 || blockSize.Data == 0 || len(check.DataArray) != 2 {
+		return nil, DarTypeUnmatched
+	}
+	checksumType, ok := check.DataArray[0].(*Enum)
+	checksumValue, ok2 := check.DataArray[1].(*OctetString)
+	if !ok || !ok2 {
+		return nil, DarTypeUnmatched
+	}
+	checksum, err := hex.DecodeString(checksumValue.Data)
+	if err != nil {
+		return nil, DarTypeUnmatched
+	}
+	total := fileBlocks(int(info.Size), int(blockSize.Data))
+	if total > fileMaxBlocks {
+		return nil, DarOutOfRange
+	}
+	r.mu.Lock()
+	r.info = info
+	r.blockSize = int(blockSize.Data)
+	r.checksumType = checksumType.Data
+	r.checksum = checksum
+	r.blocks = make([][]byte, total)
+	r.received = make([]bool, total)
+	r.mu.Unlock()
+	return nil, nil
+}
+
+func (r *FileReceiver) write(_ *ServerRequest, _ *OMD, data DataInter) (DataInter, error) {
+	s, ok := data.(*Structure)
+	if !ok || len(s.DataArray) != 2 {
+		return nil, DarTypeUnmatched
+	}
+	index, ok := s.DataArray[0].(*LongUnsigned)
+	value, ok2 := s.DataArray[1].(*OctetString)
+	if !ok || !ok2 {
+		return nil, DarTypeUnmatched
+	}
+	block, err := hex.DecodeString(value.Data)
+	if err != nil {
+		return nil, DarTypeUnmatched
+	}
+	r.mu.Lock()
+	if r.info == nil {
+		r.mu.Unlock()
+		return nil, DarNoLongWriteInProgress
+	}
+	i := int(index.Data)
+	if i >= len(r.blocks) || len(block) > r.blockSize {
+		r.mu.Unlock()
+		return nil, DarDataBlockNumberInvalid
+	}
+	//已收到的块重复写入时不再触发完成
+	complete := !r.received[i]
+	r.blocks[i] = block
+	r.received[i] = true
+	for _, received := range r.received {
+		complete = complete && received
+	}
+	if !complete {
+		r.mu.Unlock()
+		return nil, nil
+	}
+	file := bytes.Join(r.blocks, nil)
+	info := *r.info
+	checksum, err := FileChecksum(r.checksumType, file)
+	if err != nil || !bytes.Equal(checksum, r.checksum) || len(file) != int(info.Size) {
+		//校验失败时清除状态字，需要重新传输
+		r.received = make([]bool, len(r.received))
+		r.mu.Unlock()
+		return nil, DarCompareFailed
+	}
+	r.mu.Unlock()
+	if r.Complete != nil {
+		if err := r.Complete(&info, file); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (r *FileReceiver) read(_ *ServerRequest, _ *OMD, data DataInter) (DataInter, error) {
+	s, ok := data.(*Structure)
+	if !ok || len(s.DataArray) != 1 {
+		return nil, DarTypeUnmatched
+	}
+	index, ok := s.DataArray[0].(*LongUnsigned)
+	if !ok {
+		return nil, DarTypeUnmatched
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := int(index.Data)
+	if i >= len(r.received) || !r.received[i] {
+		return nil, DarDataBlockNumberInvalid
+	}
+	return &OctetString{Data: hex.EncodeToString(r.blocks[i])}, nil
+}
@@ -0,0 +1,367 @@
+package dlt698
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// JSON序列化
+// 接口类型的字段序列化为 {"type": 类型标识, "value": 具体值}，反序列化时根据类型标识还原具体类型：
+//   APDURegion 使用APDUMark()，如 "get_request_normal"
+//   Selector、MSer 使用选择方法序号，ConnectMechanismInfo 使用认证机制序号
+//   DataInter 等其余接口使用数据类型标签，如 6 表示double-long-unsigned，
+//   DAR、ResultNormal等非A-XDR数据类型使用名称，如 "dar"
+// 反序列化后的模型调用Encoder()可以得到与原报文完全相同的字节
+
+var (
+	apduRegionType           = reflect.TypeOf((*APDURegion)(nil)).Elem()
+	selectorType             = reflect.TypeOf((*Selector)(nil)).Elem()
+	msType                   = reflect.TypeOf((*MSer)(nil)).Elem()
+	connectMechanismInfoType = reflect.TypeOf((*ConnectMechanismInfo)(nil)).Elem()
+	jsonMarshalerType        = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshalerType      = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// namedRegions 没有数据类型标签的类型，使用名称作为类型标识
+var namedRegions = map[string]func() FrameRegion{
+	"dar":           func() FrameRegion { return new(DAR) },
+	"result_normal": func() FrameRegion { return new(ResultNormal) },
+	"result_record": func() FrameRegion { return new(ResultRecord) },
+	"record_row":    func() FrameRegion { return new(RecordRow) },
+	"rn_mac":        func() FrameRegion { return new(RNMAC) },
+}
+
+var (
+	apduMarkOnce sync.Once
+	apduMarkMap  map[string]plugIn
+)
+
+func apduByMark(mark string) APDURegion {
+	apduMarkOnce.Do(func() {
+		apduMarkMap = make(map[string]plugIn, len(apduMap))
+		for _, pi := range apduMap {
+			apduMarkMap[pi().APDUMark()] = pi
+		}
+	})
+	if pi, ok := apduMarkMap[mark]; ok {
+		return pi()
+	}
+	return nil
+}
+
+type protocolDlt698ModelJSON ProtocolDlt698Model
+type apduJSON APDU
+
+func (p *ProtocolDlt698Model) MarshalJSON() ([]byte, error) {
+	return marshalJSON(reflect.ValueOf((*protocolDlt698ModelJSON)(p)))
+}
+
+func (p *ProtocolDlt698Model) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, reflect.ValueOf((*protocolDlt698ModelJSON)(p)).Elem())
+}
+
+func (a *APDU) MarshalJSON() ([]byte, error) {
+	return marshalJSON(reflect.ValueOf((*apduJSON)(a)))
+}
+
+func (a *APDU) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, reflect.ValueOf((*apduJSON)(a)).Elem())
+}
+
+type followReportJSON struct {
+	ResultNormals []*ResultNormal `json:"result_normals"`
+	ResultRecords []*ResultRecord `json:"result_records"`
+}
+
+func (f *FollowReport) MarshalJSON() ([]byte, error) {
+	return marshalJSON(reflect.ValueOf(&followReportJSON{ResultNormals: f.aResultNormal, ResultRecords: f.aResultRecord}))
+}
+
+func (f *FollowReport) UnmarshalJSON(data []byte) error {
+	fr := &followReportJSON{}
+	if err := unmarshalJSON(data, reflect.ValueOf(fr).Elem()); err != nil {
+		return err
+	}
+	f.aResultNormal = fr.ResultNormals
+	f.aResultRecord = fr.ResultRecords
+	return nil
+}
+
+func marshalJSON(v reflect.Value) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := marshalValue(buf, v, true); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func marshalValue(buf *bytes.Buffer, v reflect.Value, root bool) error {
+	if !root {
+		if v.Kind() == reflect.Ptr && !v.IsNil() && v.Type().Implements(jsonMarshalerType) {
+			array, err := v.Interface().(json.Marshaler).MarshalJSON()
+			if err != nil {
+				return err
+			}
+			buf.Write(array)
+			return nil
+		}
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		return marshalValue(buf, v.Elem(), false)
+	case reflect.Interface:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		ident, err := jsonIdent(v.Type(), v.Elem())
+		if err != nil {
+			return err
+		}
+		identArray, _ := json.Marshal(ident)
+		buf.WriteString(`{"type":`)
+		buf.Write(identArray)
+		buf.WriteString(`,"value":`)
+		if err := marshalValue(buf, v.Elem(), false); err != nil {
+			return err
+		}
+		buf.WriteByte('}')
+		return nil
+	case reflect.Struct:
+		buf.WriteByte('{')
+		for i, f := range jsonFields(v.Type()) {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			nameArray, _ := json.Marshal(f.name)
+			buf.Write(nameArray)
+			buf.WriteByte(':')
+			if err := marshalValue(buf, v.FieldByIndex(f.index), false); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := marshalValue(buf, v.Index(i), false); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	}
+	array, err := json.Marshal(v.Interface())
+	if err != nil {
+		return err
+	}
+	buf.Write(array)
+	return nil
+}
+
+func unmarshalJSON(data []byte, v reflect.Value) error {
+	return unmarshalValue(data, v, true)
+}
+
+func unmarshalValue(data []byte, v reflect.Value, root bool) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if !root && v.Kind() == reflect.Ptr && v.Type().Implements(jsonUnmarshalerType) {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return v.Interface().(json.Unmarshaler).UnmarshalJSON(data)
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return unmarshalValue(data, v.Elem(), false)
+	case reflect.Interface:
+		var wrapper struct {
+			Type  json.RawMessage `json:"type"`
+			Value json.RawMessage `json:"value"`
+		}
+		if err := json.Unmarshal(data, &wrapper); err != nil {
+			return errors.New("json decode " + v.Type().Name() + " err: " + err.Error())
+		}
+		region, err := jsonRegion(v.Type(), wrapper.Type)
+		if err != nil {
+			return err
+		}
+		value := reflect.ValueOf(region)
+		if !value.Type().AssignableTo(v.Type()) {
+			return errors.New("json decode " + v.Type().Name() + " err: type " + string(wrapper.Type) + " unmatched")
+		}
+		if len(wrapper.Value) > 0 {
+			if err := unmarshalValue(wrapper.Value, value.Elem(), false); err != nil {
+				return err
+			}
+		}
+		v.Set(value)
+		return nil
+	case reflect.Struct:
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return errors.New("json decode " + v.Type().Name() + " err: " + err.Error())
+		}
+		for _, f := range jsonFields(v.Type()) {
+			if raw, ok := fields[f.name]; ok {
+				if err := unmarshalValue(raw, v.FieldByIndex(f.index), false); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return errors.New("json decode " + v.Type().String() + " err: " + err.Error())
+		}
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), len(items), len(items)))
+		} else if len(items) != v.Len() {
+			return errors.New("json decode " + v.Type().String() + " err: length unmatched")
+		}
+		for i, item := range items {
+			if err := unmarshalValue(item, v.Index(i), false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return json.Unmarshal(data, v.Addr().Interface())
+}
+
+type jsonField struct {
+	name  string
+	index []int
+}
+
+// jsonFields 获取结构体需要序列化的字段，与encoding/json一致展开匿名结构体
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			for _, sub := range jsonFields(f.Type) {
+				fields = append(fields, jsonField{name: sub.name, index: append([]int{i}, sub.index...)})
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, jsonField{name: name, index: []int{i}})
+	}
+	return fields
+}
+
+// jsonIdent 获取接口具体类型的类型标识
+func jsonIdent(t reflect.Type, v reflect.Value) (interface{}, error) {
+	region := v.Interface()
+	switch t {
+	case apduRegionType:
+		return region.(APDURegion).APDUMark(), nil
+	case selectorType:
+		return region.(Selector).GetDataIdent(), nil
+	case msType:
+		return region.(MSer).GetDataIdent(), nil
+	case connectMechanismInfoType:
+		return region.(ConnectMechanismInfo).Sign(), nil
+	}
+	if data, ok := region.(DataInter); ok {
+		if d := dataTranslate(data.DataType()); d != nil && reflect.TypeOf(d) == v.Type() {
+			return data.DataType(), nil
+		}
+	}
+	for name, pi := range namedRegions {
+		if reflect.TypeOf(pi()) == v.Type() {
+			return name, nil
+		}
+	}
+	return nil, errors.New("json encode err: type " + v.Type().String() + " has no ident")
+}
+
+// jsonRegion 根据类型标识创建接口的具体类型
+func jsonRegion(t reflect.Type, ident json.RawMessage) (interface{}, error) {
+	var region interface{}
+	switch t {
+	case apduRegionType:
+		var mark string
+		if err := json.Unmarshal(ident, &mark); err != nil {
+			return nil, errors.New("json decode APDURegion err: " + err.Error())
+		}
+		if apdu := apduByMark(mark); apdu != nil {
+			region = apdu
+		}
+	case selectorType, msType, connectMechanismInfoType:
+		var number byte
+		if err := json.Unmarshal(ident, &number); err != nil {
+			return nil, errors.New("json decode " + t.Name() + " err: " + err.Error())
+		}
+		switch t {
+		case selectorType:
+			if s := newSelector(number); s != nil {
+				region = s
+			}
+		case msType:
+			if m := newMS(number); m != nil {
+				region = m
+			}
+		default:
+			if c := newConnectMechanismInfo(number); c != nil {
+				region = c
+			}
+		}
+	default:
+		var name string
+		var number byte
+		if err := json.Unmarshal(ident, &name); err == nil {
+			if pi, ok := namedRegions[name]; ok {
+				region = pi()
+			}
+		} else if err := json.Unmarshal(ident, &number); err == nil {
+			if d := dataTranslate(number); d != nil {
+				region = d
+			}
+		}
+	}
+	if region == nil {
+		return nil, errors.New("json decode " + t.Name() + " err: unknown type " + string(ident))
+	}
+	return region, nil
+}
@@ -0,0 +1,59 @@
+package dlt698
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+var jsonTests = []struct {
+	name  string
+	frame string
+	apdu  APDURegion
+}{
+	{"GetResponseNormal", "682100c305060504030201016322850101400102000109060102030405060000ad8616", &GetResponseNormal{}},
+	{"GetResponseRecord", "683900c305060504030201018cef85030250040200020020210200000010020001011c07e40101000000010206000003e806000000c800006bb016", &GetResponseRecord{}},
+	{"FollowReportTimeTag", "683300c3050605040302010104f885010340010200010906010203040506010101301d020000060107e401010c000001000f0fa016", &GetResponseNormal{}},
+	{"SecurityRequest", "6829004305060504030201011e5a1000080501044001020000008102000004000000040401020304670416", &SecurityRequest{}},
+	{"SecurityResponse", "682900c30506050403020101c99e90011000112233445566778899aabbccddeeff0100040102030464be16", &SecurityResponse{}},
+	{"ProxyGetRequestRecord", "682e00430506050403020101f8fa0902053c000705000000000001500402000901020020210200000010020000019916", &ProxyGetRequestRecord{}},
+	{"ProxyGetResponseRecord", "684100c30506050403020101ec0d890205070500000000000150040200020020210200000010020001011c07e40101000000010206000003e806000000c80000894f16", &ProxyGetResponseRecord{}},
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	for _, tt := range jsonTests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame := testHex(t, tt.frame)
+			model := &ProtocolDlt698Model{}
+			if err := model.DecodeByBytes(frame); err != nil {
+				t.Fatal(err)
+			}
+			data, err := json.Marshal(model)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded := &ProtocolDlt698Model{}
+			if err = json.Unmarshal(data, decoded); err != nil {
+				t.Fatalf("unmarshal %s: %v", data, err)
+			}
+			if reflect.TypeOf(decoded.Data.Data) != reflect.TypeOf(tt.apdu) {
+				t.Fatalf("apdu = %T, want %T", decoded.Data.Data, tt.apdu)
+			}
+			encoded, err := decoded.Encoder()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(encoded, frame) {
+				t.Fatalf("encode = %x, want %x\njson: %s", encoded, frame, data)
+			}
+			again, err := json.Marshal(decoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(again, data) {
+				t.Fatalf("json = %s, want %s", again, data)
+			}
+		})
+	}
+}
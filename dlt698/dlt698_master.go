@@ -0,0 +1,324 @@
+package dlt698
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// 主站监听默认参数
+const (
+	DefaultLoginTimeout    = 30 * time.Second //连接建立后等待登录的时间
+	DefaultHeartbeatFactor = 3                //心跳超时倍数，超过心跳周期*倍数未收到心跳时判定离线
+	DefaultHeartbeatCycle  = 60 * time.Second //登录请求中心跳周期为0时使用
+	replyWriteTimeout      = 10 * time.Second
+)
+
+var (
+	ErrTerminalLogout    = errors.New("master: terminal logout")
+	ErrLoginTimeout      = errors.New("master: login timeout")
+	ErrHeartbeatTimeout  = errors.New("master: heartbeat timeout")
+	ErrTerminalReplaced  = errors.New("master: terminal logged in from another connection")
+	ErrTerminalForbidden = errors.New("master: terminal login refused")
+)
+
+// TerminalEvent 终端上线、离线事件
+type TerminalEvent struct {
+	Online   bool      //true上线，false离线
+	Terminal *Terminal //终端
+	Err      error     //离线原因，如ErrTerminalLogout、ErrHeartbeatTimeout、ErrTerminalReplaced或连接错误
+}
+
+// Master 主站监听，接收终端的TCP连接并处理链路请求
+// 终端登录后按服务器地址登记为在线，超过心跳周期*HeartbeatFactor未收到链路请求时关闭连接并判定离线，
+// 登录后可以通过Terminal的客户机会话读取、设置终端
+type Master struct {
+	CA              byte                                       //主站客户机地址
+	ClockTrusted    bool                                       //链路响应中的时钟可信标志
+	LoginTimeout    time.Duration                              //为0时使用DefaultLoginTimeout
+	HeartbeatFactor int                                        //为0时使用DefaultHeartbeatFactor
+	Authorize       func(address string, remote net.Addr) byte //登录校验，返回链路响应结果，nil时全部允许
+	Reporter        *Reporter                                  //主动上报处理，设置后自动应答上报
+	Unsolicited     func(t *Terminal, p *ProtocolDlt698Model)  //链路请求及上报以外的非请求响应报文的处理函数
+
+	mu        sync.Mutex
+	terminals map[string]*Terminal
+	event     func(e *TerminalEvent)
+}
+
+// NewMaster 创建主站监听
+// ca 主站客户机地址
+func NewMaster(ca byte) *Master {
+	return &Master{CA: ca, ClockTrusted: true, terminals: make(map[string]*Terminal)}
+}
+
+// HandleEvent 设置终端上线、离线事件的处理函数
+// 处理函数在连接的读取协程中执行，不应长时间阻塞
+func (m *Master) HandleEvent(handler func(e *TerminalEvent)) {
+	m.mu.Lock()
+	m.event = handler
+	m.mu.Unlock()
+}
+
+// Terminal 根据服务器地址查找在线终端
+func (m *Master) Terminal(address string) (*Terminal, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.terminals[address]
+	return t, ok
+}
+
+// Terminals 全部在线终端
+func (m *Master) Terminals() []*Terminal {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	terminals := make([]*Terminal, 0, len(m.terminals))
+	for _, t := range m.terminals {
+		terminals = append(terminals, t)
+	}
+	return terminals
+}
+
+// Serve 接收连接，每个连接在独立的协程中处理，直到监听关闭
+func (m *Master) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go m.ServeConn(conn)
+	}
+}
+
+// ServeConn 处理一个终端连接，直到连接关闭
+func (m *Master) ServeConn(conn net.Conn) {
+	t := &Terminal{
+		Client:     NewClient(conn, "", m.CA),
+		RemoteAddr: conn.RemoteAddr(),
+		kick:       make(chan time.Duration, 1),
+	}
+	t.Client.HandleUnsolicited(func(p *ProtocolDlt698Model) {
+		m.dispatch(t, p)
+	})
+	m.watch(t)
+	m.mu.Lock()
+	online := m.terminals[t.Address()] == t
+	if online {
+		delete(m.terminals, t.Address())
+	}
+	m.mu.Unlock()
+	if online {
+		m.emit(&TerminalEvent{Online: false, Terminal: t, Err: t.Err()})
+	}
+}
+
+// watch 登录及心跳超时检查
+func (m *Master) watch(t *Terminal) {
+	timeout := m.LoginTimeout
+	if timeout <= 0 {
+		timeout = DefaultLoginTimeout
+	}
+	reason := ErrLoginTimeout
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-t.Done():
+			return
+		case cycle := <-t.kick:
+			factor := m.HeartbeatFactor
+			if factor <= 0 {
+				factor = DefaultHeartbeatFactor
+			}
+			reason = ErrHeartbeatTimeout
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(cycle * time.Duration(factor))
+		case <-timer.C:
+			t.closeWith(reason)
+		}
+	}
+}
+
+func (m *Master) dispatch(t *Terminal, p *ProtocolDlt698Model) {
+	receiveTime := time.Now()
+	request, ok := p.Data.Data.(*LinkRequest)
+	if !ok {
+		if m.Reporter != nil && m.Reporter.Dispatch(t.Client, p) {
+			return
+		}
+		if m.Unsolicited != nil {
+			m.Unsolicited(t, p)
+		}
+		return
+	}
+	address := p.Address.Address
+	result := byte(0)
+	switch request.LinkRequestType {
+	case 0:
+		if m.Authorize != nil {
+			result = m.Authorize(address, t.RemoteAddr)
+		}
+	case 1:
+		//未登录的心跳不响应
+		if t.Address() != address {
+			return
+		}
+	}
+	if err := m.linkResponse(t, p, request, result, receiveTime); err != nil {
+		t.closeWith(err)
+		return
+	}
+	switch {
+	case result != 0:
+		t.closeWith(ErrTerminalForbidden)
+	case request.LinkRequestType == 0:
+		m.login(t, address, request.HeartbeatCycle, receiveTime)
+	case request.LinkRequestType == 1:
+		t.heartbeat(request.HeartbeatCycle, receiveTime)
+	case request.LinkRequestType == 2:
+		t.closeWith(ErrTerminalLogout)
+	}
+}
+
+// linkResponse 回复链路响应，请求时间原样返回，收到时间为报文到达时间，响应时间为发送时间
+func (m *Master) linkResponse(t *Terminal, p *ProtocolDlt698Model, request *LinkRequest, result byte, receiveTime time.Time) error {
+	var timing byte
+	if m.ClockTrusted {
+		timing = 1
+	}
+	response := ProtocolDlt698Model{
+		Control: &ControlRegion{"0", "0", "0", "0", "001"},
+		Address: &AddressRegion{AddressType: p.Address.AddressType, LogicAddress: p.Address.LogicAddress, Address: p.Address.Address, CA: p.Address.CA},
+		Data: &APDU{Pid: p.Data.Pid, Data: &LinkResponse{
+			Timing:       timing,
+			Result:       result,
+			RequestTime:  request.RequestTime,
+			ReceiveTime:  (&DateTime{}).BuildByTime(receiveTime),
+			ResponseTime: (&DateTime{}).Build(),
+		}},
+	}
+	frame, err := response.Encoder()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), replyWriteTimeout)
+	defer cancel()
+	return t.write(ctx, frame)
+}
+
+// login 登记在线终端，同一地址已在其他连接上登录时关闭旧连接
+func (m *Master) login(t *Terminal, address string, cycle uint16, now time.Time) {
+	m.mu.Lock()
+	old := m.terminals[address]
+	if old == t {
+		m.mu.Unlock()
+		t.heartbeat(cycle, now)
+		return
+	}
+	if previous := t.Address(); previous != "" && m.terminals[previous] == t {
+		delete(m.terminals, previous)
+	}
+	m.terminals[address] = t
+	t.setAddress(address)
+	m.mu.Unlock()
+	if old != nil {
+		old.closeWith(ErrTerminalReplaced)
+		m.emit(&TerminalEvent{Online: false, Terminal: old, Err: ErrTerminalReplaced})
+	}
+	t.mu.Lock()
+	t.loginTime = now
+	t.mu.Unlock()
+	t.heartbeat(cycle, now)
+	m.emit(&TerminalEvent{Online: true, Terminal: t})
+}
+
+func (m *Master) emit(e *TerminalEvent) {
+	m.mu.Lock()
+	handler := m.event
+	m.mu.Unlock()
+	if handler != nil {
+		handler(e)
+	}
+}
+
+/*----------------------------------终端-----------------------------------*/
+
+// Terminal 连接到主站的终端，登录后可以使用客户机会话读取、设置终端
+type Terminal struct {
+	*Client
+	RemoteAddr net.Addr
+
+	mu         sync.Mutex
+	loginTime  time.Time
+	lastActive time.Time
+	cycle      time.Duration
+	reason     error
+	kick       chan time.Duration
+}
+
+// LoginTime 登录时间
+func (t *Terminal) LoginTime() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.loginTime
+}
+
+// LastActive 最后一次收到链路请求的时间
+func (t *Terminal) LastActive() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lastActive
+}
+
+// HeartbeatCycle 终端上报的心跳周期
+func (t *Terminal) HeartbeatCycle() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cycle
+}
+
+// Err 离线原因，在线时返回nil
+func (t *Terminal) Err() error {
+	t.mu.Lock()
+	reason := t.reason
+	t.mu.Unlock()
+	if reason != nil {
+		return reason
+	}
+	return t.Client.Err()
+}
+
+// heartbeat 记录心跳并重新开始超时计时，心跳周期单位为秒
+func (t *Terminal) heartbeat(cycle uint16, now time.Time) {
+	t.mu.Lock()
+	t.lastActive = now
+	if cycle > 0 {
+		t.cycle = time.Duration(cycle) * time.Second
+	} else if t.cycle == 0 {
+		t.cycle = DefaultHeartbeatCycle
+	}
+	duration := t.cycle
+	t.mu.Unlock()
+	select {
+	case <-t.kick:
+	default:
+	}
+	t.kick <- duration
+}
+
+// closeWith 记录离线原因并关闭连接
+func (t *Terminal) closeWith(reason error) {
+	t.mu.Lock()
+	if t.reason == nil {
+		t.reason = reason
+	}
+	t.mu.Unlock()
+	_ = t.Client.Close()
+}
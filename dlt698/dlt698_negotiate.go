@@ -0,0 +1,385 @@
+package dlt698
+
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 建立应用连接请求的默认参数
+const (
+	DefaultConnectVersion     uint16 = 0x0016
+	DefaultConnectMaxSize     uint16 = 1024
+	DefaultConnectWindowSize  uint8  = 1
+	DefaultConnectLinkTimeOut uint32 = 7200
+)
+
+const (
+	protocolBlockSize = 8
+	funcBlockSize     = 16
+)
+
+var ErrConnectVersion = errors.New("connect: negotiated version is higher than expected")
+
+/*----------------------------------一致性块-----------------------------------*/
+
+// ProtocolBit 协议一致性块的位序号，bit0为第一个字节的最高位
+type ProtocolBit int
+
+const (
+	ProtocolApplicationAssociation ProtocolBit = iota //应用连接协商
+	ProtocolGetNormal                                 //请求对象属性
+	ProtocolGetWithList                               //批量请求基本对象属性
+	ProtocolGetRecord                                 //请求记录型对象属性
+	ProtocolGetProxy                                  //代理请求对象属性
+	ProtocolGetProxyRecord                            //代理请求记录型对象属性
+	ProtocolGetSubsequentFrame                        //请求分帧后续帧
+	ProtocolSetNormal                                 //设置基本对象属性
+	ProtocolSetWithList                               //批量设置基本对象属性
+	ProtocolSetWithGet                                //批量设置后读取
+	ProtocolSetProxy                                  //代理设置对象属性
+	ProtocolSetProxyWithGet                           //代理设置后读取
+	ProtocolActionNormal                              //执行对象方法
+	ProtocolActionWithList                            //批量执行对象方法
+	ProtocolActionWithGet                             //批量执行方法后读取
+	ProtocolActionProxy                               //代理执行对象方法
+	ProtocolActionProxyWithGet                        //代理执行后读取
+	ProtocolActiveEventReport                         //事件主动上报
+	ProtocolFollowEventReport                         //事件尾随上报
+	ProtocolEventAccessRequest                        //事件请求访问位
+	ProtocolSplitFrame                                //分帧数据传输
+)
+
+var protocolBitNames = []string{
+	"应用连接协商", "请求对象属性", "批量请求基本对象属性", "请求记录型对象属性", "代理请求对象属性", "代理请求记录型对象属性", "请求分帧后续帧",
+	"设置基本对象属性", "批量设置基本对象属性", "批量设置后读取", "代理设置对象属性", "代理设置后读取",
+	"执行对象方法", "批量执行对象方法", "批量执行方法后读取", "代理执行对象方法", "代理执行后读取",
+	"事件主动上报", "事件尾随上报", "事件请求访问位", "分帧数据传输",
+}
+
+func (b ProtocolBit) String() string {
+	if b >= 0 && int(b) < len(protocolBitNames) {
+		return protocolBitNames[b]
+	}
+	return "bit" + strconv.Itoa(int(b))
+}
+
+// FuncBit 功能一致性块的位序号，bit0为第一个字节的最高位
+type FuncBit int
+
+const (
+	FuncEnergy               FuncBit = iota //电能量计量
+	FuncBidirectional                       //双向有功计量
+	FuncReactiveEnergy                      //无功电能计量
+	FuncApparentEnergy                      //视在电能计量
+	FuncActiveDemand                        //有功需量
+	FuncReactiveDemand                      //无功需量
+	FuncApparentDemand                      //视在需量
+	FuncMultiTariff                         //复费率
+	FuncStepTariff                          //阶梯电价
+	FuncHarmonicEnergy                      //谐波计量
+	FuncInterHarmonicEnergy                 //间谐波计量
+	FuncVoltageQualification                //电压合格率
+)
+
+var funcBitNames = []string{
+	"电能量计量", "双向有功计量", "无功电能计量", "视在电能计量", "有功需量", "无功需量", "视在需量",
+	"复费率", "阶梯电价", "谐波计量", "间谐波计量", "电压合格率",
+}
+
+func (b FuncBit) String() string {
+	if b >= 0 && int(b) < len(funcBitNames) {
+		return funcBitNames[b]
+	}
+	return "bit" + strconv.Itoa(int(b))
+}
+
+// ProtocolConformance 协议一致性块(8个字节)，可以直接赋值给ConnectRequest.ProtocolBlock
+type ProtocolConformance []byte
+
+// NewProtocolConformance 根据位序号生成协议一致性块
+func NewProtocolConformance(bits ...ProtocolBit) ProtocolConformance {
+	p := make(ProtocolConformance, protocolBlockSize)
+	for _, bit := range bits {
+		setBit(p, int(bit))
+	}
+	return p
+}
+
+// AllProtocolConformance 支持全部已命名的协议一致性
+func AllProtocolConformance() ProtocolConformance {
+	p := make(ProtocolConformance, protocolBlockSize)
+	for bit := range protocolBitNames {
+		setBit(p, bit)
+	}
+	return p
+}
+
+// Has 是否支持
+func (p ProtocolConformance) Has(bit ProtocolBit) bool {
+	return hasBit(p, int(bit))
+}
+
+// Bits 全部置位的位序号
+func (p ProtocolConformance) Bits() []ProtocolBit {
+	var bits []ProtocolBit
+	for _, bit := range setBits(p) {
+		bits = append(bits, ProtocolBit(bit))
+	}
+	return bits
+}
+
+// Intersect 与另一个一致性块的交集
+func (p ProtocolConformance) Intersect(other ProtocolConformance) ProtocolConformance {
+	return intersectBits(p, other, protocolBlockSize)
+}
+
+func (p ProtocolConformance) String() string {
+	names := make([]string, 0)
+	for _, bit := range p.Bits() {
+		names = append(names, bit.String())
+	}
+	return strings.Join(names, ",")
+}
+
+// FuncConformance 功能一致性块(16个字节)，可以直接赋值给ConnectRequest.FuncBlock
+type FuncConformance []byte
+
+// NewFuncConformance 根据位序号生成功能一致性块
+func NewFuncConformance(bits ...FuncBit) FuncConformance {
+	f := make(FuncConformance, funcBlockSize)
+	for _, bit := range bits {
+		setBit(f, int(bit))
+	}
+	return f
+}
+
+// Has 是否支持
+func (f FuncConformance) Has(bit FuncBit) bool {
+	return hasBit(f, int(bit))
+}
+
+// Bits 全部置位的位序号
+func (f FuncConformance) Bits() []FuncBit {
+	var bits []FuncBit
+	for _, bit := range setBits(f) {
+		bits = append(bits, FuncBit(bit))
+	}
+	return bits
+}
+
+// Intersect 与另一个一致性块的交集
+func (f FuncConformance) Intersect(other FuncConformance) FuncConformance {
+	return intersectBits(f, other, funcBlockSize)
+}
+
+func (f FuncConformance) String() string {
+	names := make([]string, 0)
+	for _, bit := range f.Bits() {
+		names = append(names, bit.String())
+	}
+	return strings.Join(names, ",")
+}
+
+func setBit(block []byte, bit int) {
+	if bit >= 0 && bit/8 < len(block) {
+		block[bit/8] |= 0x80 >> (bit % 8)
+	}
+}
+
+func hasBit(block []byte, bit int) bool {
+	return bit >= 0 && bit/8 < len(block) && block[bit/8]&(0x80>>(bit%8)) != 0
+}
+
+func setBits(block []byte) []int {
+	var bits []int
+	for bit := 0; bit < len(block)*8; bit++ {
+		if hasBit(block, bit) {
+			bits = append(bits, bit)
+		}
+	}
+	return bits
+}
+
+func intersectBits(a []byte, b []byte, size int) []byte {
+	result := make([]byte, size)
+	for i := 0; i < size && i < len(a) && i < len(b); i++ {
+		result[i] = a[i] & b[i]
+	}
+	return result
+}
+
+/*----------------------------------认证-----------------------------------*/
+
+// Authenticator 应用连接认证，生成认证请求对象并校验服务器返回的认证附加信息
+// NullSecurity、PasswordSecurity可以直接使用，对称加密及数字签名由SymmetryAuthenticator、SignatureAuthenticator接入安全模块
+type Authenticator interface {
+	Mechanism() (ConnectMechanismInfo, error)
+	Verify(info *ConnectResponseInfo) error
+}
+
+var _ Authenticator = (*NullSecurity)(nil)
+var _ Authenticator = (*PasswordSecurity)(nil)
+var _ Authenticator = (*SymmetryAuthenticator)(nil)
+var _ Authenticator = (*SignatureAuthenticator)(nil)
+
+func (n *NullSecurity) Mechanism() (ConnectMechanismInfo, error) {
+	return n, nil
+}
+
+func (n *NullSecurity) Verify(_ *ConnectResponseInfo) error {
+	return nil
+}
+
+func (p *PasswordSecurity) Mechanism() (ConnectMechanismInfo, error) {
+	if p.Password == nil {
+		return nil, errors.New("connect: password == nil")
+	}
+	return p, nil
+}
+
+func (p *PasswordSecurity) Verify(_ *ConnectResponseInfo) error {
+	return nil
+}
+
+// SymmetryAuthenticator 对称加密认证
+type SymmetryAuthenticator struct {
+	Create func() (secret []byte, signature []byte, err error) //生成密文1及客户机签名1
+	Check  func(rn []byte, serverSign []byte) error            //校验服务器随机数及服务器签名，为nil时不校验
+}
+
+func (s *SymmetryAuthenticator) Mechanism() (ConnectMechanismInfo, error) {
+	secret, signature, err := createSecurity(s.Create)
+	if err != nil {
+		return nil, err
+	}
+	return &SymmetrySecurity{Secret: secret, Signature: signature}, nil
+}
+
+func (s *SymmetryAuthenticator) Verify(info *ConnectResponseInfo) error {
+	return checkSecurity(s.Check, info)
+}
+
+// SignatureAuthenticator 数字签名认证
+type SignatureAuthenticator struct {
+	Create func() (secret []byte, signature []byte, err error) //生成密文2及客户机签名2
+	Check  func(rn []byte, serverSign []byte) error            //校验服务器随机数及服务器签名，为nil时不校验
+}
+
+func (s *SignatureAuthenticator) Mechanism() (ConnectMechanismInfo, error) {
+	secret, signature, err := createSecurity(s.Create)
+	if err != nil {
+		return nil, err
+	}
+	return &SignatureSecurity{Secret: secret, Signature: signature}, nil
+}
+
+func (s *SignatureAuthenticator) Verify(info *ConnectResponseInfo) error {
+	return checkSecurity(s.Check, info)
+}
+
+func createSecurity(create func() ([]byte, []byte, error)) (*OctetString, *OctetString, error) {
+	if create == nil {
+		return nil, nil, errors.New("connect: authenticator create == nil")
+	}
+	secret, signature, err := create()
+	if err != nil {
+		return nil, nil, err
+	}
+	return &OctetString{Data: hex.EncodeToString(secret)}, &OctetString{Data: hex.EncodeToString(signature)}, nil
+}
+
+func checkSecurity(check func([]byte, []byte) error, info *ConnectResponseInfo) error {
+	if check == nil {
+		return nil
+	}
+	if info == nil || info.SecurityData == nil {
+		return errors.New("connect: security data == nil")
+	}
+	if info.SecurityData.RN == nil || info.SecurityData.ServerSign == nil {
+		return errors.New("connect: rn or server sign == nil")
+	}
+	rn, err := hex.DecodeString(info.SecurityData.RN.Data)
+	if err != nil {
+		return err
+	}
+	sign, err := hex.DecodeString(info.SecurityData.ServerSign.Data)
+	if err != nil {
+		return err
+	}
+	return check(rn, sign)
+}
+
+/*----------------------------------协商-----------------------------------*/
+
+// NewConnectRequest 使用默认的版本号、帧尺寸、窗口及超时时间生成建立应用连接请求
+func NewConnectRequest(protocol ProtocolConformance, function FuncConformance, auth Authenticator) (*ConnectRequest, error) {
+	if auth == nil {
+		auth = &NullSecurity{}
+	}
+	mechanism, err := auth.Mechanism()
+	if err != nil {
+		return nil, err
+	}
+	return &ConnectRequest{
+		ExpectVersion:           DefaultConnectVersion,
+		ProtocolBlock:           intersectBits(protocol, protocol, protocolBlockSize),
+		FuncBlock:               intersectBits(function, function, funcBlockSize),
+		ClientSendMaxSize:       DefaultConnectMaxSize,
+		ClientReceiveMaxSize:    DefaultConnectMaxSize,
+		ClientReceiveWindowSize: DefaultConnectWindowSize,
+		ClientHandleMaxSize:     DefaultConnectMaxSize,
+		LinkTimeOut:             DefaultConnectLinkTimeOut,
+		ConnectMechanismInfo:    mechanism,
+	}, nil
+}
+
+// Negotiated 协商后的应用连接参数
+type Negotiated struct {
+	Version        uint16              //商定的协议版本号
+	Protocol       ProtocolConformance //双方都支持的协议一致性
+	Func           FuncConformance     //双方都支持的功能一致性
+	SendMaxSize    uint16              //客户机发送帧最大尺寸，不超过服务器接收帧最大尺寸
+	ReceiveMaxSize uint16              //客户机接收帧最大尺寸，不超过服务器发送帧最大尺寸
+	MaxAPDUSize    uint16              //双方最大可处理APDU尺寸中的较小值
+	WindowSize     uint8               //同时等待响应的请求数
+	LinkTimeOut    time.Duration       //商定的应用连接超时时间
+}
+
+// Negotiate 根据客户机的请求及服务器的响应计算协商后的参数
+// 连接被拒绝时返回*ConnectError
+func Negotiate(request *ConnectRequest, response *ConnectResponse) (*Negotiated, error) {
+	if response.ConnectResponseInfo == nil {
+		return nil, errors.New("connect response info == nil")
+	}
+	if result := response.ConnectResponseInfo.ConnectResult; result != 0 {
+		return nil, &ConnectError{Result: result}
+	}
+	if request.ExpectVersion != 0 && response.ExpectVersion > request.ExpectVersion {
+		return nil, ErrConnectVersion
+	}
+	window := request.ClientReceiveWindowSize
+	if server := response.ServerReceiveWindowMaxSize; server > 0 && (window == 0 || server < window) {
+		window = server
+	}
+	return &Negotiated{
+		Version:        response.ExpectVersion,
+		Protocol:       ProtocolConformance(request.ProtocolBlock).Intersect(response.ProtocolBlock),
+		Func:           FuncConformance(request.FuncBlock).Intersect(response.FuncBlock),
+		SendMaxSize:    minSize(request.ClientSendMaxSize, response.ServerReceiveMaxSize),
+		ReceiveMaxSize: minSize(request.ClientReceiveMaxSize, response.ServerSendMaxSize),
+		MaxAPDUSize:    minSize(request.ClientHandleMaxSize, response.ServerHandleMaxSize),
+		WindowSize:     window,
+		LinkTimeOut:    time.Duration(response.LinkTimeOut) * time.Second,
+	}, nil
+}
+
+// minSize 较小的尺寸，为0表示未指定
+func minSize(a uint16, b uint16) uint16 {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,173 @@
+package dlt698
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+)
+
+// 分帧响应默认限制，防止终端持续返回后续帧
+const (
+	DefaultNextMaxPages = 1024
+	DefaultNextMaxBytes = 16 << 20
+)
+
+var (
+	ErrNextFrameNumber = errors.New("get response next: frame number not contiguous")
+	ErrNextDataType    = errors.New("get response next: result type changed")
+	ErrNextLimit       = errors.New("get response next: exceeds page or byte limit")
+)
+
+// NextCollector 收集GetResponseNext分帧响应，并合并为一个完整的结果
+// 使用方法：每收到一帧调用Add，未结束时使用Next()的LastId发送GetRequestNext，结束后调用ResultNormals或ResultRecords
+type NextCollector struct {
+	MaxPages int //最多帧数，为0时使用DefaultNextMaxPages
+	MaxBytes int //最多字节数，为0时使用DefaultNextMaxBytes
+
+	pages   int
+	bytes   int
+	last    uint16
+	end     bool
+	normals []*ResultNormal
+	records []*ResultRecord
+}
+
+// Add 添加一帧分帧响应，返回是否已收到末帧
+// 帧序号必须连续，分帧响应为DAR时返回对应的错误
+func (n *NextCollector) Add(g *GetResponseNext) (bool, error) {
+	if n.end {
+		return true, errors.New("get response next: already ended")
+	}
+	if n.pages > 0 && g.FrameNumber != n.last+1 {
+		return false, ErrNextFrameNumber
+	}
+	if g.Dar != nil {
+		if err := g.Dar.Err(); err != nil {
+			return false, err
+		}
+	}
+	size := 3
+	for _, d := range g.Data {
+		array, err := d.encoder()
+		if err != nil {
+			return false, err
+		}
+		size += len(array)
+	}
+	maxPages, maxBytes := n.MaxPages, n.MaxBytes
+	if maxPages <= 0 {
+		maxPages = DefaultNextMaxPages
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultNextMaxBytes
+	}
+	if n.pages+1 > maxPages || n.bytes+size > maxBytes {
+		return false, ErrNextLimit
+	}
+	n.pages++
+	n.bytes += size
+	n.last = g.FrameNumber
+	for _, d := range g.Data {
+		switch data := d.(type) {
+		case *ResultNormal:
+			if len(n.records) > 0 {
+				return false, ErrNextDataType
+			}
+			n.normals = append(n.normals, data)
+		case *ResultRecord:
+			if len(n.normals) > 0 {
+				return false, ErrNextDataType
+			}
+			n.records = mergeResultRecord(n.records, data)
+		default:
+			return false, ErrNextDataType
+		}
+	}
+	n.end = g.EndFlag != 0
+	return n.end, nil
+}
+
+// Next 请求下一帧的GetRequestNext
+func (n *NextCollector) Next() *GetRequestNext {
+	return &GetRequestNext{LastId: n.last}
+}
+
+// Pages 已收到的帧数
+func (n *NextCollector) Pages() int {
+	return n.pages
+}
+
+// ResultNormals 合并后的对象属性结果
+func (n *NextCollector) ResultNormals() []*ResultNormal {
+	return n.normals
+}
+
+// ResultRecords 合并后的记录型对象属性结果，相同OAD及RCSD的记录合并到一个ResultRecord中
+func (n *NextCollector) ResultRecords() []*ResultRecord {
+	return n.records
+}
+
+// mergeResultRecord 与最后一个结果的OAD及RCSD相同时合并记录，否则追加
+func mergeResultRecord(records []*ResultRecord, record *ResultRecord) []*ResultRecord {
+	if len(records) > 0 {
+		last := records[len(records)-1]
+		lastRow, ok1 := last.Data.(*RecordRow)
+		row, ok2 := record.Data.(*RecordRow)
+		if ok1 && ok2 && bytes.Equal(last.Oad, record.Oad) && sameRCSD(last.Rcsd, record.Rcsd) {
+			lastRow.RecordRow = append(lastRow.RecordRow, row.RecordRow...)
+			return records
+		}
+	}
+	return append(records, record)
+}
+
+func sameRCSD(a *RCSD, b *RCSD) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	aArray, err1 := a.encoder()
+	bArray, err2 := b.encoder()
+	return err1 == nil && err2 == nil && bytes.Equal(aArray, bArray)
+}
+
+/*---------------------------------客户机自动读取后续帧---------------------------------*/
+
+// readNext 收到GetResponseNext时持续请求后续帧，并转换为与请求对应的响应类型
+func (c *Client) readNext(ctx context.Context, request APDURegion, apdu *APDU, timeTag *TimeTag) (*APDU, error) {
+	collector := &NextCollector{MaxPages: c.NextMaxPages, MaxBytes: c.NextMaxBytes}
+	for {
+		end, err := collector.Add(apdu.Data.(*GetResponseNext))
+		if err != nil {
+			return nil, err
+		}
+		if end {
+			break
+		}
+		apdu, err = c.exchange(ctx, collector.Next(), timeTag)
+		if err != nil {
+			return nil, err
+		}
+	}
+	result := &APDU{Pid: apdu.Pid, TimeTag: apdu.TimeTag, FollowReport: apdu.FollowReport}
+	normals, records := collector.ResultNormals(), collector.ResultRecords()
+	switch request.(type) {
+	case *GetRequestNormal:
+		if len(normals) != 1 {
+			return nil, errors.New("get response next: expect 1 result normal, got " + strconv.Itoa(len(normals)))
+		}
+		result.Data = &GetResponseNormal{ResultNormal: normals[0]}
+	case *GetRequestNormalList:
+		result.Data = &GetResponseNormalList{ResultNormals: normals}
+	case *GetRequestRecord:
+		if len(records) != 1 {
+			return nil, errors.New("get response next: expect 1 result record, got " + strconv.Itoa(len(records)))
+		}
+		result.Data = &GetResponseRecord{ResultRecord: records[0]}
+	case *GetRequestRecordList:
+		result.Data = &GetResponseRecordList{ResultRecords: records}
+	default:
+		return nil, errors.New("get response next: unsupported request " + request.APDUMark())
+	}
+	return result, nil
+}
@@ -0,0 +1,206 @@
+package dlt698
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultReportDedupWindow 重复上报判定时间
+const DefaultReportDedupWindow = 5 * time.Minute
+
+var ErrNotReport = errors.New("report: not a report notification")
+
+// Report 终端上报的数据，按订阅的OAD过滤后交给订阅者
+type Report struct {
+	Address string                       //服务器地址
+	Pid     byte                         //上报报文的PIID
+	Normals []*ResultNormal              //ReportNotificationList
+	Records []*ResultRecord              //ReportNotificationRecordList
+	Trans   *ReportNotificationTransData //ReportNotificationTransData
+	Frame   *ProtocolDlt698Model         //完整的上报报文
+}
+
+// ReportFunc 上报处理函数
+type ReportFunc func(report *Report)
+
+type reportSubscription struct {
+	keys    map[uint32]bool //为空时订阅全部上报
+	handler ReportFunc
+}
+
+// Reporter 主动上报处理
+// 收到ReportNotificationList、ReportNotificationRecordList、ReportNotificationTransData时使用相同的PIID和OAD应答，
+// 终端未收到应答时会重发，PIID及内容相同的上报在DedupWindow内只交给订阅者一次(重发的上报依然应答)
+type Reporter struct {
+	DedupWindow time.Duration //为0时使用DefaultReportDedupWindow
+
+	mu   sync.Mutex
+	id   int
+	subs map[int]*reportSubscription
+	seen map[string]time.Time
+}
+
+// NewReporter 创建上报处理
+func NewReporter() *Reporter {
+	return &Reporter{subs: make(map[int]*reportSubscription), seen: make(map[string]time.Time)}
+}
+
+// Subscribe 订阅上报，oad为空时订阅全部上报，否则只接收匹配的OAD(元素索引为0时匹配全部元素，属性为0时匹配全部属性)
+// 返回取消订阅的函数，处理函数在连接的读取协程中执行，不应长时间阻塞
+func (r *Reporter) Subscribe(handler ReportFunc, oad ...[]byte) (func(), error) {
+	sub := &reportSubscription{keys: make(map[uint32]bool), handler: handler}
+	for _, o := range oad {
+		if len(o) != 4 {
+			return nil, errors.New("report: oad size != 4")
+		}
+		sub.keys[binary.BigEndian.Uint32(o)] = true
+	}
+	r.mu.Lock()
+	r.id++
+	id := r.id
+	r.subs[id] = sub
+	r.mu.Unlock()
+	return func() {
+		r.mu.Lock()
+		delete(r.subs, id)
+		r.mu.Unlock()
+	}, nil
+}
+
+// Dispatch 处理客户机会话收到的非请求响应报文，是上报时通过会话应答并返回true
+// 可以在HandleUnsolicited中调用，其它报文返回false
+func (r *Reporter) Dispatch(c *Client, p *ProtocolDlt698Model) bool {
+	response, err := r.Handle(p)
+	if err != nil {
+		return err != ErrNotReport
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), replyWriteTimeout)
+	defer cancel()
+	_ = c.write(ctx, response)
+	return true
+}
+
+// Handle 处理上报报文，返回应答报文，不是上报时返回ErrNotReport
+func (r *Reporter) Handle(p *ProtocolDlt698Model) ([]byte, error) {
+	if p == nil || p.Data == nil || p.Address == nil {
+		return nil, ErrNotReport
+	}
+	report := &Report{Address: p.Address.Address, Pid: p.Data.Pid, Frame: p}
+	var response APDURegion
+	switch data := p.Data.Data.(type) {
+	case *ReportNotificationList:
+		report.Normals = data.Data
+		oad := make([][]byte, len(data.Data))
+		for i, normal := range data.Data {
+			oad[i] = normal.OAD
+		}
+		response = &ReportResponseList{Data: oad}
+	case *ReportNotificationRecordList:
+		report.Records = data.Data
+		oad := make([][]byte, len(data.Data))
+		for i, record := range data.Data {
+			oad[i] = record.Oad
+		}
+		response = &ReportResponseRecordList{ReportResponseList: ReportResponseList{Data: oad}}
+	case *ReportNotificationTransData:
+		report.Trans = data
+		response = &ReportResponseTransData{}
+	default:
+		return nil, ErrNotReport
+	}
+	reply := ProtocolDlt698Model{
+		Control: &ControlRegion{"0", "0", "0", "0", "011"},
+		Address: &AddressRegion{AddressType: p.Address.AddressType, LogicAddress: p.Address.LogicAddress, Address: p.Address.Address, CA: p.Address.CA},
+		Data:    &APDU{Pid: p.Data.Pid, Data: response, TimeTag: p.Data.TimeTag},
+	}
+	frame, err := reply.Encoder()
+	if err != nil {
+		return nil, err
+	}
+	if r.duplicate(p) {
+		return frame, nil
+	}
+	r.deliver(report)
+	return frame, nil
+}
+
+// duplicate 按服务器地址、PIID及APDU内容判断是否为重发的上报
+func (r *Reporter) duplicate(p *ProtocolDlt698Model) bool {
+	content, err := p.Data.Data.encoder()
+	if err != nil {
+		return false
+	}
+	sum := sha256.Sum256(content)
+	key := p.Address.Address + string([]byte{p.Data.Pid}) + string(sum[:])
+	window := r.DedupWindow
+	if window <= 0 {
+		window = DefaultReportDedupWindow
+	}
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, t := range r.seen {
+		if now.Sub(t) > window {
+			delete(r.seen, k)
+		}
+	}
+	if _, ok := r.seen[key]; ok {
+		return true
+	}
+	r.seen[key] = now
+	return false
+}
+
+func (r *Reporter) deliver(report *Report) {
+	r.mu.Lock()
+	subs := make([]*reportSubscription, 0, len(r.subs))
+	for id := 1; id <= r.id; id++ {
+		if sub, ok := r.subs[id]; ok {
+			subs = append(subs, sub)
+		}
+	}
+	r.mu.Unlock()
+	for _, sub := range subs {
+		if filtered := sub.filter(report); filtered != nil {
+			sub.handler(filtered)
+		}
+	}
+}
+
+// filter 返回只包含订阅OAD的上报，没有匹配的OAD时返回nil
+func (s *reportSubscription) filter(report *Report) *Report {
+	if len(s.keys) == 0 {
+		return report
+	}
+	filtered := &Report{Address: report.Address, Pid: report.Pid, Frame: report.Frame}
+	for _, normal := range report.Normals {
+		if s.match(normal.OAD) {
+			filtered.Normals = append(filtered.Normals, normal)
+		}
+	}
+	for _, record := range report.Records {
+		if s.match(record.Oad) {
+			filtered.Records = append(filtered.Records, record)
+		}
+	}
+	if report.Trans != nil && s.match(report.Trans.Oad) {
+		filtered.Trans = report.Trans
+	}
+	if filtered.Normals == nil && filtered.Records == nil && filtered.Trans == nil {
+		return nil
+	}
+	return filtered
+}
+
+func (s *reportSubscription) match(oad []byte) bool {
+	for _, key := range oadKeys(oad) {
+		if s.keys[key] {
+			return true
+		}
+	}
+	return false
+}
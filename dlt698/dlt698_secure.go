@@ -0,0 +1,263 @@
+package dlt698
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"sync"
+)
+
+// 应用连接请求认证的结果
+const (
+	ConnectResultSuccess    byte = 0   //允许建立应用连接
+	ConnectResultPassword   byte = 1   //密码错误
+	ConnectResultSymmetry   byte = 2   //对称解密错误
+	ConnectResultAsymmetry  byte = 3   //非对称解密错误
+	ConnectResultSignature  byte = 4   //签名错误
+	ConnectResultVersion    byte = 5   //协议版本不匹配
+	ConnectResultESAMFailed byte = 6   //ESAM通信故障
+	ConnectResultOther      byte = 255 //其他错误
+)
+
+// ESAM加密类型
+const (
+	EncryptPlaintextMAC  = "11" //明文+MAC
+	EncryptCiphertext    = "96" //密文
+	EncryptCiphertextMAC = "97" //密文+MAC
+)
+
+const esamMACSize = 4 //ESAM计算的数据MAC长度
+
+// ESAM 终端安全模块，Encrypt的加密类型为EncryptPlaintextMAC等字符串
+// zx1166y.SPICodec的Encrypt使用zx1166y.EncryptType，不能直接使用，经zx1166y.NewDLT698ESAM包装后满足该接口
+// secureFlag为安全标识的十六进制字符串，attachData为附加数据
+type ESAM interface {
+	// SessionKeyConnect 会话密钥协商，输入密文1及客户机签名1，返回服务器随机数及服务器签名的十六进制字符串
+	SessionKeyConnect(ucOutSessionInit, ucOutSign []byte) (ucSessionData string, ucSign string, err error)
+	// Encrypt 按加密类型计算数据MAC或加密，结果中数据MAC在最后4个字节
+	Encrypt(encrypt string, data []byte) ([]byte, error)
+	// VerifyBySid 使用安全标识解密
+	VerifyBySid(secureFlag string, attachData, data []byte) ([]byte, error)
+	// VerifyBySidMac 使用安全标识及数据MAC验证，密文时返回明文
+	VerifyBySidMac(secureFlag string, attachData, data, mac []byte) ([]byte, error)
+	// ReadTerminal 使用主站下发的随机数计算抄读数据的MAC，结果中数据MAC在最后4个字节
+	ReadTerminal(rn, data []byte) ([]byte, error)
+	// TerminalActiveReport1 计算主动上报数据的MAC
+	TerminalActiveReport1(data []byte) (resultData []byte, mac []byte, err error)
+	// TerminalActiveReport2 验证主站对主动上报的应答
+	TerminalActiveReport2(secureFlag string, attachData []byte, data []byte, mac []byte) ([]byte, error)
+}
+
+// Protection 安全响应的保护方式，与对应的安全请求相同
+type Protection struct {
+	Ciphertext bool   //密文应用数据单元
+	MAC        bool   //带数据MAC
+	RN         []byte //明文+随机数请求的随机数，不为nil时数据MAC由ReadTerminal计算
+}
+
+// SecureChannel 终端侧安全传输
+// 使用ESAM完成对称加密方式的建立应用连接，验证、解密收到的安全请求，并按请求的保护方式生成安全响应
+// 同一个ESAM的命令不能交叉执行，SecureChannel的方法可以在多个协程中同时调用
+type SecureChannel struct {
+	ESAM ESAM
+
+	mu sync.Mutex
+}
+
+// NewSecureChannel 创建安全传输
+func NewSecureChannel(esam ESAM) *SecureChannel {
+	return &SecureChannel{ESAM: esam}
+}
+
+// Connect 对称加密方式的会话密钥协商，返回连接响应对象，协商失败时认证结果为对称解密错误
+func (c *SecureChannel) Connect(request *SymmetrySecurity) *ConnectResponseInfo {
+	if request.Secret == nil || request.Signature == nil {
+		return &ConnectResponseInfo{ConnectResult: ConnectResultSymmetry}
+	}
+	secret, err1 := hex.DecodeString(request.Secret.Data)
+	signature, err2 := hex.DecodeString(request.Signature.Data)
+	if err1 != nil || err2 != nil {
+		return &ConnectResponseInfo{ConnectResult: ConnectResultSymmetry}
+	}
+	c.mu.Lock()
+	rn, sign, err := c.ESAM.SessionKeyConnect(secret, signature)
+	c.mu.Unlock()
+	if err != nil {
+		return &ConnectResponseInfo{ConnectResult: ConnectResultSymmetry}
+	}
+	return &ConnectResponseInfo{
+		ConnectResult: ConnectResultSuccess,
+		SecurityData:  &SecurityData{RN: &OctetString{Data: rn}, ServerSign: &OctetString{Data: sign}},
+	}
+}
+
+// Unwrap 验证安全请求的数据MAC或解密密文，返回明文APDU及响应的保护方式
+// 应用数据单元类型与数据验证信息不匹配时返回DarSecurityUnmatched，ESAM验证失败时返回ESAM的错误
+// 数据验证信息为RN_MAC时同样返回DarSecurityUnmatched，ESAM接口没有对应的验证命令
+func (c *SecureChannel) Unwrap(request *SecurityRequest) (*APDU, *Protection, error) {
+	if request.Data == nil {
+		return nil, nil, errors.New("secure: security data == nil")
+	}
+	data, err := hex.DecodeString(request.Data.Data)
+	if err != nil {
+		return nil, nil, err
+	}
+	ciphertext := request.DataType == SecurityCiphertext
+	protection := &Protection{Ciphertext: ciphertext, MAC: true}
+	var plaintext []byte
+	c.mu.Lock()
+	switch verify := request.Verify.(type) {
+	case *SIDMAC:
+		flag, attach, e := sidParams(verify.Sid)
+		if e != nil || verify.Mac == nil {
+			err = DarSecurityUnmatched
+			break
+		}
+		mac, e := hex.DecodeString(verify.Mac.Data)
+		if e != nil {
+			err = e
+			break
+		}
+		if !ciphertext && request.Apdu != nil && isReportResponse(request.Apdu.Data) {
+			_, err = c.ESAM.TerminalActiveReport2(flag, attach, data, mac)
+		} else {
+			plaintext, err = c.ESAM.VerifyBySidMac(flag, attach, data, mac)
+		}
+	case *SID:
+		flag, attach, e := sidParams(verify)
+		if e != nil || !ciphertext {
+			err = DarSecurityUnmatched
+			break
+		}
+		protection.MAC = false
+		plaintext, err = c.ESAM.VerifyBySid(flag, attach, data)
+	case *RN:
+		if ciphertext {
+			err = DarSecurityUnmatched
+			break
+		}
+		protection.RN, err = hex.DecodeString(verify.Data)
+	case *RNMAC:
+		//ESAM接口没有验证主站RN_MAC的命令，不支持该验证方式
+		err = DarSecurityUnmatched
+	default:
+		err = DarSecurityUnmatched
+	}
+	c.mu.Unlock()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ciphertext {
+		return request.Apdu, protection, nil
+	}
+	apdu := &APDU{}
+	if err = apdu.decoder(bytes.NewReader(plaintext)); err != nil {
+		return nil, nil, DarSymmetricDecryptError
+	}
+	return apdu, protection, nil
+}
+
+// Wrap 按保护方式生成安全响应
+func (c *SecureChannel) Wrap(apdu *APDU, protection *Protection) (*SecurityResponse, error) {
+	data, err := apdu.encoder()
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case protection.RN != nil:
+		result, err := c.ESAM.ReadTerminal(protection.RN, data)
+		if err != nil {
+			return nil, err
+		}
+		return plaintextResponse(apdu, data, result)
+	case !protection.Ciphertext:
+		result, err := c.ESAM.Encrypt(EncryptPlaintextMAC, data)
+		if err != nil {
+			return nil, err
+		}
+		return plaintextResponse(apdu, data, result)
+	case protection.MAC:
+		result, err := c.ESAM.Encrypt(EncryptCiphertextMAC, data)
+		if err != nil {
+			return nil, err
+		}
+		if len(result) <= esamMACSize {
+			return nil, errors.New("secure: esam result size err")
+		}
+		size := len(result) - esamMACSize
+		return &SecurityResponse{
+			DataType: SecurityCiphertext,
+			Data:     &OctetString{Data: hex.EncodeToString(result[:size])},
+			Mac:      &MAC{OctetString{Data: hex.EncodeToString(result[size:])}},
+		}, nil
+	}
+	result, err := c.ESAM.Encrypt(EncryptCiphertext, data)
+	if err != nil {
+		return nil, err
+	}
+	return &SecurityResponse{DataType: SecurityCiphertext, Data: &OctetString{Data: hex.EncodeToString(result)}}, nil
+}
+
+// WrapReport 生成明文+MAC的主动上报，主站的应答由Unwrap验证
+func (c *SecureChannel) WrapReport(apdu *APDU) (*SecurityResponse, error) {
+	data, err := apdu.encoder()
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	_, mac, err := c.ESAM.TerminalActiveReport1(data)
+	c.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if len(mac) != esamMACSize {
+		return nil, errors.New("secure: esam mac size err")
+	}
+	return &SecurityResponse{
+		DataType: SecurityPlaintext,
+		Data:     &OctetString{Data: hex.EncodeToString(data)},
+		Apdu:     apdu,
+		Mac:      &MAC{OctetString{Data: hex.EncodeToString(mac)}},
+	}, nil
+}
+
+// plaintextResponse 明文+MAC的安全响应，数据MAC为ESAM结果的最后4个字节
+func plaintextResponse(apdu *APDU, data []byte, result []byte) (*SecurityResponse, error) {
+	if len(result) < esamMACSize {
+		return nil, errors.New("secure: esam mac size err")
+	}
+	return &SecurityResponse{
+		DataType: SecurityPlaintext,
+		Data:     &OctetString{Data: hex.EncodeToString(data)},
+		Apdu:     apdu,
+		Mac:      &MAC{OctetString{Data: hex.EncodeToString(result[len(result)-esamMACSize:])}},
+	}, nil
+}
+
+// sidParams 安全标识的十六进制字符串及附加数据
+func sidParams(sid *SID) (string, []byte, error) {
+	if sid == nil {
+		return "", nil, errors.New("secure: sid == nil")
+	}
+	flag := make([]byte, 4)
+	binary.BigEndian.PutUint32(flag, sid.Flag)
+	var attach []byte
+	if sid.Additional != nil {
+		var err error
+		if attach, err = hex.DecodeString(sid.Additional.Data); err != nil {
+			return "", nil, err
+		}
+	}
+	return hex.EncodeToString(flag), attach, nil
+}
+
+func isReportResponse(region APDURegion) bool {
+	switch region.(type) {
+	case *ReportResponseList, *ReportResponseRecordList, *ReportResponseTransData:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,300 @@
+package dlt698
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+var _ ESAM = (*testESAM)(nil)
+
+// testESAM 测试用的ESAM，会话密钥协商后才能加密、验证
+// 加密为数据与会话密钥逐字节异或，数据MAC为HMAC-SHA256的前4个字节，主站侧使用同样的算法生成测试报文
+type testESAM struct {
+	key     []byte
+	session bool
+}
+
+var errTestSession = errors.New("test esam: session not established")
+
+func newTestESAM() *testESAM {
+	return &testESAM{key: []byte("0123456789abcdef")}
+}
+
+func (e *testESAM) mac(data []byte) []byte {
+	h := hmac.New(sha256.New, e.key)
+	h.Write(data)
+	return h.Sum(nil)[:esamMACSize]
+}
+
+func (e *testESAM) crypt(data []byte) []byte {
+	result := make([]byte, len(data))
+	for i, b := range data {
+		result[i] = b ^ e.key[i%len(e.key)]
+	}
+	return result
+}
+
+// verify 验证数据MAC，安全标识最后1个字节的bit1为1时数据为密文，返回明文
+func (e *testESAM) verify(secureFlag string, data, mac []byte) ([]byte, error) {
+	if !e.session {
+		return nil, errTestSession
+	}
+	flag, err := hex.DecodeString(secureFlag)
+	if err != nil || len(flag) != 4 {
+		return nil, errors.New("test esam: secure flag err")
+	}
+	if mac != nil && !hmac.Equal(mac, e.mac(data)) {
+		return nil, errors.New("test esam: mac err")
+	}
+	if flag[3]&0x02 != 0 {
+		return e.crypt(data), nil
+	}
+	return data, nil
+}
+
+func (e *testESAM) SessionKeyConnect(ucOutSessionInit, ucOutSign []byte) (string, string, error) {
+	if !hmac.Equal(ucOutSign, e.mac(ucOutSessionInit)) {
+		return "", "", errors.New("test esam: sign err")
+	}
+	e.session = true
+	return "0102030405060708", hex.EncodeToString(e.mac([]byte{1, 2, 3, 4, 5, 6, 7, 8})), nil
+}
+
+func (e *testESAM) Encrypt(encrypt string, data []byte) ([]byte, error) {
+	if !e.session {
+		return nil, errTestSession
+	}
+	switch encrypt {
+	case EncryptPlaintextMAC:
+		return append(append([]byte{}, data...), e.mac(data)...), nil
+	case EncryptCiphertextMAC:
+		return append(e.crypt(data), e.mac(data)...), nil
+	case EncryptCiphertext:
+		return e.crypt(data), nil
+	}
+	return nil, errors.New("test esam: encrypt type err")
+}
+
+func (e *testESAM) VerifyBySid(secureFlag string, attachData, data []byte) ([]byte, error) {
+	return e.verify(secureFlag, data, nil)
+}
+
+func (e *testESAM) VerifyBySidMac(secureFlag string, attachData, data, mac []byte) ([]byte, error) {
+	return e.verify(secureFlag, data, mac)
+}
+
+func (e *testESAM) ReadTerminal(rn, data []byte) ([]byte, error) {
+	return append(append([]byte{}, data...), e.mac(append(append([]byte{}, rn...), data...))...), nil
+}
+
+func (e *testESAM) TerminalActiveReport1(data []byte) ([]byte, []byte, error) {
+	return data, e.mac(data), nil
+}
+
+func (e *testESAM) TerminalActiveReport2(secureFlag string, attachData []byte, data []byte, mac []byte) ([]byte, error) {
+	return e.verify(secureFlag, data, mac)
+}
+
+const testSecureAddress = "010203040506"
+
+var testSecureOAD = []byte{0x40, 0x01, 0x02, 0x00}
+
+// newSecureServer 使用testESAM的服务器，4001读取返回通信地址，设置保存到value
+func newSecureServer(t *testing.T, secureWrite bool) (*Server, *testESAM, *string) {
+	t.Helper()
+	esam := newTestESAM()
+	server := NewServer(testSecureAddress)
+	server.Security = NewSecureChannel(esam)
+	server.SecureWrite = secureWrite
+	value := testSecureAddress
+	server.HandleGet(testSecureOAD, func(r *ServerRequest, oad []byte) (DataInter, error) {
+		return &OctetString{Data: value}, nil
+	})
+	server.HandleSet(testSecureOAD, func(r *ServerRequest, oad []byte, data DataInter) error {
+		value = data.(*OctetString).Data
+		return nil
+	})
+
+	secret := bytes.Repeat([]byte{0x11}, 32)
+	request, err := NewConnectRequest(AllProtocolConformance(), nil, &SymmetryAuthenticator{
+		Create: func() ([]byte, []byte, error) { return secret, esam.mac(secret), nil },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame, err := CreateConnectRequest(testSecureAddress, 1, 1, request, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := handleSecure(t, server, frame)
+	connect, ok := response.Data.Data.(*ConnectResponse)
+	if !ok || connect.ConnectResponseInfo == nil || connect.ConnectResponseInfo.ConnectResult != ConnectResultSuccess {
+		t.Fatalf("connect response: %+v", response.Data.Data)
+	}
+	if connect.ConnectResponseInfo.SecurityData == nil || connect.ConnectResponseInfo.SecurityData.RN == nil {
+		t.Fatal("connect response without server random")
+	}
+	return server, esam, &value
+}
+
+func handleSecure(t *testing.T, server *Server, frame []byte) *ProtocolDlt698Model {
+	t.Helper()
+	responseFrame, err := server.HandleBytes(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := &ProtocolDlt698Model{}
+	if err = response.DecodeByBytes(responseFrame); err != nil {
+		t.Fatal(err)
+	}
+	return response
+}
+
+func encodeAPDU(t *testing.T, apdu *APDU) []byte {
+	t.Helper()
+	data, err := apdu.encoder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func decodeAPDU(t *testing.T, data []byte) *APDU {
+	t.Helper()
+	apdu := &APDU{}
+	if err := apdu.decoder(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	return apdu
+}
+
+func testSIDMAC(esam *testESAM, flag uint32, data []byte) *SIDMAC {
+	return &SIDMAC{
+		Sid: &SID{Flag: flag, Additional: &OctetString{Data: "00000000"}},
+		Mac: &MAC{OctetString: OctetString{Data: hex.EncodeToString(esam.mac(data))}},
+	}
+}
+
+func securityResponse(t *testing.T, response *ProtocolDlt698Model) (*SecurityResponse, []byte, []byte) {
+	t.Helper()
+	security, ok := response.Data.Data.(*SecurityResponse)
+	if !ok {
+		t.Fatalf("response is not security response: %T", response.Data.Data)
+	}
+	if security.DataType == SecurityDar {
+		t.Fatalf("security response dar: %v", security.Dar.Data)
+	}
+	data, err := hex.DecodeString(security.Data.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mac []byte
+	if security.Mac != nil {
+		if mac, err = hex.DecodeString(security.Mac.Data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return security, data, mac
+}
+
+// getResult 读取响应中的通信地址
+func getResult(t *testing.T, apdu *APDU) string {
+	t.Helper()
+	get, ok := apdu.Data.(*GetResponseNormal)
+	if !ok {
+		t.Fatalf("response is not get response: %T", apdu.Data)
+	}
+	value, ok := get.ResultNormal.GetResult.Data.(*OctetString)
+	if !ok {
+		t.Fatalf("get result: %+v", get.ResultNormal.GetResult.Data)
+	}
+	return value.Data
+}
+
+func TestSecureChannelPlaintextMAC(t *testing.T) {
+	server, esam, _ := newSecureServer(t, false)
+	apdu := &APDU{Pid: 0x05, Data: &GetRequestNormal{OAD: testSecureOAD}}
+	frame, err := CreateSecurityRequestPlaintextMAC(testSecureAddress, 1, apdu, testSIDMAC(esam, 0x81020000, encodeAPDU(t, apdu)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	security, data, mac := securityResponse(t, handleSecure(t, server, frame))
+	if security.DataType != SecurityPlaintext {
+		t.Fatalf("data type = %d", security.DataType)
+	}
+	if !bytes.Equal(mac, esam.mac(data)) {
+		t.Fatal("response mac mismatch")
+	}
+	if value := getResult(t, security.Apdu); value != testSecureAddress {
+		t.Fatalf("value = %s", value)
+	}
+
+	//数据MAC错误时不执行请求
+	frame, err = CreateSecurityRequestPlaintextMAC(testSecureAddress, 1, apdu, testSIDMAC(esam, 0x81020000, []byte{0x00}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := handleSecure(t, server, frame)
+	if security, ok := response.Data.Data.(*SecurityResponse); !ok || security.DataType != SecurityDar {
+		t.Fatalf("bad mac response: %+v", response.Data.Data)
+	}
+}
+
+func TestSecureChannelCiphertextSIDMAC(t *testing.T) {
+	server, esam, _ := newSecureServer(t, false)
+	ciphertext := esam.crypt(encodeAPDU(t, &APDU{Pid: 0x06, Data: &GetRequestNormal{OAD: testSecureOAD}}))
+	frame, err := CreateSecurityRequestCiphertextMAC(testSecureAddress, 1, hex.EncodeToString(ciphertext), testSIDMAC(esam, 0x81020002, ciphertext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	security, data, mac := securityResponse(t, handleSecure(t, server, frame))
+	if security.DataType != SecurityCiphertext || mac == nil {
+		t.Fatalf("data type = %d, mac = %x", security.DataType, mac)
+	}
+	plaintext := esam.crypt(data)
+	if !bytes.Equal(mac, esam.mac(plaintext)) {
+		t.Fatal("response mac mismatch")
+	}
+	if value := getResult(t, decodeAPDU(t, plaintext)); value != testSecureAddress {
+		t.Fatalf("value = %s", value)
+	}
+}
+
+func TestSecureChannelCiphertextSID(t *testing.T) {
+	server, esam, value := newSecureServer(t, true)
+	set := &APDU{Pid: 0x07, Data: &SetRequestNormal{Oad: testSecureOAD, Data: &OctetString{Data: "112233445566"}}}
+	ciphertext := esam.crypt(encodeAPDU(t, set))
+	sid := &SID{Flag: 0x81020002, Additional: &OctetString{Data: "00000000"}}
+	frame, err := CreateSecurityRequestCiphertext(testSecureAddress, 1, hex.EncodeToString(ciphertext), sid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	security, data, mac := securityResponse(t, handleSecure(t, server, frame))
+	if security.DataType != SecurityCiphertext || mac != nil {
+		t.Fatalf("data type = %d, mac = %x", security.DataType, mac)
+	}
+	if _, ok := decodeAPDU(t, esam.crypt(data)).Data.(*SetResponseNormal); !ok {
+		t.Fatal("response is not set response")
+	}
+	if *value != "112233445566" {
+		t.Fatalf("value = %s", *value)
+	}
+
+	//SecureWrite时明文设置被拒绝
+	frame, err = CreateSetRequestNormal(testSecureAddress, 1, 0x08, testSecureOAD, &OctetString{Data: "000000000000"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := handleSecure(t, server, frame)
+	setResponse, ok := response.Data.Data.(*SetResponseNormal)
+	if !ok || setResponse.Dar.Data != DarSecurityUnmatched {
+		t.Fatalf("plain set response: %+v", response.Data.Data)
+	}
+	if *value != "112233445566" {
+		t.Fatalf("value = %s", *value)
+	}
+}
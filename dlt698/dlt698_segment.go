@@ -0,0 +1,228 @@
+package dlt698
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var _ FrameRegion = (*SegmentRegion)(nil)
+
+const (
+	SegmentFirst   byte = 0 //起始帧
+	SegmentLast    byte = 1 //最后帧
+	SegmentConfirm byte = 2 //确认帧
+	SegmentMiddle  byte = 3 //中间帧
+)
+
+const segmentMaxIndex uint16 = 0x0FFF
+
+// SegmentRegion 分帧格式域及分帧数据
+// 分帧格式域共两个字节，bit0~bit11为分帧传输序号，bit14~bit15为分帧类型
+type SegmentRegion struct {
+	Index uint16 `json:"index"` //分帧传输序号 0~4095
+	Type  byte   `json:"type"`  //分帧类型 0-起始帧 1-最后帧 2-确认帧 3-中间帧
+	Data  []byte `json:"data"`  //APDU片段，确认帧为空
+}
+
+func (s *SegmentRegion) decoder(buf *bytes.Reader) error {
+	var head uint16
+	if err := binary.Read(buf, binary.LittleEndian, &head); err != nil {
+		return errors.New("SegmentRegion decode err: " + err.Error())
+	}
+	s.Index = head & segmentMaxIndex
+	s.Type = byte(head>>14) & 0b11
+	s.Data = make([]byte, buf.Len())
+	if _, err := buf.Read(s.Data); err != nil && len(s.Data) > 0 {
+		return errors.New("SegmentRegion decode err: " + err.Error())
+	}
+	return nil
+}
+
+func (s *SegmentRegion) encoder() ([]byte, error) {
+	if s.Index > segmentMaxIndex {
+		return nil, errors.New("SegmentRegion encode err: index out of range")
+	}
+	head := s.Index | uint16(s.Type&0b11)<<14
+	encodeArray := []byte{byte(head), byte(head >> 8)}
+	return append(encodeArray, s.Data...), nil
+}
+
+// CreateSegmentConfirm 根据收到的分帧报文生成确认帧，传输方向取反，序号与收到的分帧相同
+func CreateSegmentConfirm(p *ProtocolDlt698Model) ([]byte, error) {
+	if p.Segment == nil {
+		return nil, errors.New("create segment confirm err: not a segment frame")
+	}
+	dir := "1"
+	if p.Control.Dir == "1" {
+		dir = "0"
+	}
+	protocolDlt698Model := ProtocolDlt698Model{
+		Control: &ControlRegion{Dir: dir, Prm: p.Control.Prm, Framing: "1", Sc: "0", Func: p.Control.Func},
+		Address: &AddressRegion{AddressType: p.Address.AddressType, LogicAddress: p.Address.LogicAddress, Address: p.Address.Address, CA: p.Address.CA},
+		Segment: &SegmentRegion{Index: p.Segment.Index, Type: SegmentConfirm},
+	}
+	return protocolDlt698Model.Encoder()
+}
+
+// SplitFrame 将Encoder()生成的报文按最大帧长拆分为分帧报文
+// 报文长度不超过maxFrameSize时原样返回
+func SplitFrame(frame []byte, maxFrameSize int) ([][]byte, error) {
+	if len(frame) <= maxFrameSize {
+		return [][]byte{frame}, nil
+	}
+	p := &ProtocolDlt698Model{}
+	apduArray, err := p.decodeLink(frame)
+	if err != nil {
+		return nil, err
+	}
+	if p.Control.Framing == "1" {
+		return nil, errors.New("split frame err: frame is already a segment")
+	}
+	//分帧后每帧增加两个字节的分帧格式域
+	size := maxFrameSize - (len(frame) - len(apduArray)) - 2
+	if size <= 0 {
+		return nil, errors.New("split frame err: max frame size too small")
+	}
+	count := (len(apduArray) + size - 1) / size
+	if count > int(segmentMaxIndex)+1 {
+		return nil, errors.New("split frame err: too many segments")
+	}
+	control := *p.Control
+	control.Framing = "1"
+	frames := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * size
+		if end > len(apduArray) {
+			end = len(apduArray)
+		}
+		segmentType := SegmentMiddle
+		if i == 0 {
+			segmentType = SegmentFirst
+		} else if i == count-1 {
+			segmentType = SegmentLast
+		}
+		segment := ProtocolDlt698Model{
+			Control: &control,
+			Address: p.Address,
+			Segment: &SegmentRegion{Index: uint16(i), Type: segmentType, Data: apduArray[i*size : end]},
+		}
+		segmentArray, err := segment.Encoder()
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, segmentArray)
+	}
+	return frames, nil
+}
+
+/*----------------------分帧组装-----------------------------*/
+
+// Reassembler 分帧组装器，按服务器地址和客户机地址分别缓存分帧数据，可以并发使用
+// 零值可以直接使用，此时不限制APDU长度也不超时，NewReassembler设置了默认的限制
+type Reassembler struct {
+	MaxSize int           //组装后APDU的最大长度，超过时丢弃该地址的分帧数据，为0时不限制
+	Timeout time.Duration //两帧之间的最大间隔，超时未收到下一帧的分帧数据被丢弃，为0时不超时
+
+	mu      sync.Mutex
+	buffers map[string]*segmentBuffer
+}
+
+type segmentBuffer struct {
+	next     uint16 //期望的下一帧序号
+	data     []byte
+	received time.Time //最后一帧的接收时间
+}
+
+// NewReassembler 创建分帧组装器，APDU最大65535个字节，两帧之间最多间隔1分钟
+func NewReassembler() *Reassembler {
+	return &Reassembler{
+		MaxSize: 0xFFFF,
+		Timeout: time.Minute,
+		buffers: make(map[string]*segmentBuffer),
+	}
+}
+
+func segmentKey(a *AddressRegion) string {
+	return fmt.Sprintf("%s-%02x", a.Address, a.CA)
+}
+
+// Push 接收一个已解析的报文
+// 起始帧和中间帧返回需要回复的确认帧，最后帧返回组装完成的APDU
+// 非分帧报文直接返回其APDU，确认帧由发送方处理，这里忽略
+func (r *Reassembler) Push(p *ProtocolDlt698Model) (confirm []byte, apdu *APDU, err error) {
+	if p.Segment == nil {
+		return nil, p.Data, nil
+	}
+	key := segmentKey(p.Address)
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expire(now)
+	switch p.Segment.Type {
+	case SegmentConfirm:
+		return nil, nil, nil
+	case SegmentFirst:
+		if r.MaxSize > 0 && len(p.Segment.Data) > r.MaxSize {
+			delete(r.buffers, key)
+			return nil, nil, fmt.Errorf("segment %d of %s: apdu exceeds %d bytes", p.Segment.Index, key, r.MaxSize)
+		}
+		if r.buffers == nil {
+			r.buffers = make(map[string]*segmentBuffer)
+		}
+		r.buffers[key] = &segmentBuffer{
+			next:     (p.Segment.Index + 1) & segmentMaxIndex,
+			data:     append([]byte(nil), p.Segment.Data...),
+			received: now,
+		}
+		confirm, err = CreateSegmentConfirm(p)
+		return confirm, nil, err
+	}
+	b, ok := r.buffers[key]
+	if !ok {
+		return nil, nil, fmt.Errorf("segment %d of %s: first segment not received", p.Segment.Index, key)
+	}
+	if p.Segment.Index != b.next {
+		delete(r.buffers, key)
+		return nil, nil, fmt.Errorf("segment %d of %s: expect %d", p.Segment.Index, key, b.next)
+	}
+	if r.MaxSize > 0 && len(b.data)+len(p.Segment.Data) > r.MaxSize {
+		delete(r.buffers, key)
+		return nil, nil, fmt.Errorf("segment %d of %s: apdu exceeds %d bytes", p.Segment.Index, key, r.MaxSize)
+	}
+	b.data = append(b.data, p.Segment.Data...)
+	b.received = now
+	if p.Segment.Type == SegmentMiddle {
+		b.next = (b.next + 1) & segmentMaxIndex
+		confirm, err = CreateSegmentConfirm(p)
+		return confirm, nil, err
+	}
+	delete(r.buffers, key)
+	apdu = &APDU{}
+	if err = apdu.decoder(bytes.NewReader(b.data)); err != nil {
+		return nil, nil, err
+	}
+	return nil, apdu, nil
+}
+
+// expire 丢弃超时未收到下一帧的分帧数据，调用时持有锁
+func (r *Reassembler) expire(now time.Time) {
+	if r.Timeout <= 0 {
+		return
+	}
+	for key, b := range r.buffers {
+		if now.Sub(b.received) > r.Timeout {
+			delete(r.buffers, key)
+		}
+	}
+}
+
+// Reset 丢弃指定地址未组装完成的分帧数据
+func (r *Reassembler) Reset(address string, ca byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.buffers, segmentKey(&AddressRegion{Address: address, CA: ca}))
+}
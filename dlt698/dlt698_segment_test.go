@@ -0,0 +1,66 @@
+package dlt698
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReassembler(t *testing.T) {
+	frame, err := CreateGetResponseNormal("010203040506", 1, 1, []byte{0x40, 0x01, 0x02, 0x00}, &OctetString{Data: strings.Repeat("5a", 200)}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ProtocolDlt698Model{}
+	if err = want.DecodeByBytes(frame); err != nil {
+		t.Fatal(err)
+	}
+	wantAPDU, err := want.Data.encoder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames, err := SplitFrame(frame, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) < 3 {
+		t.Fatalf("%d segments", len(frames))
+	}
+
+	tests := []struct {
+		name        string
+		reassembler *Reassembler
+	}{
+		{"ZeroValue", &Reassembler{}},
+		{"NewReassembler", NewReassembler()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var apdu *APDU
+			for i, segment := range frames {
+				p := &ProtocolDlt698Model{}
+				if err := p.DecodeByBytes(segment); err != nil {
+					t.Fatal(err)
+				}
+				confirm, result, err := tt.reassembler.Push(p)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if i < len(frames)-1 && (confirm == nil || result != nil) {
+					t.Fatalf("segment %d: confirm = %x, apdu = %v", i, confirm, result)
+				}
+				apdu = result
+			}
+			if apdu == nil {
+				t.Fatal("apdu not reassembled")
+			}
+			data, err := apdu.encoder()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, wantAPDU) {
+				t.Fatalf("apdu = %x, want %x", data, wantAPDU)
+			}
+		})
+	}
+}
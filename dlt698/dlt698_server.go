@@ -0,0 +1,615 @@
+package dlt698
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// GetFunc 读取一个对象属性，返回DARCode类型的错误时应答对应的DAR，其它错误应答DarOther
+type GetFunc func(r *ServerRequest, oad []byte) (DataInter, error)
+
+// GetRecordFunc 读取一个记录型对象属性，返回结果的Rcsd为nil时使用请求中的RCSD
+// 返回(nil, nil)或结果的Data为nil时应答0条记录
+type GetRecordFunc func(r *ServerRequest, record *GetRecord) (*ResultRecord, error)
+
+// SetFunc 设置一个对象属性
+type SetFunc func(r *ServerRequest, oad []byte, data DataInter) error
+
+// ActionFunc 操作一个对象方法，返回操作返回数据，可以为nil
+type ActionFunc func(r *ServerRequest, omd *OMD, data DataInter) (DataInter, error)
+
+// ServerRequest 服务器收到的请求
+type ServerRequest struct {
+	Frame      *ProtocolDlt698Model //请求报文
+	Tsa        *TSA                 //代理请求的目标服务器地址，非代理请求为nil
+	Protection *Protection          //安全请求的保护方式，非安全请求为nil
+
+	dar DARCode //不为成功时所有对象直接应答该DAR，如时间标签无效
+}
+
+// Server 服务器(终端)端请求分发
+// 按OAD/OMD将读取、设置、操作及代理请求分发到注册的处理函数，并使用相同的PIID生成对应的响应
+// OAD的查找顺序为：完整OAD、元素索引为0的OAD、属性及元素索引都为0的OAD(对象级处理函数)
+type Server struct {
+	Address      string                                                                    //服务器地址，为空时应答任意地址
+	Connect      func(r *ServerRequest, request *ConnectRequest) *ConnectResponse          //建立应用连接，为nil时同意所有连接请求
+	TransCommand func(r *ServerRequest, request *ProxyTransCommandRequest) ([]byte, error) //透明转发，为nil时应答DarObjectUndefined
+	Security     *SecureChannel                                                            //安全传输，为nil时不处理安全请求及对称加密方式的建立应用连接
+	SecureWrite  bool                                                                      //为true时非安全请求中的设置、操作及透明转发应答DarSecurityUnmatched，为false时处理函数需要自行检查r.Protection
+
+	mu      sync.RWMutex
+	gets    map[uint32]GetFunc
+	records map[uint32]GetRecordFunc
+	sets    map[uint32]SetFunc
+	actions map[uint32]ActionFunc
+	proxies map[string]*Server
+}
+
+// NewServer 创建服务器
+// address 服务器地址，为空时应答任意地址
+func NewServer(address string) *Server {
+	return &Server{
+		Address: address,
+		gets:    make(map[uint32]GetFunc),
+		records: make(map[uint32]GetRecordFunc),
+		sets:    make(map[uint32]SetFunc),
+		actions: make(map[uint32]ActionFunc),
+		proxies: make(map[string]*Server),
+	}
+}
+
+// HandleGet 注册读取处理函数，oad为4个字节
+func (s *Server) HandleGet(oad []byte, handler GetFunc) {
+	s.mu.Lock()
+	s.gets[binary.BigEndian.Uint32(oad)] = handler
+	s.mu.Unlock()
+}
+
+// HandleGetRecord 注册记录型对象属性读取处理函数，oad为4个字节
+func (s *Server) HandleGetRecord(oad []byte, handler GetRecordFunc) {
+	s.mu.Lock()
+	s.records[binary.BigEndian.Uint32(oad)] = handler
+	s.mu.Unlock()
+}
+
+// HandleSet 注册设置处理函数，oad为4个字节
+func (s *Server) HandleSet(oad []byte, handler SetFunc) {
+	s.mu.Lock()
+	s.sets[binary.BigEndian.Uint32(oad)] = handler
+	s.mu.Unlock()
+}
+
+// HandleAction 注册方法处理函数，method为0时处理对象的所有方法
+func (s *Server) HandleAction(oi uint16, method byte, handler ActionFunc) {
+	s.mu.Lock()
+	s.actions[uint32(oi)<<8|uint32(method)] = handler
+	s.mu.Unlock()
+}
+
+// HandleProxy 注册代理目标服务器，代理请求中目标地址为address的对象交给target处理，按TSA的地址部分匹配
+func (s *Server) HandleProxy(address string, target *Server) {
+	s.mu.Lock()
+	s.proxies[address] = target
+	s.mu.Unlock()
+}
+
+// oadKeys OAD的查找顺序：完整OAD、元素索引为0的OAD、对象级
+func oadKeys(oad []byte) []uint32 {
+	if len(oad) != 4 {
+		return nil
+	}
+	key := binary.BigEndian.Uint32(oad)
+	return []uint32{key, key &^ 0xFF, key &^ 0xFFFF}
+}
+
+func (s *Server) lookupGet(oad []byte) GetFunc {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, key := range oadKeys(oad) {
+		if handler, ok := s.gets[key]; ok {
+			return handler
+		}
+	}
+	return nil
+}
+
+func (s *Server) lookupGetRecord(oad []byte) GetRecordFunc {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, key := range oadKeys(oad) {
+		if handler, ok := s.records[key]; ok {
+			return handler
+		}
+	}
+	return nil
+}
+
+func (s *Server) lookupSet(oad []byte) SetFunc {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, key := range oadKeys(oad) {
+		if handler, ok := s.sets[key]; ok {
+			return handler
+		}
+	}
+	return nil
+}
+
+/*----------------------------------连接-----------------------------------*/
+
+// Serve 在监听上接受连接，每个连接使用单独的协程处理
+func (s *Server) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			_ = s.ServeConn(conn)
+		}()
+	}
+}
+
+// ServeConn 处理一个连接上的请求直到连接关闭，连接关闭时返回nil
+func (s *Server) ServeConn(conn net.Conn) error {
+	defer conn.Close()
+	decoder := NewStreamDecoder(conn)
+	reassembler := NewReassembler()
+	for {
+		frame, err := decoder.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		model := &ProtocolDlt698Model{}
+		if err := model.DecodeByBytes(frame); err != nil {
+			continue
+		}
+		if model.Segment != nil {
+			confirm, apdu, err := reassembler.Push(model)
+			if err != nil {
+				continue
+			}
+			if confirm != nil {
+				if _, err := conn.Write(confirm); err != nil {
+					return err
+				}
+			}
+			if apdu == nil {
+				continue
+			}
+			model.Data = apdu
+			model.Segment = nil
+		}
+		response, err := s.Handle(model)
+		if err != nil || response == nil {
+			continue
+		}
+		responseArray, err := response.Encoder()
+		if err != nil {
+			continue
+		}
+		if _, err := conn.Write(responseArray); err != nil {
+			return err
+		}
+	}
+}
+
+// HandleBytes 处理一个请求报文，不需要应答时返回nil
+func (s *Server) HandleBytes(frame []byte) ([]byte, error) {
+	model := &ProtocolDlt698Model{}
+	if err := model.DecodeByBytes(frame); err != nil {
+		return nil, err
+	}
+	response, err := s.Handle(model)
+	if err != nil || response == nil {
+		return nil, err
+	}
+	return response.Encoder()
+}
+
+// Handle 处理一个请求，返回使用相同PIID的响应，不需要应答时返回nil
+// 时间标签超时时所有对象应答DarTimeTagInvalid，不支持的服务应答ErrorResponse
+func (s *Server) Handle(p *ProtocolDlt698Model) (*ProtocolDlt698Model, error) {
+	if p.Data == nil || p.Data.Data == nil {
+		return nil, errors.New("server: apdu == nil")
+	}
+	if s.Address != "" && p.Address.AddressType == 0 && p.Address.Address != s.Address {
+		return nil, nil
+	}
+	r := &ServerRequest{Frame: p}
+	var response *APDU
+	if request, ok := p.Data.Data.(*SecurityRequest); ok && s.Security != nil {
+		response = s.secure(r, request)
+	} else {
+		response = s.handleAPDU(r, p.Data)
+	}
+	if response == nil {
+		return nil, nil
+	}
+	address := *p.Address
+	if s.Address != "" {
+		address = AddressRegion{AddressType: 0, Address: s.Address, CA: p.Address.CA}
+	}
+	return &ProtocolDlt698Model{
+		Control: &ControlRegion{Dir: "1", Prm: "1", Framing: "0", Sc: "0", Func: "011"},
+		Address: &address,
+		Data:    response,
+	}, nil
+}
+
+// handleAPDU 生成请求APDU对应的响应APDU，不需要应答时返回nil
+func (s *Server) handleAPDU(r *ServerRequest, apdu *APDU) *APDU {
+	if apdu.TimeTag.Expired(time.Now()) {
+		r.dar = DarTimeTagInvalid
+	} else if s.SecureWrite && r.Protection == nil && isWriteRequest(apdu.Data) {
+		r.dar = DarSecurityUnmatched
+	}
+	response := s.respond(r, apdu.Data)
+	if response == nil {
+		if responseMark(apdu.Data) == "" {
+			return nil
+		}
+		response = &ErrorResponse{ErrorType: ErrorResponseUnsupported}
+	}
+	return &APDU{Pid: apdu.Pid, Data: response, TimeTag: apdu.TimeTag}
+}
+
+// isWriteRequest 是否为设置、操作或透明转发请求，包括代理请求
+func isWriteRequest(region APDURegion) bool {
+	switch region.(type) {
+	case *SetRequestNormal, *SetRequestNormalList, *SetThenGetRequestNormalList,
+		*ActionRequestNormal, *ActionRequestNormalList, *ActionThenGetRequestNormalList,
+		*ProxySetRequestList, *ProxySetThenGetRequestList, *ProxyActionRequestList,
+		*ProxyActionThenGetRequestList, *ProxyTransCommandRequest:
+		return true
+	}
+	return false
+}
+
+// secure 验证或解密安全请求后处理明文APDU，响应使用与请求相同的保护方式
+// 验证失败时应答异常错误，数据验证信息不匹配应答DarSecurityUnmatched，ESAM验证失败应答DarESAMVerifyFailed
+func (s *Server) secure(r *ServerRequest, request *SecurityRequest) *APDU {
+	apdu, protection, err := s.Security.Unwrap(request)
+	if err != nil {
+		return &APDU{Data: &SecurityResponse{DataType: SecurityDar, Dar: securityDAR(err)}}
+	}
+	r.Protection = protection
+	response := s.handleAPDU(r, apdu)
+	if response == nil {
+		return nil
+	}
+	wrapped, err := s.Security.Wrap(response, protection)
+	if err != nil {
+		return &APDU{Data: &SecurityResponse{DataType: SecurityDar, Dar: securityDAR(err)}}
+	}
+	return &APDU{Data: wrapped}
+}
+
+// securityDAR 安全传输的错误转换为DAR，ESAM的错误为DarESAMVerifyFailed
+func securityDAR(err error) *DAR {
+	var code DARCode
+	if errors.As(err, &code) {
+		return &DAR{Data: code}
+	}
+	return &DAR{Data: DarESAMVerifyFailed}
+}
+
+// respond 生成请求对应的响应APDU，不支持的请求返回nil
+func (s *Server) respond(r *ServerRequest, request APDURegion) APDURegion {
+	switch req := request.(type) {
+	case *ConnectRequest:
+		return s.connect(r, req)
+	case *ReleaseRequest:
+		return &ReleaseResponse{Result: 0}
+	case *GetRequestNormal:
+		return &GetResponseNormal{ResultNormal: s.get(r, req.OAD)}
+	case *GetRequestNormalList:
+		response := &GetResponseNormalList{ResultNormals: make([]*ResultNormal, len(req.OADs))}
+		for i, oad := range req.OADs {
+			response.ResultNormals[i] = s.get(r, oad)
+		}
+		return response
+	case *GetRequestRecord:
+		return &GetResponseRecord{ResultRecord: s.getRecord(r, req.GetRecord)}
+	case *GetRequestRecordList:
+		response := &GetResponseRecordList{ResultRecords: make([]*ResultRecord, len(req.GetRecords))}
+		for i, record := range req.GetRecords {
+			response.ResultRecords[i] = s.getRecord(r, record)
+		}
+		return response
+	case *GetRequestNext:
+		return &GetResponseNext{EndFlag: 1, FrameNumber: req.LastId, Dar: &DAR{Data: DarNoLongGetInProgress}}
+	case *SetRequestNormal:
+		return &SetResponseNormal{Oad: req.Oad, Dar: s.set(r, req.Oad, req.Data)}
+	case *SetRequestNormalList:
+		response := &SetResponseNormalList{Data: make([]*SetResponseNormal, len(req.Data))}
+		for i, item := range req.Data {
+			response.Data[i] = &SetResponseNormal{Oad: item.Oad, Dar: s.set(r, item.Oad, item.Data)}
+		}
+		return response
+	case *SetThenGetRequestNormalList:
+		return &SetThenGetResponseNormalList{Data: s.setThenGet(r, req.Data)}
+	case *ActionRequestNormal:
+		return s.action(r, req)
+	case *ActionRequestNormalList:
+		return &ActionResponseNormalList{Data: s.actionList(r, req.Data)}
+	case *ActionThenGetRequestNormalList:
+		return &ActionThenGetResponseNormalList{Data: s.actionThenGet(r, req.Data)}
+	case *ProxyGetRequestList:
+		response := &ProxyGetResponseList{Data: make([]*ProxyGetResponseListItem, len(req.Data))}
+		for i, item := range req.Data {
+			target, pr := s.proxy(r, item.Tsa)
+			results := make([]*ResultNormal, len(item.Oads))
+			for j, oad := range item.Oads {
+				results[j] = target.get(pr, oad)
+			}
+			response.Data[i] = &ProxyGetResponseListItem{Tsa: item.Tsa, Data: results}
+		}
+		return response
+	case *ProxyGetRequestRecord:
+		target, pr := s.proxy(r, req.Tsa)
+		record := &GetRecord{OAD: req.Oad, Rsd: req.Rsd, Rcsd: req.Rcsd}
+		return &ProxyGetResponseRecord{Tsa: req.Tsa, ResultRecord: target.getRecord(pr, record)}
+	case *ProxySetRequestList:
+		response := &ProxySetResponseList{Data: make([]*ProxySetResponseListItem, len(req.Data))}
+		for i, item := range req.Data {
+			target, pr := s.proxy(r, item.Tsa)
+			results := make([]*SetResponseNormal, len(item.Data))
+			for j, set := range item.Data {
+				results[j] = &SetResponseNormal{Oad: set.Oad, Dar: target.set(pr, set.Oad, set.Data)}
+			}
+			response.Data[i] = &ProxySetResponseListItem{Tsa: item.Tsa, Data: results}
+		}
+		return response
+	case *ProxySetThenGetRequestList:
+		response := &ProxySetThenGetResponseList{Data: make([]*ProxySetThenGetResponseListItem, len(req.Data))}
+		for i, item := range req.Data {
+			target, pr := s.proxy(r, item.Tsa)
+			response.Data[i] = &ProxySetThenGetResponseListItem{Tsa: item.Tsa, Data: target.setThenGet(pr, item.Data)}
+		}
+		return response
+	case *ProxyActionRequestList:
+		response := &ProxyActionResponseList{Data: make([]*ProxyActionResponseListItem, len(req.Data))}
+		for i, item := range req.Data {
+			target, pr := s.proxy(r, item.Tsa)
+			response.Data[i] = &ProxyActionResponseListItem{Tsa: item.Tsa, Data: target.actionList(pr, item.Data)}
+		}
+		return response
+	case *ProxyActionThenGetRequestList:
+		response := &ProxyActionThenGetResponseList{Data: make([]*ProxyActionThenGetResponseListItem, len(req.Data))}
+		for i, item := range req.Data {
+			target, pr := s.proxy(r, item.Tsa)
+			response.Data[i] = &ProxyActionThenGetResponseListItem{Tsa: item.Tsa, Data: target.actionThenGet(pr, item.Data)}
+		}
+		return response
+	case *ProxyTransCommandRequest:
+		return s.transCommand(r, req)
+	}
+	return nil
+}
+
+/*----------------------------------对象处理-----------------------------------*/
+
+func (s *Server) get(r *ServerRequest, oad []byte) *ResultNormal {
+	result := &ResultNormal{OAD: oad, GetResult: &GetResult{}}
+	if r.dar != DarSuccess {
+		result.GetResult.Data = &DAR{Data: r.dar}
+		return result
+	}
+	handler := s.lookupGet(oad)
+	if handler == nil {
+		result.GetResult.Data = &DAR{Data: DarObjectUndefined}
+		return result
+	}
+	data, err := handler(r, oad)
+	if err == nil && data == nil {
+		data = &Null{}
+	}
+	if err != nil {
+		result.GetResult.Data = toDAR(err)
+	} else {
+		result.GetResult.Data = data
+	}
+	return result
+}
+
+func (s *Server) getRecord(r *ServerRequest, record *GetRecord) *ResultRecord {
+	result := &ResultRecord{Oad: record.OAD, Rcsd: record.Rcsd}
+	if r.dar != DarSuccess {
+		result.Data = &DAR{Data: r.dar}
+		return result
+	}
+	handler := s.lookupGetRecord(record.OAD)
+	if handler == nil {
+		result.Data = &DAR{Data: DarObjectUndefined}
+		return result
+	}
+	response, err := handler(r, record)
+	if err != nil {
+		result.Data = toDAR(err)
+		return result
+	}
+	if response == nil {
+		response = &ResultRecord{}
+	}
+	if response.Data == nil {
+		response.Data = &RecordRow{}
+	}
+	if response.Oad == nil {
+		response.Oad = record.OAD
+	}
+	if response.Rcsd == nil {
+		response.Rcsd = record.Rcsd
+	}
+	return response
+}
+
+func (s *Server) set(r *ServerRequest, oad []byte, data DataInter) *DAR {
+	if r.dar != DarSuccess {
+		return &DAR{Data: r.dar}
+	}
+	handler := s.lookupSet(oad)
+	if handler == nil {
+		return &DAR{Data: DarObjectUndefined}
+	}
+	if err := handler(r, oad, data); err != nil {
+		return toDAR(err)
+	}
+	return &DAR{Data: DarSuccess}
+}
+
+func (s *Server) setThenGet(r *ServerRequest, items []*SetThenGetRequestItem) []*SetThenGetResponseNormalListItem {
+	results := make([]*SetThenGetResponseNormalListItem, len(items))
+	for i, item := range items {
+		results[i] = &SetThenGetResponseNormalListItem{
+			Oad:          item.SetOad,
+			Dar:          s.set(r, item.SetOad, item.Data),
+			ResultNormal: s.get(r, item.ReadOad),
+		}
+	}
+	return results
+}
+
+func (s *Server) action(r *ServerRequest, request *ActionRequestNormal) *ActionResponseNormal {
+	response := &ActionResponseNormal{Omd: request.OMD, DAR: &DAR{}}
+	if r.dar != DarSuccess {
+		response.DAR.Data = r.dar
+		return response
+	}
+	var handler ActionFunc
+	var ok bool
+	if request.OMD != nil && request.OMD.Oi != nil {
+		key := uint32(request.OMD.Oi.Data) << 8
+		s.mu.RLock()
+		if handler, ok = s.actions[key|uint32(request.OMD.FuncMark)]; !ok {
+			handler, ok = s.actions[key]
+		}
+		s.mu.RUnlock()
+	}
+	if !ok {
+		response.DAR.Data = DarObjectUndefined
+		return response
+	}
+	data, err := handler(r, request.OMD, request.Data)
+	if err != nil {
+		response.DAR = toDAR(err)
+		return response
+	}
+	response.Data = data
+	return response
+}
+
+func (s *Server) actionList(r *ServerRequest, items []*ActionRequestNormal) []*ActionResponseNormal {
+	results := make([]*ActionResponseNormal, len(items))
+	for i, item := range items {
+		results[i] = s.action(r, item)
+	}
+	return results
+}
+
+func (s *Server) actionThenGet(r *ServerRequest, items []*ActionThenGetRequestNormalListItem) []*ActionThenGetResponseNormalListItem {
+	results := make([]*ActionThenGetResponseNormalListItem, len(items))
+	for i, item := range items {
+		action := s.action(r, &ActionRequestNormal{OMD: item.Omd, Data: item.Data})
+		results[i] = &ActionThenGetResponseNormalListItem{
+			Omd:          item.Omd,
+			Dar:          action.DAR,
+			Data:         action.Data,
+			ResultNormal: s.get(r, item.Oad),
+		}
+	}
+	return results
+}
+
+// proxy 查找代理目标服务器，未注册的目标所有对象应答DarRequestTimeout
+func (s *Server) proxy(r *ServerRequest, tsa *TSA) (*Server, *ServerRequest) {
+	pr := &ServerRequest{Frame: r.Frame, Tsa: tsa, dar: r.dar}
+	s.mu.RLock()
+	target, ok := s.proxies[tsa.Address]
+	s.mu.RUnlock()
+	if !ok {
+		target = NewServer(tsa.Address)
+		if pr.dar == DarSuccess {
+			pr.dar = DarRequestTimeout
+		}
+	}
+	return target, pr
+}
+
+func (s *Server) transCommand(r *ServerRequest, request *ProxyTransCommandRequest) *ProxyTransCommandResponse {
+	response := &ProxyTransCommandResponse{Oad: request.Oad}
+	if r.dar != DarSuccess {
+		response.Dar = &DAR{Data: r.dar}
+		return response
+	}
+	if s.TransCommand == nil {
+		response.Dar = &DAR{Data: DarObjectUndefined}
+		return response
+	}
+	data, err := s.TransCommand(r, request)
+	if err != nil {
+		response.Dar = toDAR(err)
+		return response
+	}
+	response.Command = &OctetString{Data: hex.EncodeToString(data)}
+	return response
+}
+
+// connect 建立应用连接，未设置Connect时按请求的参数同意连接
+// 设置了Security时，同意的对称加密方式连接由ESAM完成会话密钥协商
+func (s *Server) connect(r *ServerRequest, request *ConnectRequest) *ConnectResponse {
+	var response *ConnectResponse
+	if s.Connect != nil {
+		response = s.Connect(r, request)
+	} else {
+		response = defaultConnectResponse(request)
+	}
+	symmetry, ok := request.ConnectMechanismInfo.(*SymmetrySecurity)
+	if !ok || s.Security == nil || response == nil {
+		return response
+	}
+	if response.ConnectResponseInfo == nil || response.ConnectResponseInfo.ConnectResult == ConnectResultSuccess {
+		response.ConnectResponseInfo = s.Security.Connect(symmetry)
+	}
+	return response
+}
+
+// defaultConnectResponse 按请求的参数同意连接
+func defaultConnectResponse(request *ConnectRequest) *ConnectResponse {
+	return &ConnectResponse{
+		FactoryVersion: &FactoryVersion{
+			ManuCode:            &VisibleString{Data: "0000"},
+			SoftwareVersion:     &VisibleString{Data: "0000"},
+			SoftwareVersionDate: &VisibleString{Data: "000000"},
+			HardwareVersion:     &VisibleString{Data: "0000"},
+			HardwareVersionDate: &VisibleString{Data: "000000"},
+			ExtendedInfo:        &VisibleString{Data: "00000000"},
+		},
+		ExpectVersion:              request.ExpectVersion,
+		ProtocolBlock:              request.ProtocolBlock,
+		FuncBlock:                  request.FuncBlock,
+		ServerSendMaxSize:          request.ClientReceiveMaxSize,
+		ServerReceiveMaxSize:       request.ClientSendMaxSize,
+		ServerReceiveWindowMaxSize: 1,
+		ServerHandleMaxSize:        request.ClientHandleMaxSize,
+		LinkTimeOut:                request.LinkTimeOut,
+		ConnectResponseInfo:        &ConnectResponseInfo{ConnectResult: 0},
+	}
+}
+
+// toDAR 处理函数返回的错误转换为DAR
+func toDAR(err error) *DAR {
+	var code DARCode
+	if errors.As(err, &code) {
+		return &DAR{Data: code}
+	}
+	return &DAR{Data: DarOther}
+}
@@ -0,0 +1,127 @@
+package dlt698
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const streamReadSize = 1024
+
+// StreamDecoder 从io.Reader中连续提取报文
+// 自动跳过前导的0xFE唤醒字节及无效数据，长度域、HCS、FCS或结束符校验失败时从下一个0x68重新同步
+// 读取出错时已缓存的数据会保留，可以在设置新的超时时间后继续调用
+type StreamDecoder struct {
+	r   io.Reader
+	buf []byte
+}
+
+func NewStreamDecoder(r io.Reader) *StreamDecoder {
+	return &StreamDecoder{r: r}
+}
+
+// Next 返回下一个校验通过的完整报文
+// 数据流结束时若没有残留数据返回io.EOF，否则返回io.ErrUnexpectedEOF
+func (s *StreamDecoder) Next() ([]byte, error) {
+	for {
+		frame, need := s.extract()
+		if frame != nil {
+			return frame, nil
+		}
+		if err := s.fill(need); err != nil {
+			if errors.Is(err, io.EOF) && len(s.buf) > 0 {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+	}
+}
+
+// Decode 读取下一个完整报文并解析
+// 报文校验通过但APDU解析失败时返回错误，该报文已从数据流中移除
+func (s *StreamDecoder) Decode() (*ProtocolDlt698Model, error) {
+	frame, err := s.Next()
+	if err != nil {
+		return nil, err
+	}
+	model := &ProtocolDlt698Model{}
+	if err := model.DecodeByBytes(frame); err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// Buffered 返回已缓存但尚未组成完整报文的字节数
+func (s *StreamDecoder) Buffered() int {
+	return len(s.buf)
+}
+
+// Reset 丢弃已缓存的数据，连接重建后使用
+func (s *StreamDecoder) Reset(r io.Reader) {
+	s.r = r
+	s.buf = s.buf[:0]
+}
+
+// fill 至少读取need个字节到缓存中
+func (s *StreamDecoder) fill(need int) error {
+	chunk := make([]byte, streamReadSize)
+	for need > 0 {
+		n, err := s.r.Read(chunk)
+		s.buf = append(s.buf, chunk[:n]...)
+		need -= n
+		if err != nil {
+			if n > 0 && need <= 0 {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// extract 在缓存中查找完整报文，找不到时返回还需要读取的字节数
+func (s *StreamDecoder) extract() ([]byte, int) {
+	for {
+		index := bytes.IndexByte(s.buf, StartChar)
+		if index < 0 {
+			s.buf = s.buf[:0]
+			return nil, 1
+		}
+		s.buf = s.buf[index:]
+		//起始符 + 长度域 + 控制域 + 地址特征
+		if len(s.buf) < 5 {
+			return nil, 5 - len(s.buf)
+		}
+		length, err := frameLength(binary.LittleEndian.Uint16(s.buf[1:3]))
+		addressLength := int(s.buf[4]&0x0F) + 1
+		//长度域 + 控制域 + 地址特征 + 地址 + 客户机地址
+		hcsLen := 2 + 1 + 1 + addressLength + 1
+		//帧头 + HCS + FCS
+		if err != nil || length < hcsLen+2+2 {
+			s.buf = s.buf[1:]
+			continue
+		}
+		if len(s.buf) < hcsLen+3 {
+			return nil, hcsLen + 3 - len(s.buf)
+		}
+		hcs := (&ProtocolDlt698Model{}).Cs(s.buf[1 : hcsLen+1])
+		if !bytes.Equal(hcs, s.buf[hcsLen+1:hcsLen+3]) {
+			s.buf = s.buf[1:]
+			continue
+		}
+		frameLen := length + 2
+		if len(s.buf) < frameLen {
+			return nil, frameLen - len(s.buf)
+		}
+		fcs := (&ProtocolDlt698Model{}).Cs(s.buf[1 : frameLen-3])
+		if !bytes.Equal(fcs, s.buf[frameLen-3:frameLen-1]) || s.buf[frameLen-1] != EndChar {
+			s.buf = s.buf[1:]
+			continue
+		}
+		frame := make([]byte, frameLen)
+		copy(frame, s.buf)
+		s.buf = s.buf[frameLen:]
+		return frame, 0
+	}
+}
@@ -406,7 +406,7 @@ func GetResponseMD5Error(address string, ca byte, Pid byte, oad []byte, errCode
 	}
 	getResponseMD5 := &GetResponseMD5{
 		Oad:  oad,
-		Data: &DAR{Data: errCode},
+		Data: &DAR{Data: DARCode(errCode)},
 	}
 	protocolDlt698Model := ProtocolDlt698Model{
 		Control: &ControlRegion{"1", "1", "0", "0", "011"},
@@ -509,7 +509,7 @@ func CreateSetResponseNormal(address string, ca byte, Pid byte, timeTag *TimeTag
 	}
 	setResponseNormal := &SetResponseNormal{
 		Oad: oad,
-		Dar: &DAR{Data: result},
+		Dar: &DAR{Data: DARCode(result)},
 	}
 	protocolDlt698Model := ProtocolDlt698Model{
 		Control: &ControlRegion{"1", "1", "0", "0", "011"},
@@ -549,7 +549,7 @@ func CreateSetThenGetResponseNormalListItem(oad []byte, result byte, road []byte
 	}
 	setThenGetResponseNormalListItem := &SetThenGetResponseNormalListItem{
 		Oad: oad,
-		Dar: &DAR{Data: result},
+		Dar: &DAR{Data: DARCode(result)},
 		ResultNormal: &ResultNormal{
 			OAD:       road,
 			GetResult: &GetResult{Data: data},
@@ -729,7 +729,7 @@ func CreateActionResponseNormal(address string, ca byte, Pid byte, timeTag *Time
 			FuncMark: funcMark,
 			Mode:     mode,
 		},
-		DAR:  &DAR{Data: result},
+		DAR:  &DAR{Data: DARCode(result)},
 		Data: data,
 	}
 	protocolDlt698Model := ProtocolDlt698Model{
@@ -761,7 +761,7 @@ func CreateActionResponseNormalItem(oi []byte, funcMark byte, mode byte, result
 			FuncMark: funcMark,
 			Mode:     mode,
 		},
-		DAR:  &DAR{Data: result},
+		DAR:  &DAR{Data: DARCode(result)},
 		Data: data,
 	}
 	return actionResponseNormal, err
@@ -812,7 +812,7 @@ func CreateActionThenGetResponseNormalListItem(oi []byte, funcMark byte, mode by
 		FuncMark: funcMark,
 		Mode:     mode,
 	}
-	dar := &DAR{Data: result}
+	dar := &DAR{Data: DARCode(result)}
 	resultNormal := &ResultNormal{
 		OAD: oad,
 		GetResult: &GetResult{
@@ -1001,7 +1001,7 @@ func CreateProxyGetRequestList(address string, ca byte, Pid byte, timeTag *TimeT
 // OAD 对象属性描述符
 // RSD 记录行选择描述符
 // RCSD 记录列选择描述符
-func CreateProxyGetRequestRecord(address string, ca byte, Pid byte, timeTag *TimeTag, timeOut uint16, tsa string, oad []byte, selector Selector, rcsd *RCSD) ([]byte, error) {
+func CreateProxyGetRequestRecord(address string, ca byte, Pid byte, timeTag *TimeTag, timeOut uint16, tsa *TSA, oad []byte, selector Selector, rcsd *RCSD) ([]byte, error) {
 	proxyGetRequestRecord := &ProxyGetRequestRecord{
 		TimeOut: timeOut,
 		Tsa:     tsa,
@@ -1016,3 +1016,385 @@ func CreateProxyGetRequestRecord(address string, ca byte, Pid byte, timeTag *Tim
 	}
 	return protocolDlt698Model.Encoder()
 }
+
+// CreateProxySetRequestList 请求代理设置若干个服务器的若干个对象属性
+// address 服务器地址SA
+// ca 客户机地址
+// Pid
+// timeTag 时间标签域
+// timeOut 代理整个请求的超时时间
+// data 结果集
+func CreateProxySetRequestList(address string, ca byte, Pid byte, timeTag *TimeTag, timeOut uint16, data ...*ProxySetRequestListItem) ([]byte, error) {
+	proxySetRequestList := &ProxySetRequestList{
+		TimeOut: &LongUnsigned{Data: timeOut},
+		Data:    data,
+	}
+	return proxyRequestFrame(address, ca, Pid, timeTag, proxySetRequestList)
+}
+
+// CreateProxySetThenGetRequestList 请求代理设置后读取若干个服务器的若干个对象属性
+// address 服务器地址SA
+// ca 客户机地址
+// Pid
+// timeTag 时间标签域
+// timeOut 代理整个请求的超时时间
+// data 结果集
+func CreateProxySetThenGetRequestList(address string, ca byte, Pid byte, timeTag *TimeTag, timeOut uint16, data ...*ProxySetThenGetRequestListItem) ([]byte, error) {
+	proxySetThenGetRequestList := &ProxySetThenGetRequestList{
+		TimeOut: &LongUnsigned{Data: timeOut},
+		Data:    data,
+	}
+	return proxyRequestFrame(address, ca, Pid, timeTag, proxySetThenGetRequestList)
+}
+
+// CreateProxyActionRequestList 请求代理操作若干个服务器的若干个对象方法
+// address 服务器地址SA
+// ca 客户机地址
+// Pid
+// timeTag 时间标签域
+// timeOut 代理整个请求的超时时间
+// data 结果集
+func CreateProxyActionRequestList(address string, ca byte, Pid byte, timeTag *TimeTag, timeOut uint16, data ...*ProxyActionRequestListItem) ([]byte, error) {
+	proxyActionRequestList := &ProxyActionRequestList{
+		TimeOut: &LongUnsigned{Data: timeOut},
+		Data:    data,
+	}
+	return proxyRequestFrame(address, ca, Pid, timeTag, proxyActionRequestList)
+}
+
+// CreateProxyActionThenGetRequestList 请求代理操作后读取若干个服务器的若干个对象方法和属性
+// address 服务器地址SA
+// ca 客户机地址
+// Pid
+// timeTag 时间标签域
+// timeOut 代理整个请求的超时时间
+// data 结果集
+func CreateProxyActionThenGetRequestList(address string, ca byte, Pid byte, timeTag *TimeTag, timeOut uint16, data ...*ProxyActionThenGetRequestListItem) ([]byte, error) {
+	proxyActionThenGetRequestList := &ProxyActionThenGetRequestList{
+		TimeOut: &LongUnsigned{Data: timeOut},
+		Data:    data,
+	}
+	return proxyRequestFrame(address, ca, Pid, timeTag, proxyActionThenGetRequestList)
+}
+
+// CreateProxyTransCommandRequest 请求代理透明转发命令
+// address 服务器地址SA
+// ca 客户机地址
+// Pid
+// timeTag 时间标签域
+// oad 数据转发端口
+// comdcb 端口通信控制块
+// recvTimeOut 接收等待报文超时时间，单位秒
+// byteTimeOut 接收等待字节超时时间，单位毫秒
+// command 透明转发命令(16进制字符串)
+func CreateProxyTransCommandRequest(address string, ca byte, Pid byte, timeTag *TimeTag, oad []byte, comdcb *COMDCB, recvTimeOut uint16, byteTimeOut uint16, command string) ([]byte, error) {
+	if len(oad) != 4 {
+		return nil, errors.New("oad size != 4")
+	}
+	if comdcb == nil {
+		return nil, errors.New("comdcb must not be nil")
+	}
+	proxyTransCommandRequest := &ProxyTransCommandRequest{
+		Oad:         oad,
+		Comdcb:      comdcb,
+		RecvTimeOut: recvTimeOut,
+		ByteTimeOut: byteTimeOut,
+		Command:     &OctetString{Data: command},
+	}
+	return proxyRequestFrame(address, ca, Pid, timeTag, proxyTransCommandRequest)
+}
+
+func proxyRequestFrame(address string, ca byte, Pid byte, timeTag *TimeTag, apdu APDURegion) ([]byte, error) {
+	protocolDlt698Model := ProtocolDlt698Model{
+		Control: &ControlRegion{Dir: "0", Prm: "1", Framing: "0", Sc: "0", Func: "011"},
+		Address: &AddressRegion{AddressType: 0, Address: address, CA: ca},
+		Data:    &APDU{Pid: Pid, Data: apdu, TimeTag: timeTag},
+	}
+	return protocolDlt698Model.Encoder()
+}
+
+// CreateProxyGetResponseList 响应代理读取若干个服务器的若干个对象属性
+// address 服务器地址SA
+// ca 客户机地址
+// Pid
+// timeTag 时间标签域
+// followReport 跟随上报信息域
+// data 结果集
+func CreateProxyGetResponseList(address string, ca byte, Pid byte, timeTag *TimeTag, followReport *FollowReport, data ...*ProxyGetResponseListItem) ([]byte, error) {
+	proxyGetResponseList := &ProxyGetResponseList{
+		Data: data,
+	}
+	return proxyResponseFrame(address, ca, Pid, timeTag, followReport, proxyGetResponseList)
+}
+
+// CreateProxyGetResponseRecord 响应代理读取一个服务器的一个记录型对象属性
+// address 服务器地址SA
+// ca 客户机地址
+// Pid
+// timeTag 时间标签域
+// followReport 跟随上报信息域
+// tsa 目标服务器地址
+// resultRecord 一个记录型对象属性及结果
+func CreateProxyGetResponseRecord(address string, ca byte, Pid byte, timeTag *TimeTag, followReport *FollowReport, tsa *TSA, resultRecord *ResultRecord) ([]byte, error) {
+	if resultRecord == nil {
+		return nil, errors.New("resultRecord must not be nil")
+	}
+	proxyGetResponseRecord := &ProxyGetResponseRecord{
+		Tsa:          tsa,
+		ResultRecord: resultRecord,
+	}
+	return proxyResponseFrame(address, ca, Pid, timeTag, followReport, proxyGetResponseRecord)
+}
+
+// CreateProxySetResponseList 响应代理设置若干个服务器的若干个对象属性
+// address 服务器地址SA
+// ca 客户机地址
+// Pid
+// timeTag 时间标签域
+// followReport 跟随上报信息域
+// data 结果集
+func CreateProxySetResponseList(address string, ca byte, Pid byte, timeTag *TimeTag, followReport *FollowReport, data ...*ProxySetResponseListItem) ([]byte, error) {
+	proxySetResponseList := &ProxySetResponseList{
+		Data: data,
+	}
+	return proxyResponseFrame(address, ca, Pid, timeTag, followReport, proxySetResponseList)
+}
+
+// CreateProxySetThenGetResponseList 响应代理设置后读取若干个服务器的若干个对象属性
+// address 服务器地址SA
+// ca 客户机地址
+// Pid
+// timeTag 时间标签域
+// followReport 跟随上报信息域
+// data 结果集
+func CreateProxySetThenGetResponseList(address string, ca byte, Pid byte, timeTag *TimeTag, followReport *FollowReport, data ...*ProxySetThenGetResponseListItem) ([]byte, error) {
+	proxySetThenGetResponseList := &ProxySetThenGetResponseList{
+		Data: data,
+	}
+	return proxyResponseFrame(address, ca, Pid, timeTag, followReport, proxySetThenGetResponseList)
+}
+
+// CreateProxyActionResponseList 响应代理操作若干个服务器的若干个对象方法
+// address 服务器地址SA
+// ca 客户机地址
+// Pid
+// timeTag 时间标签域
+// followReport 跟随上报信息域
+// data 结果集
+func CreateProxyActionResponseList(address string, ca byte, Pid byte, timeTag *TimeTag, followReport *FollowReport, data ...*ProxyActionResponseListItem) ([]byte, error) {
+	proxyActionResponseList := &ProxyActionResponseList{
+		Data: data,
+	}
+	return proxyResponseFrame(address, ca, Pid, timeTag, followReport, proxyActionResponseList)
+}
+
+// CreateProxyActionThenGetResponseList 响应代理操作后读取若干个服务器的若干个对象方法和属性
+// address 服务器地址SA
+// ca 客户机地址
+// Pid
+// timeTag 时间标签域
+// followReport 跟随上报信息域
+// data 结果集
+func CreateProxyActionThenGetResponseList(address string, ca byte, Pid byte, timeTag *TimeTag, followReport *FollowReport, data ...*ProxyActionThenGetResponseListItem) ([]byte, error) {
+	proxyActionThenGetResponseList := &ProxyActionThenGetResponseList{
+		Data: data,
+	}
+	return proxyResponseFrame(address, ca, Pid, timeTag, followReport, proxyActionThenGetResponseList)
+}
+
+// CreateProxyTransCommandResponse 响应代理透明转发命令
+// address 服务器地址SA
+// ca 客户机地址
+// Pid
+// timeTag 时间标签域
+// followReport 跟随上报信息域
+// oad 数据转发端口
+// data 返回数据(16进制字符串)
+func CreateProxyTransCommandResponse(address string, ca byte, Pid byte, timeTag *TimeTag, followReport *FollowReport, oad []byte, data string) ([]byte, error) {
+	if len(oad) != 4 {
+		return nil, errors.New("oad size != 4")
+	}
+	proxyTransCommandResponse := &ProxyTransCommandResponse{
+		Oad:     oad,
+		Command: &OctetString{Data: data},
+	}
+	return proxyResponseFrame(address, ca, Pid, timeTag, followReport, proxyTransCommandResponse)
+}
+
+// CreateProxyTransCommandResponseError 响应代理透明转发命令 错误信息
+// address 服务器地址SA
+// ca 客户机地址
+// Pid
+// timeTag 时间标签域
+// followReport 跟随上报信息域
+// oad 数据转发端口
+// result 错误信息
+func CreateProxyTransCommandResponseError(address string, ca byte, Pid byte, timeTag *TimeTag, followReport *FollowReport, oad []byte, result byte) ([]byte, error) {
+	if len(oad) != 4 {
+		return nil, errors.New("oad size != 4")
+	}
+	proxyTransCommandResponse := &ProxyTransCommandResponse{
+		Oad: oad,
+		Dar: &DAR{Data: DARCode(result)},
+	}
+	return proxyResponseFrame(address, ca, Pid, timeTag, followReport, proxyTransCommandResponse)
+}
+
+func proxyResponseFrame(address string, ca byte, Pid byte, timeTag *TimeTag, followReport *FollowReport, apdu APDURegion) ([]byte, error) {
+	protocolDlt698Model := ProtocolDlt698Model{
+		Control: &ControlRegion{Dir: "1", Prm: "1", Framing: "0", Sc: "0", Func: "011"},
+		Address: &AddressRegion{AddressType: 0, Address: address, CA: ca},
+		Data:    &APDU{Pid: Pid, Data: apdu, TimeTag: timeTag, FollowReport: followReport},
+	}
+	return protocolDlt698Model.Encoder()
+}
+
+// CreateSecurityRequestPlaintext 安全请求 明文应用数据单元
+// address 服务器地址SA
+// ca 客户机地址
+// apdu 明文应用数据单元
+// verify 数据验证信息，可以是 *SIDMAC(明文+MAC) *RN *RNMAC *SID
+func CreateSecurityRequestPlaintext(address string, ca byte, apdu *APDU, verify FrameRegion) ([]byte, error) {
+	if apdu == nil {
+		return nil, errors.New("apdu must not be nil")
+	}
+	securityRequest := &SecurityRequest{
+		DataType: SecurityPlaintext,
+		Apdu:     apdu,
+		Verify:   verify,
+	}
+	return securityRequestFrame(address, ca, securityRequest)
+}
+
+// CreateSecurityRequestPlaintextMAC 安全请求 明文+MAC
+// address 服务器地址SA
+// ca 客户机地址
+// apdu 明文应用数据单元
+// sidMac 数据验证码
+func CreateSecurityRequestPlaintextMAC(address string, ca byte, apdu *APDU, sidMac *SIDMAC) ([]byte, error) {
+	return CreateSecurityRequestPlaintext(address, ca, apdu, sidMac)
+}
+
+// CreateSecurityRequestPlaintextRN 安全请求 明文+随机数
+// address 服务器地址SA
+// ca 客户机地址
+// apdu 明文应用数据单元
+// rn 随机数
+func CreateSecurityRequestPlaintextRN(address string, ca byte, apdu *APDU, rn *RN) ([]byte, error) {
+	return CreateSecurityRequestPlaintext(address, ca, apdu, rn)
+}
+
+// CreateSecurityRequestPlaintextRNMAC 安全请求 明文+随机数+数据MAC
+// address 服务器地址SA
+// ca 客户机地址
+// apdu 明文应用数据单元
+// rnMac 随机数+数据MAC
+func CreateSecurityRequestPlaintextRNMAC(address string, ca byte, apdu *APDU, rnMac *RNMAC) ([]byte, error) {
+	return CreateSecurityRequestPlaintext(address, ca, apdu, rnMac)
+}
+
+// CreateSecurityRequestCiphertext 安全请求 密文
+// address 服务器地址SA
+// ca 客户机地址
+// ciphertext 密文应用数据单元(16进制字符串)
+// sid 安全标识
+func CreateSecurityRequestCiphertext(address string, ca byte, ciphertext string, sid *SID) ([]byte, error) {
+	securityRequest := &SecurityRequest{
+		DataType: SecurityCiphertext,
+		Data:     &OctetString{Data: ciphertext},
+		Verify:   sid,
+	}
+	return securityRequestFrame(address, ca, securityRequest)
+}
+
+// CreateSecurityRequestCiphertextMAC 安全请求 密文+MAC
+// address 服务器地址SA
+// ca 客户机地址
+// ciphertext 密文应用数据单元(16进制字符串)
+// sidMac 数据验证码
+func CreateSecurityRequestCiphertextMAC(address string, ca byte, ciphertext string, sidMac *SIDMAC) ([]byte, error) {
+	securityRequest := &SecurityRequest{
+		DataType: SecurityCiphertext,
+		Data:     &OctetString{Data: ciphertext},
+		Verify:   sidMac,
+	}
+	return securityRequestFrame(address, ca, securityRequest)
+}
+
+func securityRequestFrame(address string, ca byte, securityRequest *SecurityRequest) ([]byte, error) {
+	protocolDlt698Model := ProtocolDlt698Model{
+		Control: &ControlRegion{Dir: "0", Prm: "1", Framing: "0", Sc: "0", Func: "011"},
+		Address: &AddressRegion{AddressType: 0, Address: address, CA: ca},
+		Data:    &APDU{Data: securityRequest},
+	}
+	return protocolDlt698Model.Encoder()
+}
+
+// CreateSecurityResponsePlaintext 安全响应 明文，mac不为nil时为明文+MAC
+// address 服务器地址SA
+// ca 客户机地址
+// apdu 明文应用数据单元
+// mac 数据MAC，可以为nil
+func CreateSecurityResponsePlaintext(address string, ca byte, apdu *APDU, mac *MAC) ([]byte, error) {
+	if apdu == nil {
+		return nil, errors.New("apdu must not be nil")
+	}
+	securityResponse := &SecurityResponse{
+		DataType: SecurityPlaintext,
+		Apdu:     apdu,
+		Mac:      mac,
+	}
+	return securityResponseFrame(address, ca, securityResponse)
+}
+
+// CreateSecurityResponseCiphertext 安全响应 密文，mac不为nil时为密文+MAC
+// address 服务器地址SA
+// ca 客户机地址
+// ciphertext 密文应用数据单元(16进制字符串)
+// mac 数据MAC，可以为nil
+func CreateSecurityResponseCiphertext(address string, ca byte, ciphertext string, mac *MAC) ([]byte, error) {
+	securityResponse := &SecurityResponse{
+		DataType: SecurityCiphertext,
+		Data:     &OctetString{Data: ciphertext},
+		Mac:      mac,
+	}
+	return securityResponseFrame(address, ca, securityResponse)
+}
+
+// CreateSecurityResponseError 安全响应 异常错误
+// address 服务器地址SA
+// ca 客户机地址
+// dar 错误信息
+func CreateSecurityResponseError(address string, ca byte, dar *DAR) ([]byte, error) {
+	securityResponse := &SecurityResponse{
+		DataType: SecurityDar,
+		Dar:      dar,
+	}
+	return securityResponseFrame(address, ca, securityResponse)
+}
+
+func securityResponseFrame(address string, ca byte, securityResponse *SecurityResponse) ([]byte, error) {
+	protocolDlt698Model := ProtocolDlt698Model{
+		Control: &ControlRegion{Dir: "1", Prm: "1", Framing: "0", Sc: "0", Func: "011"},
+		Address: &AddressRegion{AddressType: 0, Address: address, CA: ca},
+		Data:    &APDU{Data: securityResponse},
+	}
+	return protocolDlt698Model.Encoder()
+}
+
+// CreateErrorResponse 异常响应
+// address 服务器地址SA
+// ca 客户机地址
+// Pid
+// errorType 异常类型 1-APDU无法解析 2-服务不支持 255-其他
+func CreateErrorResponse(address string, ca byte, Pid byte, errorType byte) ([]byte, error) {
+	errorResponse := &ErrorResponse{
+		ErrorType: errorType,
+	}
+	protocolDlt698Model := ProtocolDlt698Model{
+		Control: &ControlRegion{Dir: "1", Prm: "1", Framing: "0", Sc: "0", Func: "011"},
+		Address: &AddressRegion{AddressType: 0, Address: address, CA: ca},
+		Data:    &APDU{Pid: Pid, Data: errorResponse},
+	}
+	return protocolDlt698Model.Encoder()
+}
@@ -2,9 +2,9 @@ module zx1166y
 
 go 1.23.0
 
-require github.com/ecc1/spi v0.0.0-20230226182530-b0f4c20d714a
-
 require (
-	github.com/ecc1/gpio v0.0.0-20200212231225-d40e43fcf8f5 // indirect
-	golang.org/x/sys v0.5.0 // indirect
+	github.com/ecc1/spi v0.0.0-20230226182530-b0f4c20d714a
+	golang.org/x/sys v0.5.0
 )
+
+require github.com/ecc1/gpio v0.0.0-20200212231225-d40e43fcf8f5 // indirect
@@ -0,0 +1,444 @@
+package zx1166y
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	emulatorMACSize      = 4
+	emulatorKeySize      = 16
+	emulatorAttachSize   = 4 //附加数据长度
+	emulatorSessionLimit = 7200
+)
+
+// Emulator ESAM软件模拟器，实现0x55帧格式、状态字及ASCTR、ARCTR、AGSEQ计数器，结果只与密钥及命令顺序有关
+// 模拟器使用AES-128-CBC(零IV，0x80填充)加解密，数据MAC为HMAC-SHA256的前4个字节，与真实芯片的算法不同，只用于测试
+// 安全标识(SID)的约定：附加数据为4个字节，最后2个字节为数据长度，数据后为0或4个字节的MAC；安全标识最后1个字节的bit1为1时数据为密文
+type Emulator struct {
+	Number              []byte           //ESAM序列号，8字节
+	Version             []byte           //ESAM版本号，4字节
+	KeyVersion          []byte           //对称密钥版本，16字节
+	Key                 []byte           //对称密钥，16字节
+	MasterCertificate   []byte           //主站证书
+	TerminalCertificate []byte           //终端证书
+	SessionTimeLimit    uint32           //会话时效门限，秒
+	CounterLimit        uint32           //单地址应用协商计数器的上限，为0时不限制
+	Now                 func() time.Time //当前时间，为nil时使用time.Now
+
+	mu                  sync.Mutex
+	sessionKey          []byte
+	sessionTime         time.Time
+	asctr               uint32
+	arctr               uint32
+	agseq               uint32
+	meterSeq            uint32
+	masterCertVersion   byte
+	terminalCertVersion byte
+	closed              bool
+}
+
+// NewEmulator 使用对称密钥创建模拟器，key为16字节
+func NewEmulator(key []byte) *Emulator {
+	return &Emulator{
+		Number:              []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
+		Version:             []byte{0x00, 0x00, 0x00, 0x01},
+		KeyVersion:          make([]byte, 16),
+		Key:                 key,
+		MasterCertificate:   []byte{0x30, 0x00},
+		TerminalCertificate: []byte{0x30, 0x01},
+		SessionTimeLimit:    emulatorSessionLimit,
+	}
+}
+
+// Codec 返回使用模拟器通信的SPICodec
+func (e *Emulator) Codec() *SPICodec {
+	return NewCodec(e)
+}
+
+// Transfer 处理一个命令帧，应答帧写入rx
+func (e *Emulator) Transfer(tx, rx []byte) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return errors.New("模拟器已关闭！")
+	}
+	var response []byte
+	header, data, sw := e.parse(tx)
+	if sw == SWSuccess {
+		response, sw = e.execute(header, data)
+	}
+	frame := emulatorFrame(sw, response)
+	if len(frame) > len(rx) {
+		return errors.New("接收缓冲区长度不足！")
+	}
+	for i := range rx {
+		rx[i] = 0
+	}
+	copy(rx, frame)
+	return nil
+}
+
+func (e *Emulator) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.closed = true
+	return nil
+}
+
+// parse 解析命令帧 0x55 CLA INS P1 P2 Len1 Len2 Data CS
+func (e *Emulator) parse(tx []byte) ([]byte, []byte, StatusWord) {
+	start := bytes.IndexByte(tx, 0x55)
+	if start < 0 || len(tx) < start+7 {
+		return nil, nil, SWWrongLength
+	}
+	frame := tx[start+1:]
+	length := int(binary.BigEndian.Uint16(frame[4:6]))
+	if len(frame) < 6+length+1 {
+		return nil, nil, SWWrongLength
+	}
+	if (&SPICodec{}).Cs(frame[:6+length]) != frame[6+length] {
+		return nil, nil, SWChecksum
+	}
+	return frame[:4], frame[6 : 6+length], SWSuccess
+}
+
+// execute 按命令头执行命令
+func (e *Emulator) execute(header []byte, data []byte) ([]byte, StatusWord) {
+	switch {
+	case bytes.Equal(header, []byte{0x80, 0x02, 0x00, 0x00}):
+		return e.sessionKeyConnect(data)
+	case bytes.Equal(header[:3], []byte{0x80, 0x1C, 0x00}):
+		return e.encrypt(header[3], data)
+	case bytes.Equal(header, []byte{0x80, 0x0E, 0x40, 0x02}):
+		return emulatorMAC(e.Key, data), SWSuccess
+	case bytes.Equal(header, []byte{0x80, 0x14, 0x01, 0x03}):
+		return e.activeReport(data)
+	case bytes.Equal(header, []byte{0x81, 0x34, 0x01, 0x05}):
+		return e.updateSessionTimeLimit(data)
+	case bytes.Equal(header, []byte{0x81, 0x30, 0x02, 0x03}):
+		return e.certificateUpdate(data)
+	case bytes.Equal(header, []byte{0x81, 0x2E, 0x00, 0x00}):
+		return e.keyUpdate(data)
+	case bytes.Equal(header[:3], []byte{0x80, 0x36, 0x00}):
+		return e.selectInfo(header[3])
+	case bytes.Equal(header, []byte{0x80, 0x04, 0x00, 0x10}):
+		e.meterSeq++
+		return emulatorHMAC(e.Key, []byte("meter"), emulatorUint32(e.meterSeq))[:16], SWSuccess
+	case bytes.Equal(header, []byte{0x80, 0x0E, 0x48, 0x87}):
+		_, sw := verifyTail(e.Key, data)
+		return nil, sw
+	case bytes.Equal(header, []byte{0x80, 0x0C, 0x48, 0x07}):
+		return emulatorMAC(e.Key, data), SWSuccess
+	case bytes.Equal(header, []byte{0x80, 0x12, 0x48, 0x07}):
+		return verifyTail(e.Key, data)
+	case bytes.Equal(header[:3], []byte{0x80, 0x16, 0x48}):
+		e.agseq++
+		return e.verifySID(header, data)
+	case header[0] == 0x81:
+		return e.verifySID(header, data)
+	case header[0] == 0x80:
+		return nil, SWINSNotSupported
+	}
+	return nil, SWCLANotSupported
+}
+
+// sessionKeyConnect 会话密钥协商，输入密文1(32字节)及客户机签名1(4字节)，输出服务器随机数(48字节)及服务器签名(4字节)
+func (e *Emulator) sessionKeyConnect(data []byte) ([]byte, StatusWord) {
+	if len(data) != 32+emulatorMACSize {
+		return nil, SWWrongLength
+	}
+	secret, sign := data[:32], data[32:]
+	if !hmac.Equal(sign, emulatorMAC(e.Key, secret)) {
+		return nil, SWMACVerify
+	}
+	random, err := emulatorDecrypt(e.Key, secret)
+	if err != nil {
+		return nil, SWWrongData
+	}
+	if e.CounterLimit > 0 && e.asctr >= e.CounterLimit {
+		return nil, SWCounterExceeded
+	}
+	e.asctr++
+	counter := emulatorUint32(e.asctr)
+	e.sessionKey = emulatorHMAC(e.Key, []byte("session"), random, counter)[:emulatorKeySize]
+	e.sessionTime = e.now()
+	rn := append(emulatorHMAC(e.Key, []byte("rn"), random, counter), emulatorHMAC(e.Key, []byte("rn1"), random, counter)[:16]...)
+	return append(rn, emulatorMAC(e.sessionKey, rn)...), SWSuccess
+}
+
+// encrypt 11-明文+MAC输出MAC，96-密文输出密文，97-密文+MAC输出密文及MAC
+func (e *Emulator) encrypt(mode byte, data []byte) ([]byte, StatusWord) {
+	if !e.sessionValid() {
+		return nil, SWSecurityStatus
+	}
+	switch mode {
+	case 0x11:
+		return emulatorMAC(e.sessionKey, data), SWSuccess
+	case 0x96:
+		return emulatorEncrypt(e.sessionKey, data), SWSuccess
+	case 0x97:
+		return append(emulatorEncrypt(e.sessionKey, data), emulatorMAC(e.sessionKey, data)...), SWSuccess
+	}
+	return nil, SWWrongP1P2
+}
+
+// verifySID 按安全标识验证数据MAC，密文时输出明文，明文时输出原数据
+func (e *Emulator) verifySID(header []byte, data []byte) ([]byte, StatusWord) {
+	if !e.sessionValid() {
+		return nil, SWSecurityStatus
+	}
+	if len(data) < emulatorAttachSize {
+		return nil, SWWrongLength
+	}
+	length := int(binary.BigEndian.Uint16(data[2:4]))
+	rest := data[emulatorAttachSize:]
+	if len(rest) != length && len(rest) != length+emulatorMACSize {
+		return nil, SWWrongLength
+	}
+	content := rest[:length]
+	if mac := rest[length:]; len(mac) > 0 && !hmac.Equal(mac, emulatorMAC(e.sessionKey, content)) {
+		return nil, SWMACVerify
+	}
+	if header[3]&0x02 == 0 {
+		return content, SWSuccess
+	}
+	plaintext, err := emulatorDecrypt(e.sessionKey, content)
+	if err != nil {
+		return nil, SWWrongData
+	}
+	return plaintext, SWSuccess
+}
+
+// activeReport 主动上报，ARCTR加1，输出ARCTR(4字节)及MAC
+func (e *Emulator) activeReport(data []byte) ([]byte, StatusWord) {
+	e.arctr++
+	counter := emulatorUint32(e.arctr)
+	return append(counter, emulatorMAC(e.Key, counter, data)...), SWSuccess
+}
+
+// updateSessionTimeLimit 数据前4个字节为会话时效门限，后面可以跟4个字节的MAC
+func (e *Emulator) updateSessionTimeLimit(data []byte) ([]byte, StatusWord) {
+	switch len(data) {
+	case 4:
+	case 4 + emulatorMACSize:
+		if _, sw := verifyTail(e.Key, data); sw != SWSuccess {
+			return nil, sw
+		}
+	default:
+		return nil, SWWrongLength
+	}
+	e.SessionTimeLimit = binary.BigEndian.Uint32(data[:4])
+	return nil, SWSuccess
+}
+
+// certificateUpdate 附加数据后为新的主站证书
+func (e *Emulator) certificateUpdate(data []byte) ([]byte, StatusWord) {
+	if len(data) <= emulatorAttachSize {
+		return nil, SWWrongLength
+	}
+	e.MasterCertificate = append([]byte{}, data[emulatorAttachSize:]...)
+	e.masterCertVersion++
+	return nil, SWSuccess
+}
+
+// keyUpdate 附加数据后为使用原密钥加密的新密钥(16字节)及新密钥版本(16字节，可以省略)，最后为MAC
+func (e *Emulator) keyUpdate(data []byte) ([]byte, StatusWord) {
+	if len(data) <= emulatorAttachSize+emulatorMACSize {
+		return nil, SWWrongLength
+	}
+	content, sw := verifyTail(e.Key, data[emulatorAttachSize:])
+	if sw != SWSuccess {
+		return nil, sw
+	}
+	plaintext, err := emulatorDecrypt(e.Key, content)
+	if err != nil || (len(plaintext) != emulatorKeySize && len(plaintext) != emulatorKeySize+16) {
+		return nil, SWWrongData
+	}
+	e.Key = plaintext[:emulatorKeySize]
+	if len(plaintext) > emulatorKeySize {
+		e.KeyVersion = plaintext[emulatorKeySize:]
+	}
+	e.sessionKey = nil
+	return nil, SWSuccess
+}
+
+// selectInfo 0B-终端证书 0C-主站证书 FF-ESAM信息
+func (e *Emulator) selectInfo(p2 byte) ([]byte, StatusWord) {
+	switch p2 {
+	case 0x0B:
+		return e.TerminalCertificate, SWSuccess
+	case 0x0C:
+		return e.MasterCertificate, SWSuccess
+	case 0xFF:
+		buf := new(bytes.Buffer)
+		buf.Write(emulatorFixed(e.Number, 8))
+		buf.Write(emulatorFixed(e.Version, 4))
+		buf.Write(emulatorFixed(e.KeyVersion, 16))
+		buf.WriteByte(e.masterCertVersion)
+		buf.WriteByte(e.terminalCertVersion)
+		buf.Write(emulatorUint32(e.SessionTimeLimit))
+		buf.Write(emulatorUint32(e.remaining()))
+		buf.Write(emulatorUint32(e.asctr))
+		buf.Write(emulatorUint32(e.arctr))
+		buf.Write(emulatorUint32(e.agseq))
+		terminal := sha256.Sum256(e.TerminalCertificate)
+		buf.Write(terminal[:16])
+		master := sha256.Sum256(e.MasterCertificate)
+		buf.Write(master[:16])
+		return buf.Bytes(), SWSuccess
+	}
+	return nil, SWWrongP1P2
+}
+
+func (e *Emulator) now() time.Time {
+	if e.Now != nil {
+		return e.Now()
+	}
+	return time.Now()
+}
+
+// remaining 会话时效剩余时间，秒
+func (e *Emulator) remaining() uint32 {
+	if e.sessionKey == nil {
+		return 0
+	}
+	elapsed := uint32(e.now().Sub(e.sessionTime) / time.Second)
+	if elapsed >= e.SessionTimeLimit {
+		return 0
+	}
+	return e.SessionTimeLimit - elapsed
+}
+
+func (e *Emulator) sessionValid() bool {
+	return e.remaining() > 0
+}
+
+/*----------------------------------模拟主站-----------------------------------*/
+
+// MasterSession 模拟主站生成会话密钥协商的密文1(32字节)及客户机签名1，random为16字节
+func (e *Emulator) MasterSession(random []byte) (secret []byte, sign []byte) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	secret = emulatorEncrypt(e.Key, random)
+	return secret, emulatorMAC(e.Key, secret)
+}
+
+// MasterMAC 模拟主站使用当前会话密钥计算数据MAC
+func (e *Emulator) MasterMAC(data []byte) []byte {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return emulatorMAC(e.sessionKey, data)
+}
+
+// MasterEncrypt 模拟主站使用当前会话密钥加密
+func (e *Emulator) MasterEncrypt(data []byte) []byte {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return emulatorEncrypt(e.sessionKey, data)
+}
+
+// MasterDecrypt 模拟主站使用当前会话密钥解密
+func (e *Emulator) MasterDecrypt(data []byte) ([]byte, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return emulatorDecrypt(e.sessionKey, data)
+}
+
+// MasterSID 模拟主站生成SID的附加数据，数据长度为length
+func (e *Emulator) MasterSID(length int) []byte {
+	return []byte{0x00, 0x00, byte(length >> 8), byte(length)}
+}
+
+/*----------------------------------算法-----------------------------------*/
+
+// emulatorFrame 应答帧 0x55 SW1 SW2 Len1 Len2 Data CS
+func emulatorFrame(sw StatusWord, data []byte) []byte {
+	if sw != SWSuccess {
+		data = nil
+	}
+	frame := []byte{byte(sw >> 8), byte(sw), byte(len(data) >> 8), byte(len(data))}
+	frame = append(frame, data...)
+	cs := (&SPICodec{}).Cs(frame)
+	frame = append([]byte{0x55}, frame...)
+	return append(frame, cs)
+}
+
+// verifyTail 验证最后4个字节的MAC，返回MAC前的数据
+func verifyTail(key []byte, data []byte) ([]byte, StatusWord) {
+	if len(data) <= emulatorMACSize {
+		return nil, SWWrongLength
+	}
+	content := data[:len(data)-emulatorMACSize]
+	if !hmac.Equal(data[len(data)-emulatorMACSize:], emulatorMAC(key, content)) {
+		return nil, SWMACVerify
+	}
+	return content, SWSuccess
+}
+
+func emulatorHMAC(key []byte, data ...[]byte) []byte {
+	h := hmac.New(sha256.New, key)
+	for _, v := range data {
+		h.Write(v)
+	}
+	return h.Sum(nil)
+}
+
+func emulatorMAC(key []byte, data ...[]byte) []byte {
+	return emulatorHMAC(key, data...)[:emulatorMACSize]
+}
+
+// emulatorEncrypt AES-128-CBC，零IV，0x80填充
+func emulatorEncrypt(key []byte, data []byte) []byte {
+	block, err := aes.NewCipher(emulatorFixed(key, emulatorKeySize))
+	if err != nil {
+		return nil
+	}
+	padded := append(append([]byte{}, data...), 0x80)
+	for len(padded)%aes.BlockSize != 0 {
+		padded = append(padded, 0x00)
+	}
+	out := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, make([]byte, aes.BlockSize)).CryptBlocks(out, padded)
+	return out
+}
+
+func emulatorDecrypt(key []byte, data []byte) ([]byte, error) {
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, errors.New("密文长度错误！")
+	}
+	block, err := aes.NewCipher(emulatorFixed(key, emulatorKeySize))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, make([]byte, aes.BlockSize)).CryptBlocks(out, data)
+	end := bytes.LastIndexByte(out, 0x80)
+	if end < 0 || len(out)-end > aes.BlockSize {
+		return nil, errors.New("填充错误！")
+	}
+	for _, b := range out[end+1:] {
+		if b != 0 {
+			return nil, errors.New("填充错误！")
+		}
+	}
+	return out[:end], nil
+}
+
+func emulatorUint32(value uint32) []byte {
+	return []byte{byte(value >> 24), byte(value >> 16), byte(value >> 8), byte(value)}
+}
+
+// emulatorFixed 截断或补0到固定长度
+func emulatorFixed(data []byte, size int) []byte {
+	out := make([]byte, size)
+	copy(out, data)
+	return out
+}
@@ -0,0 +1,71 @@
+package zx1166y
+
+var _ ESAM = (*SPICodec)(nil)
+
+// ESAM 安全模块的命令集，与芯片的连接方式无关
+// SPICodec为硬件实现，Emulator.Codec()返回使用软件模拟器的实现，可以在没有芯片时测试上层的安全传输
+type ESAM interface {
+	Close() error
+
+	// SessionKeyConnect 建立应用连接（会话密钥协商）
+	SessionKeyConnect(ucOutSessionInit, ucOutSign []byte) (ucSessionData string, ucSign string, err error)
+	// UpdateSessionTimeLimit 更新会话时效门限
+	UpdateSessionTimeLimit(data []byte) error
+	// Encrypt 加密
+	Encrypt(encrypt EncryptType, data []byte) ([]byte, error)
+	// VerifyBySid 若数据验证信息为SID
+	VerifyBySid(secureFlag string, attachData, data []byte) ([]byte, error)
+	// VerifyBySidMac 若数据验证信息为SID_MAC
+	VerifyBySidMac(secureFlag string, attachData, data, mac []byte) ([]byte, error)
+	// VerifySelectSecureFlag 获取安全标识
+	VerifySelectSecureFlag(lastValidBit byte) string
+	// ReadTerminal 抄读终端
+	ReadTerminal(rn, data []byte) ([]byte, error)
+	// TerminalActiveReport1 终端主动上报 第1个步骤
+	TerminalActiveReport1(data []byte) (resultData []byte, mac []byte, err error)
+	// TerminalActiveReport2 终端主动上报 第2个步骤
+	TerminalActiveReport2(secureFlag string, attachData []byte, data []byte, mac []byte) ([]byte, error)
+
+	// CertificateUpdate 证书更新
+	CertificateUpdate(enData, sid, attachData []byte) error
+	// TerminalSymmetricKeyUpdate 终端对称密钥更新
+	TerminalSymmetricKeyUpdate(secureFlag, attachData, mac, enData []byte) ([]byte, error)
+	// SelectMasterStationCertificate 获取主站证书
+	SelectMasterStationCertificate() (string, error)
+	// SelectTerminalCertificate 获取终端证书
+	SelectTerminalCertificate() (string, error)
+	// SelectESAMInfos 获取ESAM信息
+	SelectESAMInfos() (*TESABInfo, error)
+
+	// ReadMeter1 终端抄读电表第1个步骤
+	ReadMeter1() ([]byte, error)
+	// ReadMeter8 终端抄读电表第8个步骤
+	ReadMeter8(meterId, rand, data, mac []byte) error
+	// ReadMeter9 终端抄读电表第9个步骤
+	ReadMeter9(meterId, rn, data []byte) ([]byte, error)
+	// ReadMeter10 终端抄读电表第10个步骤
+	ReadMeter10(meterId, rn, data, mac []byte) ([]byte, error)
+
+	// TransferString 发送十六进制字符串形式的完整命令帧
+	TransferString(tx string, length int) ([]byte, error)
+	// TransferBytes 发送完整命令帧，返回应答的数据
+	TransferBytes(tx []byte, length int) ([]byte, error)
+	// Cs 计算校验值
+	Cs(data []byte) byte
+}
+
+// DLT698ESAM 以字符串表示加密类型的ESAM，满足dlt698.ESAM接口，可以直接用于dlt698.NewSecureChannel
+// 除Encrypt外的方法与被包装的ESAM相同，本包不依赖dlt698，接口按方法集匹配
+type DLT698ESAM struct {
+	ESAM
+}
+
+// NewDLT698ESAM 包装ESAM，esam可以是SPICodec、Manager或Emulator.Codec()
+func NewDLT698ESAM(esam ESAM) *DLT698ESAM {
+	return &DLT698ESAM{ESAM: esam}
+}
+
+// Encrypt 加密，encrypt为加密类型的十六进制字符串，如"11"明文+MAC
+func (d *DLT698ESAM) Encrypt(encrypt string, data []byte) ([]byte, error) {
+	return d.ESAM.Encrypt(EncryptType(encrypt), data)
+}
@@ -0,0 +1,381 @@
+package zx1166y
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var _ ESAM = (*Manager)(nil)
+
+// Negotiator 会话密钥协商的主站侧，由主站或上层应用提供
+// 重新协商会改变ESAM的会话密钥，主站需要通过Complete取得服务器随机数及服务器签名计算新的会话密钥
+// Create及Complete调用时Manager不持有锁，可以访问网络或调用Manager的方法，协商进行中的命令不会再次触发协商
+type Negotiator struct {
+	Create   func() (sessionInit []byte, sign []byte, err error) //生成会话密钥协商的密文1及客户机签名1
+	Complete func(ucSessionData string, ucSign string) error     //接收ESAM返回的服务器随机数及服务器签名
+}
+
+// Session 当前会话的状态
+type Session struct {
+	Established time.Time     //通过Manager建立会话的时间，会话由其他程序建立时为零值
+	Limit       time.Duration //会话时效门限
+	Remaining   time.Duration //最后一次查询时的会话时效剩余时间，为0时没有会话
+	Checked     time.Time     //最后一次查询的时间
+}
+
+// Active 会话在t时是否有效
+func (s Session) Active(t time.Time) bool {
+	return s.Remaining > 0 && t.Before(s.ExpiresAt())
+}
+
+// ExpiresAt 会话的到期时间
+func (s Session) ExpiresAt() time.Time {
+	return s.Checked.Add(s.Remaining)
+}
+
+// Snapshot 用于监控的状态快照
+type Snapshot struct {
+	Session      Session
+	Info         TESABInfo //最后一次查询的ESAM信息，包括ASCTR、ARCTR、AGSEQ计数器及密钥、证书版本
+	Negotiations int       //Manager成功协商会话的次数
+	Failures     int       //Manager协商会话失败的次数
+	LastError    error     //最后一次协商或查询的错误
+	RetryAt      time.Time //协商失败后下一次自动协商的时间，没有失败时为零值
+}
+
+// Manager 串行访问ESAM并管理会话的生命周期
+// Manager实现ESAM接口，所有命令逐条执行，多个协程可以共享同一个Manager；同一个芯片的命令只能经由一个Manager发送
+// 会话密钥协商、更新会话时效门限、证书及密钥更新后查询ESAM信息，记录会话的剩余时间及计数器、版本；设置了Negotiate时，在会话到期前Margin重新协商并把结果交给Negotiate.Complete
+// 自动协商失败后Backoff内不再自动协商，Renegotiate不受限制
+type Manager struct {
+	ESAM      ESAM
+	Negotiate *Negotiator      //主动协商会话，为nil或Create、Complete为nil时不主动协商，只跟踪会话
+	Margin    time.Duration    //会话剩余时间小于Margin时重新协商
+	Backoff   time.Duration    //自动协商失败后的重试间隔，为0时每条命令都会重试
+	Now       func() time.Time //当前时间，为nil时使用time.Now
+
+	mu           sync.Mutex
+	session      Session
+	info         TESABInfo
+	negotiations int
+	failures     int
+	lastErr      error
+	retryAt      time.Time
+	negotiating  bool
+}
+
+// NewManager 创建ESAM管理，会话到期前5分钟重新协商，协商失败后1分钟重试
+func NewManager(esam ESAM, negotiate *Negotiator) *Manager {
+	return &Manager{ESAM: esam, Negotiate: negotiate, Margin: 5 * time.Minute, Backoff: time.Minute}
+}
+
+// Run 每隔interval查询一次ESAM信息，会话即将到期时重新协商，ctx结束时返回
+func (m *Manager) Run(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return errors.New("查询间隔必须大于0！")
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := m.Refresh(); err == nil {
+			_ = m.renew()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh 查询ESAM信息，更新会话及计数器
+func (m *Manager) Refresh() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.refresh()
+}
+
+// Renegotiate 立即重新协商会话，另一个协商正在进行时返回错误
+func (m *Manager) Renegotiate() error {
+	m.mu.Lock()
+	if m.negotiating {
+		m.mu.Unlock()
+		return errors.New("会话协商正在进行！")
+	}
+	m.negotiating = true
+	m.mu.Unlock()
+	return m.negotiate()
+}
+
+// Snapshot 最后一次查询时的状态，不访问芯片
+func (m *Manager) Snapshot() Snapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return Snapshot{
+		Session:      m.session,
+		Info:         m.info,
+		Negotiations: m.negotiations,
+		Failures:     m.failures,
+		LastError:    m.lastErr,
+		RetryAt:      m.retryAt,
+	}
+}
+
+func (m *Manager) now() time.Time {
+	if m.Now != nil {
+		return m.Now()
+	}
+	return time.Now()
+}
+
+// refresh 查询ESAM信息，调用时持有锁
+func (m *Manager) refresh() error {
+	info, err := m.ESAM.SelectESAMInfos()
+	if err != nil {
+		m.lastErr = err
+		return err
+	}
+	m.update(info)
+	return nil
+}
+
+func (m *Manager) update(info *TESABInfo) {
+	m.info = *info
+	m.session.Limit = time.Duration(info.SessionTimeLimit) * time.Second
+	m.session.Remaining = time.Duration(info.SessionTimeRemainingTime) * time.Second
+	m.session.Checked = m.now()
+}
+
+// renew 会话即将到期时重新协商，调用时不持有锁
+// 协商正在进行或失败后未到重试时间时直接返回
+func (m *Manager) renew() error {
+	if !m.Negotiate.valid() {
+		return nil
+	}
+	m.mu.Lock()
+	now := m.now()
+	if m.negotiating || now.Before(m.retryAt) || now.Add(m.Margin).Before(m.session.ExpiresAt()) {
+		m.mu.Unlock()
+		return nil
+	}
+	m.negotiating = true
+	m.mu.Unlock()
+	return m.negotiate()
+}
+
+// negotiate 协商会话，调用前置negotiating，只在芯片命令期间持有锁
+func (m *Manager) negotiate() (err error) {
+	defer func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.negotiating = false
+		if err != nil {
+			m.failures++
+			m.lastErr = err
+			m.retryAt = m.now().Add(m.Backoff)
+			return
+		}
+		m.negotiations++
+		m.retryAt = time.Time{}
+	}()
+	if !m.Negotiate.valid() {
+		return errors.New("没有会话协商数据！")
+	}
+	sessionInit, sign, err := m.Negotiate.Create()
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	ucSessionData, ucSign, err := m.sessionKeyConnect(sessionInit, sign)
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return m.Negotiate.Complete(ucSessionData, ucSign)
+}
+
+func (n *Negotiator) valid() bool {
+	return n != nil && n.Create != nil && n.Complete != nil
+}
+
+func (m *Manager) sessionKeyConnect(ucOutSessionInit, ucOutSign []byte) (string, string, error) {
+	ucSessionData, ucSign, err := m.ESAM.SessionKeyConnect(ucOutSessionInit, ucOutSign)
+	if err != nil {
+		return "", "", err
+	}
+	m.session.Established = m.now()
+	m.lastErr = nil
+	_ = m.refresh()
+	return ucSessionData, ucSign, nil
+}
+
+// withSession 使用会话密钥的命令，执行前按需重新协商，芯片应答安全状态不满足时会话失效
+func (m *Manager) withSession(f func() error) error {
+	_ = m.renew()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	err := f()
+	if errors.Is(err, SWSecurityStatus) {
+		m.session.Remaining = 0
+	}
+	return err
+}
+
+/*--------------------------------------------------------------------------*/
+
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ESAM.Close()
+}
+
+func (m *Manager) SessionKeyConnect(ucOutSessionInit, ucOutSign []byte) (ucSessionData string, ucSign string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.sessionKeyConnect(ucOutSessionInit, ucOutSign)
+}
+
+func (m *Manager) UpdateSessionTimeLimit(data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.ESAM.UpdateSessionTimeLimit(data); err != nil {
+		return err
+	}
+	_ = m.refresh()
+	return nil
+}
+
+func (m *Manager) Encrypt(encrypt EncryptType, data []byte) (result []byte, err error) {
+	err = m.withSession(func() error {
+		result, err = m.ESAM.Encrypt(encrypt, data)
+		return err
+	})
+	return result, err
+}
+
+func (m *Manager) VerifyBySid(secureFlag string, attachData, data []byte) (result []byte, err error) {
+	err = m.withSession(func() error {
+		result, err = m.ESAM.VerifyBySid(secureFlag, attachData, data)
+		return err
+	})
+	return result, err
+}
+
+func (m *Manager) VerifyBySidMac(secureFlag string, attachData, data, mac []byte) (result []byte, err error) {
+	err = m.withSession(func() error {
+		result, err = m.ESAM.VerifyBySidMac(secureFlag, attachData, data, mac)
+		return err
+	})
+	return result, err
+}
+
+func (m *Manager) VerifySelectSecureFlag(lastValidBit byte) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ESAM.VerifySelectSecureFlag(lastValidBit)
+}
+
+func (m *Manager) ReadTerminal(rn, data []byte) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ESAM.ReadTerminal(rn, data)
+}
+
+func (m *Manager) TerminalActiveReport1(data []byte) (resultData []byte, mac []byte, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ESAM.TerminalActiveReport1(data)
+}
+
+func (m *Manager) TerminalActiveReport2(secureFlag string, attachData []byte, data []byte, mac []byte) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ESAM.TerminalActiveReport2(secureFlag, attachData, data, mac)
+}
+
+func (m *Manager) CertificateUpdate(enData, sid, attachData []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.ESAM.CertificateUpdate(enData, sid, attachData); err != nil {
+		return err
+	}
+	_ = m.refresh()
+	return nil
+}
+
+func (m *Manager) TerminalSymmetricKeyUpdate(secureFlag, attachData, mac, enData []byte) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result, err := m.ESAM.TerminalSymmetricKeyUpdate(secureFlag, attachData, mac, enData)
+	if err != nil {
+		return nil, err
+	}
+	_ = m.refresh()
+	return result, nil
+}
+
+func (m *Manager) SelectMasterStationCertificate() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ESAM.SelectMasterStationCertificate()
+}
+
+func (m *Manager) SelectTerminalCertificate() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ESAM.SelectTerminalCertificate()
+}
+
+// SelectESAMInfos 获取ESAM信息，同时更新会话及计数器
+func (m *Manager) SelectESAMInfos() (*TESABInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	info, err := m.ESAM.SelectESAMInfos()
+	if err != nil {
+		return nil, err
+	}
+	m.update(info)
+	return info, nil
+}
+
+func (m *Manager) ReadMeter1() ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ESAM.ReadMeter1()
+}
+
+func (m *Manager) ReadMeter8(meterId, rand, data, mac []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ESAM.ReadMeter8(meterId, rand, data, mac)
+}
+
+func (m *Manager) ReadMeter9(meterId, rn, data []byte) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ESAM.ReadMeter9(meterId, rn, data)
+}
+
+func (m *Manager) ReadMeter10(meterId, rn, data, mac []byte) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ESAM.ReadMeter10(meterId, rn, data, mac)
+}
+
+func (m *Manager) TransferString(tx string, length int) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ESAM.TransferString(tx, length)
+}
+
+func (m *Manager) TransferBytes(tx []byte, length int) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ESAM.TransferBytes(tx, length)
+}
+
+func (m *Manager) Cs(data []byte) byte {
+	return m.ESAM.Cs(data)
+}
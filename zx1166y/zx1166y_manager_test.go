@@ -0,0 +1,158 @@
+package zx1166y
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// testClock 模拟器和Manager共用的时间
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+// newTestManager 使用模拟器的Manager，create为nil时使用模拟主站生成协商数据
+func newTestManager(create func() ([]byte, []byte, error)) (*Manager, *Emulator, *testClock, *int, *int) {
+	clock := &testClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)}
+	emulator := NewEmulator([]byte("0123456789abcdef"))
+	emulator.Now = clock.Now
+	creates, completes := 0, 0
+	manager := NewManager(emulator.Codec(), &Negotiator{
+		Create: func() ([]byte, []byte, error) {
+			creates++
+			if create != nil {
+				return create()
+			}
+			secret, sign := emulator.MasterSession(bytes.Repeat([]byte{0x22}, 16))
+			return secret, sign, nil
+		},
+		Complete: func(ucSessionData string, ucSign string) error {
+			completes++
+			return nil
+		},
+	})
+	manager.Now = clock.Now
+	return manager, emulator, clock, &creates, &completes
+}
+
+func TestManagerRenewAtMargin(t *testing.T) {
+	manager, _, clock, creates, completes := newTestManager(nil)
+	if err := manager.Renegotiate(); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := manager.Snapshot()
+	if snapshot.Negotiations != 1 || *creates != 1 || *completes != 1 {
+		t.Fatalf("negotiations = %d, creates = %d, completes = %d", snapshot.Negotiations, *creates, *completes)
+	}
+	if snapshot.Session.Remaining != emulatorSessionLimit*time.Second || !snapshot.Session.Established.Equal(clock.now) {
+		t.Fatalf("session = %+v", snapshot.Session)
+	}
+
+	//到期前Margin之外不协商
+	expires := snapshot.Session.ExpiresAt()
+	clock.now = expires.Add(-manager.Margin - time.Second)
+	if _, err := manager.Encrypt(Plaintext_MAC, []byte{0x01, 0x02}); err != nil {
+		t.Fatal(err)
+	}
+	if *creates != 1 {
+		t.Fatalf("creates = %d before margin", *creates)
+	}
+
+	//进入Margin时协商
+	clock.now = expires.Add(-manager.Margin)
+	if _, err := manager.Encrypt(Plaintext_MAC, []byte{0x01, 0x02}); err != nil {
+		t.Fatal(err)
+	}
+	snapshot = manager.Snapshot()
+	if *creates != 2 || *completes != 2 || snapshot.Negotiations != 2 {
+		t.Fatalf("negotiations = %d, creates = %d, completes = %d", snapshot.Negotiations, *creates, *completes)
+	}
+	if snapshot.Info.ASCTR != 2 || !snapshot.Session.ExpiresAt().Equal(clock.now.Add(emulatorSessionLimit*time.Second)) {
+		t.Fatalf("snapshot = %+v", snapshot)
+	}
+}
+
+func TestManagerBackoff(t *testing.T) {
+	failure := errors.New("master offline")
+	manager, _, clock, creates, completes := newTestManager(func() ([]byte, []byte, error) {
+		return nil, nil, failure
+	})
+
+	//没有会话，第一条命令触发协商，失败后命令照常执行
+	if _, err := manager.Encrypt(Plaintext_MAC, []byte{0x01}); !errors.Is(err, SWSecurityStatus) {
+		t.Fatalf("encrypt err = %v", err)
+	}
+	snapshot := manager.Snapshot()
+	if *creates != 1 || snapshot.Failures != 1 || !errors.Is(snapshot.LastError, failure) {
+		t.Fatalf("creates = %d, snapshot = %+v", *creates, snapshot)
+	}
+	if !snapshot.RetryAt.Equal(clock.now.Add(manager.Backoff)) {
+		t.Fatalf("retry at = %v", snapshot.RetryAt)
+	}
+
+	//重试时间之前不再协商
+	clock.now = clock.now.Add(manager.Backoff - time.Second)
+	for i := 0; i < 3; i++ {
+		_, _ = manager.VerifyBySid("81020002", []byte{0x00, 0x00, 0x00, 0x01}, []byte{0x01})
+	}
+	if *creates != 1 {
+		t.Fatalf("creates = %d during backoff", *creates)
+	}
+
+	//到达重试时间后再次协商
+	clock.now = clock.now.Add(time.Second)
+	_, _ = manager.Encrypt(Plaintext_MAC, []byte{0x01})
+	if *creates != 2 || manager.Snapshot().Failures != 2 || *completes != 0 {
+		t.Fatalf("creates = %d, failures = %d", *creates, manager.Snapshot().Failures)
+	}
+
+	//Renegotiate不受重试时间限制
+	if err := manager.Renegotiate(); !errors.Is(err, failure) {
+		t.Fatalf("renegotiate err = %v", err)
+	}
+	if *creates != 3 {
+		t.Fatalf("creates = %d after renegotiate", *creates)
+	}
+}
+
+func TestManagerCompleteReentrant(t *testing.T) {
+	manager, _, _, _, _ := newTestManager(nil)
+	var mac []byte
+	var completeErr error
+	manager.Negotiate.Complete = func(ucSessionData string, ucSign string) error {
+		//回调中调用Manager的方法不会死锁，也不会再次触发协商
+		mac, completeErr = manager.Encrypt(Plaintext_MAC, []byte{0x01, 0x02})
+		return completeErr
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- manager.Renegotiate()
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("renegotiate deadlocked")
+	}
+	if completeErr != nil || len(mac) == 0 {
+		t.Fatalf("encrypt in complete: %x, %v", mac, completeErr)
+	}
+	if snapshot := manager.Snapshot(); snapshot.Negotiations != 1 || snapshot.Info.ASCTR != 1 {
+		t.Fatalf("snapshot = %+v", snapshot)
+	}
+}
+
+func TestManagerRunInterval(t *testing.T) {
+	manager, _, _, _, _ := newTestManager(nil)
+	if err := manager.Run(context.Background(), 0); err == nil {
+		t.Fatal("run with zero interval")
+	}
+}
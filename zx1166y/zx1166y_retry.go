@@ -0,0 +1,40 @@
+package zx1166y
+
+import (
+	"errors"
+	"time"
+)
+
+// RetryPolicy 命令级的重试策略
+// 芯片忙、应答校验错误时重发命令；应答未就绪时不重发命令，只继续读取应答，用于证书更新等耗时较长的命令
+// 重发命令会使芯片再次执行，计数器类的命令可能因此递增，Attempts按需要设置
+type RetryPolicy struct {
+	Attempts  int              //重发命令的最多次数，为0时不重发
+	Polls     int              //应答未就绪时读取应答的最多次数，为0时不等待
+	Interval  time.Duration    //两次重发或读取之间的等待时间
+	Retryable func(error) bool //判断错误是否需要重发命令，为nil时使用IsTransient
+}
+
+// NewRetryPolicy 默认的重试策略，重发3次，应答未就绪时每20毫秒读取一次，最多100次
+// 等待应答的总时间为Polls与Interval的乘积，默认约2秒，修改其中之一时随之变化
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{Attempts: 3, Polls: 100, Interval: 20 * time.Millisecond}
+}
+
+// IsTransient 是否为重发命令可以恢复的错误：芯片忙或应答校验错误
+func IsTransient(err error) bool {
+	return errors.Is(err, ErrBusy) || errors.Is(err, ErrChecksum)
+}
+
+func (r *RetryPolicy) retryable(err error) bool {
+	if r.Retryable != nil {
+		return r.Retryable(err)
+	}
+	return IsTransient(err)
+}
+
+func (r *RetryPolicy) wait() {
+	if r.Interval > 0 {
+		time.Sleep(r.Interval)
+	}
+}
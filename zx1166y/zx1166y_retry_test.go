@@ -0,0 +1,110 @@
+package zx1166y
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+// scriptedTransport 按顺序返回预设的应答，nil表示芯片忙(应答全为0xFF)，最后一个应答重复使用
+// tx中有0x55时记为发送命令，全为0时记为只读取应答
+type scriptedTransport struct {
+	replies  [][]byte
+	commands int
+	polls    int
+}
+
+func (s *scriptedTransport) Transfer(tx, rx []byte) error {
+	index := s.commands + s.polls
+	if bytes.IndexByte(tx, 0x55) >= 0 {
+		s.commands++
+	} else {
+		s.polls++
+	}
+	if index >= len(s.replies) {
+		index = len(s.replies) - 1
+	}
+	reply := s.replies[index]
+	for i := range rx {
+		if reply == nil {
+			rx[i] = 0xFF
+		} else {
+			rx[i] = 0
+		}
+	}
+	copy(rx, reply)
+	return nil
+}
+
+func (s *scriptedTransport) Close() error {
+	return nil
+}
+
+var (
+	testReply    = emulatorFrame(SWSuccess, []byte{0xAA, 0xBB})
+	testNotReady = []byte{0x00}
+)
+
+func testBadChecksum() []byte {
+	frame := append([]byte{}, testReply...)
+	frame[len(frame)-1] ^= 0xFF
+	return frame
+}
+
+var retryTests = []struct {
+	name     string
+	retry    *RetryPolicy
+	replies  [][]byte
+	err      error
+	commands int
+	polls    int
+}{
+	{"NoRetry", nil, [][]byte{nil, testReply}, ErrBusy, 1, 0},
+	{"BusyNotReadyValid", &RetryPolicy{Attempts: 3, Polls: 3}, [][]byte{nil, testNotReady, testReply}, nil, 2, 1},
+	{"ChecksumResend", &RetryPolicy{Attempts: 3}, [][]byte{testBadChecksum(), testReply}, nil, 2, 0},
+	{"AttemptsBound", &RetryPolicy{Attempts: 2, Polls: 3}, [][]byte{nil}, ErrBusy, 3, 0},
+	{"NotReadyPollOnly", &RetryPolicy{Attempts: 3, Polls: 3}, [][]byte{testNotReady, testNotReady, testReply}, nil, 1, 2},
+	{"PollsBound", &RetryPolicy{Attempts: 3, Polls: 4}, [][]byte{testNotReady}, ErrNotReady, 1, 4},
+	{"PollThenChecksum", &RetryPolicy{Attempts: 1, Polls: 3}, [][]byte{testNotReady, testBadChecksum(), testReply}, nil, 2, 1},
+	{"StatusWordNotRetried", &RetryPolicy{Attempts: 3}, [][]byte{emulatorFrame(SWMACVerify, nil)}, SWMACVerify, 1, 0},
+	{"Retryable", &RetryPolicy{Attempts: 3, Retryable: func(err error) bool { return errors.Is(err, SWMACVerify) }},
+		[][]byte{emulatorFrame(SWMACVerify, nil), nil, testReply}, ErrBusy, 2, 0},
+}
+
+func TestRetryPolicy(t *testing.T) {
+	for _, tt := range retryTests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &scriptedTransport{replies: tt.replies}
+			codec := NewCodec(transport)
+			codec.Retry = tt.retry
+			tx, err := codec.encode("80360000")
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := codec.TransferBytes(tx, 64)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			} else if !bytes.Equal(data, []byte{0xAA, 0xBB}) {
+				t.Fatalf("data = %x", data)
+			}
+			if transport.commands != tt.commands || transport.polls != tt.polls {
+				t.Fatalf("commands = %d, polls = %d, want %d, %d", transport.commands, transport.polls, tt.commands, tt.polls)
+			}
+		})
+	}
+}
+
+func TestNewRetryPolicy(t *testing.T) {
+	retry := NewRetryPolicy()
+	if retry.Attempts != 3 || retry.Polls != 100 || time.Duration(retry.Polls)*retry.Interval != 2*time.Second {
+		t.Fatalf("retry = %+v", retry)
+	}
+	if !retry.retryable(ErrBusy) || !retry.retryable(ErrChecksum) || retry.retryable(ErrNotReady) || retry.retryable(SWSecurityStatus) {
+		t.Fatal("default retryable errors")
+	}
+}
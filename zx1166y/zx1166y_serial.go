@@ -0,0 +1,70 @@
+//go:build linux
+
+package zx1166y
+
+import (
+	"errors"
+	"os"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// 串口波特率
+var serialBauds = map[int]uint32{
+	1200:   unix.B1200,
+	2400:   unix.B2400,
+	4800:   unix.B4800,
+	9600:   unix.B9600,
+	19200:  unix.B19200,
+	38400:  unix.B38400,
+	57600:  unix.B57600,
+	115200: unix.B115200,
+	230400: unix.B230400,
+	460800: unix.B460800,
+	921600: unix.B921600,
+}
+
+// OpenSerial 打开串口，工作在原始模式
+func OpenSerial(config SerialConfig) (Transport, error) {
+	baud, ok := serialBauds[config.Baud]
+	if !ok {
+		return nil, errors.New("不支持的波特率：" + strconv.Itoa(config.Baud))
+	}
+	fd, err := unix.Open(config.Name, unix.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	termios := &unix.Termios{
+		Cflag:  baud | unix.CS8 | unix.CREAD | unix.CLOCAL,
+		Ispeed: baud,
+		Ospeed: baud,
+	}
+	switch config.Parity {
+	case 0, 'N':
+	case 'E':
+		termios.Cflag |= unix.PARENB
+	case 'O':
+		termios.Cflag |= unix.PARENB | unix.PARODD
+	default:
+		_ = unix.Close(fd)
+		return nil, errors.New("不支持的校验位：" + string(config.Parity))
+	}
+	switch config.StopBits {
+	case 0, 1:
+	case 2:
+		termios.Cflag |= unix.CSTOPB
+	default:
+		_ = unix.Close(fd)
+		return nil, errors.New("不支持的停止位：" + strconv.Itoa(config.StopBits))
+	}
+	termios.Cc[unix.VMIN] = 1
+	if err = unix.IoctlSetTermios(fd, unix.TCSETS, termios); err != nil {
+		_ = unix.Close(fd)
+		return nil, err
+	}
+	//清空收发缓冲区中残留的数据
+	_ = unix.IoctlSetInt(fd, unix.TCFLSH, unix.TCIOFLUSH)
+	//非阻塞的文件由运行时轮询，读写超时才能生效
+	return NewStreamTransport(os.NewFile(uintptr(fd), config.Name), config.Timeout), nil
+}
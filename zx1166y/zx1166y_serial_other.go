@@ -0,0 +1,10 @@
+//go:build !linux
+
+package zx1166y
+
+import "errors"
+
+// OpenSerial 串口只支持linux
+func OpenSerial(config SerialConfig) (Transport, error) {
+	return nil, errors.New("当前系统不支持串口！")
+}
@@ -5,36 +5,33 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
-	"github.com/ecc1/spi"
 	"strings"
 )
 
+// SPICodec ESAM的命令集，命令帧经Transport收发
+// 设置Dev、Mode、Speed后调用Open使用SPI连接，其他连接方式使用NewCodec
 type SPICodec struct {
-	device *spi.Device
-	Dev    string
-	Mode   uint8
-	Speed  int
+	Transport Transport
+	Retry     *RetryPolicy //重试策略，为nil时不重试
+	Dev       string
+	Mode      uint8
+	Speed     int
 }
 
 func (s *SPICodec) Open() error {
-	device, err := spi.Open(s.Dev, s.Speed, 0)
+	transport, err := OpenSPI(s.Dev, s.Mode, s.Speed)
 	if err != nil {
 		return err
 	}
-	s.device = device
-	err = s.device.SetMaxSpeed(s.Speed)
-	if err != nil {
-		return err
-	}
-	err = s.device.SetMode(s.Mode)
-	return err
+	s.Transport = transport
+	return nil
 }
 
 func (s *SPICodec) Close() error {
-	if s.device == nil {
+	if s.Transport == nil {
 		return nil
 	}
-	return s.device.Close()
+	return s.Transport.Close()
 }
 
 // TerminalActiveReport2 终端主动上报 第2个步骤
@@ -61,7 +58,7 @@ func (s *SPICodec) TerminalActiveReport1(data []byte) (resultData []byte, mac []
 	if length < 4 {
 		return nil, nil, errors.New("返回结果容量错误！")
 	}
-	return data[:length-4], data[length-4:], nil
+	return result[:length-4], result[length-4:], nil
 }
 
 // UpdateSessionTimeLimit 更新会话时效门限
@@ -190,7 +187,7 @@ func (s *SPICodec) ReadTerminal(rn, data []byte) ([]byte, error) {
 // ucSessionData：服务器随机数，48 字节
 // ucSign:服务器签名信息，Len-48 字节
 func (s *SPICodec) SessionKeyConnect(ucOutSessionInit, ucOutSign []byte) (ucSessionData string, ucSign string, err error) {
-	if len(ucOutSessionInit) == 32 {
+	if len(ucOutSessionInit) != 32 {
 		return "", "", errors.New("ucOutSessionInit长度应为32！")
 	}
 	tx, err := s.encode("80020000", ucOutSessionInit, ucOutSign)
@@ -201,6 +198,9 @@ func (s *SPICodec) SessionKeyConnect(ucOutSessionInit, ucOutSign []byte) (ucSess
 	if err != nil {
 		return "", "", err
 	}
+	if len(data) < 48 {
+		return "", "", errors.New("返回结果容量错误！")
+	}
 	ucSessionData = hex.EncodeToString(data[:48])
 	ucSign = hex.EncodeToString(data[48:])
 	return ucSessionData, ucSign, nil
@@ -250,31 +250,54 @@ func (s *SPICodec) TransferString(tx string, length int) ([]byte, error) {
 }
 
 func (s *SPICodec) TransferBytes(tx []byte, length int) ([]byte, error) {
+	if s.Transport == nil {
+		return nil, errors.New("设备未打开！")
+	}
+	retry := s.Retry
+	if retry == nil {
+		retry = &RetryPolicy{}
+	}
+	for attempt := 0; ; attempt++ {
+		data, err := s.transfer(tx, length, retry)
+		if err == nil || attempt >= retry.Attempts || !retry.retryable(err) {
+			return data, err
+		}
+		retry.wait()
+	}
+}
+
+// transfer 发送一次命令帧，应答未就绪时按重试策略继续读取应答
+func (s *SPICodec) transfer(tx []byte, length int, retry *RetryPolicy) ([]byte, error) {
 	txArr := make([]byte, length)
 	copy(txArr, tx)
 	rxArr := make([]byte, length)
-	err := s.device.Transfer(txArr, rxArr)
+	err := s.Transport.Transfer(txArr, rxArr)
 	if err != nil {
 		return nil, err
 	}
-	rxArr, err = s.decode(rxArr)
-	if err != nil {
-		return nil, err
+	data, err := s.decode(rxArr)
+	for polls := 0; errors.Is(err, ErrNotReady) && polls < retry.Polls; polls++ {
+		retry.wait()
+		//只读取应答，不重发命令
+		if err = s.Transport.Transfer(make([]byte, length), rxArr); err != nil {
+			return nil, err
+		}
+		data, err = s.decode(rxArr)
 	}
-	return rxArr, nil
+	return data, err
 }
 
 // 拆包器
 func (s *SPICodec) decode(array []byte) ([]byte, error) {
-	buf := bytes.NewReader(array)
-	var err error
-	var start byte
-	for start != 0x55 {
-		err = binary.Read(buf, binary.BigEndian, &start)
-		if err != nil {
-			return nil, err
+	start := bytes.IndexByte(array, 0x55)
+	if start < 0 {
+		if len(array) > 0 && len(bytes.Trim(array, "\xff")) == 0 {
+			return nil, ErrBusy
 		}
+		return nil, ErrNotReady
 	}
+	buf := bytes.NewReader(array[start+1:])
+	var err error
 	//读取状态码
 	status := make([]byte, 2)
 	err = binary.Read(buf, binary.BigEndian, &status)
@@ -282,8 +305,8 @@ func (s *SPICodec) decode(array []byte) ([]byte, error) {
 		return nil, err
 	}
 	//验证错误码
-	if hex.EncodeToString(status) != "9000" {
-		return nil, errors.New("不是正确报文，返回了错误码，错误码为：" + hex.EncodeToString(status))
+	if err = StatusWord(binary.BigEndian.Uint16(status)).Err(); err != nil {
+		return nil, err
 	}
 	//解析长度
 	length := make([]byte, 2)
@@ -309,7 +332,7 @@ func (s *SPICodec) decode(array []byte) ([]byte, error) {
 	csData := append(status, length...)
 	csData = append(csData, data...)
 	if s.Cs(csData) != csValue {
-		return nil, errors.New("cs错误！")
+		return nil, ErrChecksum
 	}
 	return data, nil
 }
@@ -0,0 +1,82 @@
+package zx1166y
+
+import (
+	"errors"
+	"fmt"
+)
+
+var _ error = StatusWord(0)
+
+// StatusWord ESAM应答的状态字SW1SW2，非成功的状态字可以直接作为error使用，支持errors.Is比较
+// 例如 errors.Is(err, SWMACVerify)，未列出的状态字同样以StatusWord返回，可以使用errors.As取得
+type StatusWord uint16
+
+const (
+	SWSuccess          StatusWord = 0x9000 //成功
+	SWMemoryFault      StatusWord = 0x6581 //存储器故障
+	SWWrongLength      StatusWord = 0x6700 //长度错误
+	SWCounterExceeded  StatusWord = 0x6901 //计数器超限
+	SWSecurityStatus   StatusWord = 0x6982 //安全状态不满足
+	SWKeyLocked        StatusWord = 0x6983 //密钥已锁定
+	SWConditions       StatusWord = 0x6985 //使用条件不满足
+	SWMACVerify        StatusWord = 0x6988 //MAC校验错误
+	SWWrongData        StatusWord = 0x6A80 //数据错误
+	SWFuncNotSupported StatusWord = 0x6A81 //功能不支持
+	SWFileNotFound     StatusWord = 0x6A82 //文件未找到
+	SWRecordNotFound   StatusWord = 0x6A83 //记录未找到
+	SWNotEnoughSpace   StatusWord = 0x6A84 //空间不足
+	SWWrongP1P2        StatusWord = 0x6A86 //P1P2错误
+	SWKeyNotFound      StatusWord = 0x6A88 //密钥未找到
+	SWChecksum         StatusWord = 0x6A90 //命令帧校验和错误
+	SWINSNotSupported  StatusWord = 0x6D00 //INS不支持
+	SWCLANotSupported  StatusWord = 0x6E00 //CLA不支持
+	SWNoDiagnosis      StatusWord = 0x6F00 //无精确诊断
+)
+
+var statusWordNames = map[StatusWord]string{
+	SWSuccess:          "成功",
+	SWMemoryFault:      "存储器故障",
+	SWWrongLength:      "长度错误",
+	SWCounterExceeded:  "计数器超限",
+	SWSecurityStatus:   "安全状态不满足",
+	SWKeyLocked:        "密钥已锁定",
+	SWConditions:       "使用条件不满足",
+	SWMACVerify:        "MAC校验错误",
+	SWWrongData:        "数据错误",
+	SWFuncNotSupported: "功能不支持",
+	SWFileNotFound:     "文件未找到",
+	SWRecordNotFound:   "记录未找到",
+	SWNotEnoughSpace:   "空间不足",
+	SWWrongP1P2:        "P1P2错误",
+	SWKeyNotFound:      "密钥未找到",
+	SWChecksum:         "命令帧校验和错误",
+	SWINSNotSupported:  "INS不支持",
+	SWCLANotSupported:  "CLA不支持",
+	SWNoDiagnosis:      "无精确诊断",
+}
+
+func (s StatusWord) String() string {
+	if name, ok := statusWordNames[s]; ok {
+		return name
+	}
+	return "未知错误码"
+}
+
+func (s StatusWord) Error() string {
+	return fmt.Sprintf("ESAM返回了错误码%04X：%s", uint16(s), s.String())
+}
+
+// Err 成功时返回nil，否则返回自身
+func (s StatusWord) Err() error {
+	if s == SWSuccess {
+		return nil
+	}
+	return s
+}
+
+// 应答帧本身的错误，与状态字无关
+var (
+	ErrBusy     = errors.New("ESAM忙！")     //应答全为0xFF，芯片未接收命令，需要重发命令
+	ErrNotReady = errors.New("ESAM应答未就绪！") //应答中没有0x55，芯片仍在处理命令，需要继续读取应答
+	ErrChecksum = errors.New("cs错误！")      //应答帧校验错误
+)
@@ -0,0 +1,242 @@
+package zx1166y
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ecc1/spi"
+)
+
+var (
+	_ Transport = (*spi.Device)(nil)
+	_ Transport = (*StreamTransport)(nil)
+	_ Transport = (*Emulator)(nil)
+)
+
+// Transport ESAM的字节传输层，位于0x55命令帧的封装、拆包之下
+// tx为完整的命令帧，补0到交换长度，应答帧写入rx
+// SPI为全双工交换；串口、TCP及内存管道按帧收发，见StreamTransport
+type Transport interface {
+	Transfer(tx, rx []byte) error
+	Close() error
+}
+
+// NewCodec 使用指定的传输层创建ESAM命令集，传输层已打开，不需要调用Open
+func NewCodec(transport Transport) *SPICodec {
+	return &SPICodec{Transport: transport}
+}
+
+// OpenSPI 打开SPI设备
+func OpenSPI(dev string, mode uint8, speed int) (Transport, error) {
+	device, err := spi.Open(dev, speed, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err = device.SetMaxSpeed(speed); err != nil {
+		_ = device.Close()
+		return nil, err
+	}
+	if err = device.SetMode(mode); err != nil {
+		_ = device.Close()
+		return nil, err
+	}
+	return device, nil
+}
+
+// DialTCP 连接ESAM的TCP中继，中继端可以使用Serve
+// timeout同时作为连接超时和每条命令的应答超时，为0时不超时
+func DialTCP(address string, timeout time.Duration) (Transport, error) {
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return NewStreamTransport(conn, timeout), nil
+}
+
+// Pipe 内存管道，返回的Transport与另一端的连接相连，另一端可以交给ServeConn转发到模拟器或芯片
+func Pipe(timeout time.Duration) (Transport, net.Conn) {
+	client, server := net.Pipe()
+	return NewStreamTransport(client, timeout), server
+}
+
+// SerialConfig 串口参数，数据位固定为8位
+type SerialConfig struct {
+	Name     string        //设备名称，如/dev/ttyS1
+	Baud     int           //波特率
+	Parity   byte          //校验位 'N'无校验 'E'偶校验 'O'奇校验，为0时无校验
+	StopBits int           //停止位 1或2，为0时1位
+	Timeout  time.Duration //一条命令的应答超时，为0时不超时
+}
+
+/*--------------------------------------------------------------------------*/
+
+// StreamTransport 字节流上的传输层，用于串口、TCP和内存管道
+// 只发送命令帧本身，不发送补齐的0；按应答帧的长度读取一帧，帧前的其他字节被忽略
+type StreamTransport struct {
+	Conn    io.ReadWriteCloser
+	Timeout time.Duration //一条命令的收发超时，Conn实现SetDeadline时有效，为0时不超时
+
+	mu sync.Mutex
+}
+
+// NewStreamTransport 创建字节流传输层
+func NewStreamTransport(conn io.ReadWriteCloser, timeout time.Duration) *StreamTransport {
+	return &StreamTransport{Conn: conn, Timeout: timeout}
+}
+
+func (t *StreamTransport) Transfer(tx, rx []byte) error {
+	frame, err := commandFrame(tx)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if d, ok := t.Conn.(interface{ SetDeadline(time.Time) error }); ok && t.Timeout > 0 {
+		if err = d.SetDeadline(time.Now().Add(t.Timeout)); err != nil {
+			return err
+		}
+		defer d.SetDeadline(time.Time{})
+	}
+	if _, err = t.Conn.Write(frame); err != nil {
+		return err
+	}
+	response, err := readFrame(t.Conn, 2)
+	if err != nil {
+		return err
+	}
+	if len(response) > len(rx) {
+		return errors.New("接收缓冲区长度不足！")
+	}
+	for i := range rx {
+		rx[i] = 0
+	}
+	copy(rx, response)
+	return nil
+}
+
+func (t *StreamTransport) Close() error {
+	return t.Conn.Close()
+}
+
+// commandFrame 去掉命令帧前后补齐的字节 0x55 CLA INS P1 P2 Len1 Len2 Data CS
+func commandFrame(tx []byte) ([]byte, error) {
+	for i, b := range tx {
+		if b != 0x55 {
+			continue
+		}
+		if len(tx) < i+7 {
+			break
+		}
+		end := i + 8 + int(binary.BigEndian.Uint16(tx[i+5:i+7]))
+		if len(tx) < end {
+			break
+		}
+		return tx[i:end], nil
+	}
+	return nil, errors.New("命令帧错误！")
+}
+
+// readFrame 读取一帧，0x55之后为headerSize个字节的帧头、2个字节的长度、数据和校验
+// 命令帧的帧头为CLA INS P1 P2，应答帧的帧头为SW1 SW2
+func readFrame(r io.Reader, headerSize int) ([]byte, error) {
+	start := make([]byte, 1)
+	for start[0] != 0x55 {
+		if _, err := io.ReadFull(r, start); err != nil {
+			return nil, err
+		}
+	}
+	head := make([]byte, 1+headerSize+2)
+	head[0] = 0x55
+	if _, err := io.ReadFull(r, head[1:]); err != nil {
+		return nil, err
+	}
+	length := int(binary.BigEndian.Uint16(head[1+headerSize:]))
+	frame := make([]byte, len(head)+length+1)
+	copy(frame, head)
+	if _, err := io.ReadFull(r, frame[len(head):]); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+/*--------------------------------------------------------------------------*/
+
+// Serve ESAM的TCP中继，把每个连接收到的命令帧转发到transport，应答写回连接
+// 多个连接共享同一个transport，命令按收到的顺序逐条执行；length为SPI交换的长度
+func Serve(listener net.Listener, transport Transport, length int) error {
+	shared := &lockedTransport{Transport: transport}
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			_ = ServeConn(conn, shared, length)
+		}()
+	}
+}
+
+// ServeConn 转发一个连接上的命令帧，连接关闭或出错时返回
+func ServeConn(conn io.ReadWriteCloser, transport Transport, length int) error {
+	defer conn.Close()
+	for {
+		frame, err := readFrame(conn, 4)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		size := length
+		if size < len(frame) {
+			size = len(frame)
+		}
+		tx := make([]byte, size)
+		copy(tx, frame)
+		rx := make([]byte, size)
+		if err = transport.Transfer(tx, rx); err != nil {
+			return err
+		}
+		response, err := responseFrame(rx)
+		if err != nil {
+			return err
+		}
+		if _, err = conn.Write(response); err != nil {
+			return err
+		}
+	}
+}
+
+// responseFrame 去掉应答帧前后补齐的字节 0x55 SW1 SW2 Len1 Len2 Data CS
+func responseFrame(rx []byte) ([]byte, error) {
+	for i, b := range rx {
+		if b != 0x55 {
+			continue
+		}
+		if len(rx) < i+5 {
+			break
+		}
+		end := i + 6 + int(binary.BigEndian.Uint16(rx[i+3:i+5]))
+		if len(rx) < end {
+			break
+		}
+		return rx[i:end], nil
+	}
+	return nil, errors.New("应答帧错误！")
+}
+
+// lockedTransport 多个连接共享设备时逐条执行命令
+type lockedTransport struct {
+	Transport
+	mu sync.Mutex
+}
+
+func (t *lockedTransport) Transfer(tx, rx []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.Transport.Transfer(tx, rx)
+}
@@ -0,0 +1,171 @@
+package zx1166y
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testFrameServer 模拟按帧收发的对端，读取一条命令帧后写入reply，收到的命令帧写入commands
+func testFrameServer(t *testing.T, conn net.Conn, reply []byte) <-chan []byte {
+	commands := make(chan []byte, 1)
+	go func() {
+		defer close(commands)
+		frame, err := readFrame(conn, 4)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		commands <- frame
+		if _, err = conn.Write(reply); err != nil {
+			t.Error(err)
+		}
+	}()
+	return commands
+}
+
+func TestPipeRoundTrip(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	key := []byte("0123456789abcdef")
+	direct, relayed := NewEmulator(key), NewEmulator(key)
+	direct.Now = func() time.Time { return now }
+	relayed.Now = direct.Now
+	transport, conn := Pipe(time.Second)
+	go ServeConn(conn, relayed, 2048)
+	piped := NewCodec(transport)
+	defer piped.Close()
+
+	secret, sign := direct.MasterSession(bytes.Repeat([]byte{0x33}, 16))
+	rn1, sign1, err := direct.Codec().SessionKeyConnect(secret, sign)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rn2, sign2, err := piped.SessionKeyConnect(secret, sign)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rn1 != rn2 || sign1 != sign2 {
+		t.Fatalf("session = %s %s, want %s %s", rn2, sign2, rn1, sign1)
+	}
+	for _, encrypt := range []EncryptType{Plaintext_MAC, Ciphertext, CiphertextEncrypt} {
+		want, err := direct.Codec().Encrypt(encrypt, []byte{0x01, 0x02, 0x03})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := piped.Encrypt(encrypt, []byte{0x01, 0x02, 0x03})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("encrypt %s = %x, want %x", encrypt, got, want)
+		}
+	}
+	want, err := direct.Codec().SelectESAMInfos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := piped.SelectESAMInfos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("info = %+v, want %+v", got, want)
+	}
+}
+
+func TestStreamTransportLeadingGarbage(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	codec := NewCodec(NewStreamTransport(client, time.Second))
+	defer codec.Close()
+	reply := append([]byte{0x00, 0xFF, 0x12, 0x90}, emulatorFrame(SWSuccess, []byte{0xAA, 0xBB})...)
+	commands := testFrameServer(t, server, reply)
+
+	tx, err := codec.encode("80360000", []byte{0x01})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := codec.TransferBytes(tx, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, []byte{0xAA, 0xBB}) {
+		t.Fatalf("data = %x", data)
+	}
+	//只发送命令帧本身，不发送补齐的0
+	if command := <-commands; !bytes.Equal(command, tx) {
+		t.Fatalf("command = %x, want %x", command, tx)
+	}
+}
+
+func TestStreamTransportShortBuffer(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	transport := NewStreamTransport(client, time.Second)
+	defer transport.Close()
+	testFrameServer(t, server, emulatorFrame(SWSuccess, bytes.Repeat([]byte{0xAA}, 16)))
+
+	tx, err := (&SPICodec{}).encode("80360000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = transport.Transfer(tx, make([]byte, 8)); err == nil {
+		t.Fatal("transfer into short rx buffer")
+	}
+}
+
+func TestServeSharedTransport(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer listener.Close()
+	emulator := NewEmulator([]byte("0123456789abcdef"))
+	go Serve(listener, emulator, 2048)
+
+	codecs := make([]*SPICodec, 2)
+	for i := range codecs {
+		transport, err := DialTCP(listener.Addr().String(), time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		codecs[i] = NewCodec(transport)
+		defer codecs[i].Close()
+	}
+	secret, sign := emulator.MasterSession(bytes.Repeat([]byte{0x44}, 16))
+	if _, _, err = codecs[0].SessionKeyConnect(secret, sign); err != nil {
+		t.Fatal(err)
+	}
+
+	//两个连接交替发送命令，每条应答都与自己的命令对应
+	var wg sync.WaitGroup
+	for i, codec := range codecs {
+		wg.Add(1)
+		go func(i int, codec *SPICodec) {
+			defer wg.Done()
+			for n := 0; n < 20; n++ {
+				data := []byte{byte(i), byte(n)}
+				mac, err := codec.Encrypt(Plaintext_MAC, data)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if !bytes.Equal(mac, emulator.MasterMAC(data)) {
+					t.Errorf("connection %d command %d: mac = %x", i, n, mac)
+					return
+				}
+			}
+		}(i, codec)
+	}
+	wg.Wait()
+	info, err := codecs[1].SelectESAMInfos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ASCTR != 1 {
+		t.Fatalf("asctr = %d", info.ASCTR)
+	}
+}